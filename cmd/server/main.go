@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"illust-nest/internal/config"
 	"illust-nest/internal/database"
+	"illust-nest/internal/repository"
 	"illust-nest/internal/router"
+	"illust-nest/internal/service"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -41,6 +45,37 @@ func main() {
 		log.Fatalf("Failed to initialize default data: %v", err)
 	}
 
+	settingRepo := repository.NewSettingRepository(database.DB)
+	trashService := service.NewTrashService(
+		repository.NewWorkRepository(database.DB),
+		settingRepo,
+		service.NewImageService(settingRepo),
+	)
+	go trashService.RunSweeper(context.Background(), time.Hour)
+
+	tagService := service.NewTagService(repository.NewTagRepository(database.DB), nil, nil)
+	aiMetadataService := service.NewAIMetadataService(repository.NewAIMetadataRepository(database.DB))
+	imageEXIFService := service.NewImageEXIFService(repository.NewImageEXIFRepository(database.DB))
+	paletteService := service.NewImagePaletteService(repository.NewImagePaletteRepository(database.DB))
+	animationService := service.NewImageAnimationService(repository.NewImageAnimationRepository(database.DB))
+	backfills := []struct {
+		name string
+		run  func() error
+	}{
+		{name: "tag transliterations", run: tagService.BackfillTransliterations},
+		{name: "structured AI metadata", run: aiMetadataService.BackfillStructuredMetadata},
+		{name: "image EXIF", run: imageEXIFService.BackfillImageEXIF},
+		{name: "image palettes", run: paletteService.BackfillImagePalettes},
+		{name: "image animation info", run: animationService.BackfillImageAnimation},
+	}
+	go func() {
+		for _, backfill := range backfills {
+			if err := backfill.run(); err != nil {
+				log.Printf("Failed to backfill %s: %v", backfill.name, err)
+			}
+		}
+	}()
+
 	r := router.Setup()
 
 	addr := fmt.Sprintf(":%d", config.GlobalConfig.Server.Port)
@@ -6,6 +6,8 @@ type AuthImageProps = {
   alt: string;
   className?: string;
   variant?: "thumbnail" | "original" | "transcoded";
+  mediaType?: "image" | "video";
+  controls?: boolean;
   lazy?: boolean;
   publicAccess?: boolean;
 };
@@ -15,6 +17,8 @@ export function AuthImage({
   alt,
   className,
   variant = "thumbnail",
+  mediaType = "image",
+  controls = false,
   lazy = false,
   publicAccess = false,
 }: AuthImageProps) {
@@ -108,6 +112,21 @@ export function AuthImage({
     );
   }
 
+  if (mediaType === "video") {
+    return (
+      <video
+        src={src}
+        aria-label={alt}
+        className={className}
+        controls={controls}
+        autoPlay
+        loop
+        muted
+        playsInline
+      />
+    );
+  }
+
   return (
     <img
       src={src}
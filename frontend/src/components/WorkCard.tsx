@@ -46,7 +46,10 @@ export function WorkCard({
         {work.cover_image ? (
           <button className="w-full" onClick={onPreview}>
             <AuthImage
-              path={work.cover_image.thumbnail_path}
+              path={
+                work.cover_image.animated_thumbnail_path ||
+                work.cover_image.thumbnail_path
+              }
               alt={work.title}
               className="w-full h-55 object-cover"
               lazy
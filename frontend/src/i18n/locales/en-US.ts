@@ -101,6 +101,14 @@ export const enUS = {
     createFailed: "Failed to create work",
     deleteFailed: "Delete failed",
     uploadFailed: "Failed to upload images",
+    converterTimedOut:
+      "{{command}} did not finish within {{timeout}} and was stopped",
+    uploadLimit: {
+      requestTooLarge: "The upload exceeds the {{limit}}MB per-request limit",
+      fileTooLarge:
+        "{{filename}} exceeds the {{limit}}MB limit for {{format}} files",
+      pixelsExceeded: "{{filename}} exceeds the {{limit}} megapixel limit",
+    },
     noWorks: "No works",
     sortOptions: {
       createdAtDesc: "Created",
@@ -111,6 +119,8 @@ export const enUS = {
       ratingAsc: "Rating",
       titleAsc: "Title",
       titleDesc: "Title",
+      takenAtDesc: "Date taken",
+      takenAtAsc: "Date taken",
     },
     fields: {
       title: "Title",
@@ -132,7 +142,7 @@ export const enUS = {
       uploadHintMore: "Continue dragging or click to add more images",
       dropToUpload: "Drop to add images",
       supportedFormats:
-        "Supports PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF",
+        "Supports PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF / MP4 / WebM / MOV / ugoira ZIP",
       transcodePending: "Pending transcode",
     },
   },
@@ -151,9 +161,10 @@ export const enUS = {
     downloading: "Downloading...",
     download: "Download",
     downloadAll: "All",
-    exifOnlyJpgTiff: "Only JPG/TIFF source images support EXIF",
+    exifOnlyJpgTiff: "Only JPG/TIFF/PNG/WebP/HEIC/AVIF source images support EXIF",
     exifLoadFailed: "Failed to load EXIF",
     createdAt: "Created",
+    palette: "Palette",
     returnToPublic: "Back to Public Works",
     addToCollections: "Add to Collection",
     addToCollectionsDescription:
@@ -176,7 +187,7 @@ export const enUS = {
     prev: "Previous",
     next: "Next",
     noAIMetadata: "No AI metadata for this image",
-    exifOnlyJpgTiff: "Only JPG/TIFF source images support EXIF",
+    exifOnlyJpgTiff: "Only JPG/TIFF/PNG/WebP/HEIC/AVIF source images support EXIF",
     aiMetadata: "AI Metadata",
     exif: "EXIF Info",
     file: "File",
@@ -316,6 +327,12 @@ export const enUS = {
     publicGalleryEnabled: "Enable public gallery",
     publicGalleryHelp:
       "When enabled, anonymous users can access public works, details, related images, and all tag information.",
+    publicStripEXIF: "Strip location and serial numbers from public originals",
+    publicStripEXIFHelp:
+      "Public original and transcoded images are served from a copy with GPS coordinates, camera serial numbers and owner names removed from EXIF and XMP.",
+    animatedThumbnails: "Generate animated thumbnails for GIF, APNG and WebP",
+    animatedThumbnailsHelp:
+      "Animated uploads also get a looping GIF thumbnail at thumbnail size. GIF is handled natively; APNG and animated WebP require ImageMagick.",
     imageMagickSection: "ImageMagick Settings",
     imageMagickHelp:
       "Used for preview transcoding of PSD and similar formats. v7 uses the magick command, v6 uses convert.",
@@ -331,7 +348,30 @@ export const enUS = {
     testSuccess: "ImageMagick is available (command: {{command}}). {{message}}",
     testFailed: "ImageMagick test failed",
     publicGalleryAriaLabel: "Public gallery help",
+    publicStripEXIFAriaLabel: "Public image privacy help",
+    animatedThumbnailsAriaLabel: "Animated thumbnail help",
     imageMagickAriaLabel: "ImageMagick help",
+    converterPoolStatus:
+      "Converter pool: {{active}}/{{max}} running, {{waiting}} waiting, {{timeout}}s timeout per command",
+    converterPoolStats:
+      "Completed {{completed}}, failed {{failed}}, timed out {{timedOut}}",
+    imageMagickFormats: "Supported formats ({{count}})",
+    ffmpegSection: "FFmpeg Settings",
+    ffmpegHelp:
+      "Used to extract poster frames and animated thumbnails from MP4 / WebM / MOV videos and to convert Pixiv ugoira archives into animated WebP. Without FFmpeg, videos get a placeholder thumbnail and ugoira is converted to GIF (or WebP via ImageMagick).",
+    ffmpegEnabled: "Enable FFmpeg integration",
+    ffmpegTestSuccess:
+      "FFmpeg is available (command: {{command}}). {{message}}",
+    ffmpegTestFailed: "FFmpeg test failed",
+    ffmpegAriaLabel: "FFmpeg help",
+    uploadLimitsSection: "Upload Limits",
+    uploadLimitsHelp:
+      "Limits are checked before the upload body is read. Per-format limits override the per-file limit for that extension, and the pixel limit rejects images whose dimensions exceed it before they are decoded.",
+    uploadLimitsAriaLabel: "Upload limits help",
+    uploadMaxRequestMB: "Max request size (MB)",
+    uploadMaxFileMB: "Max file size (MB)",
+    uploadMaxMegapixels: "Max image size (megapixels)",
+    uploadFormatLimits: "Per-format file size limits (extension=MB)",
     language: "UI language",
     languageZhCN: "简体中文",
     languageZhTW: "繁體中文",
@@ -100,6 +100,15 @@ export const jaJP = {
     createFailed: "作品の作成に失敗しました",
     deleteFailed: "削除に失敗しました",
     uploadFailed: "画像のアップロードに失敗しました",
+    converterTimedOut:
+      "{{command}} が {{timeout}} 以内に完了しなかったため停止しました",
+    uploadLimit: {
+      requestTooLarge:
+        "アップロードが 1 リクエストあたりの上限 {{limit}}MB を超えています",
+      fileTooLarge:
+        "{{filename}} は {{format}} ファイルの上限 {{limit}}MB を超えています",
+      pixelsExceeded: "{{filename}} は上限 {{limit}} メガピクセルを超えています",
+    },
     noWorks: "作品がありません",
     sortOptions: {
       createdAtDesc: "作成日時",
@@ -110,6 +119,8 @@ export const jaJP = {
       ratingAsc: "評価",
       titleAsc: "タイトル",
       titleDesc: "タイトル",
+      takenAtDesc: "撮影日時",
+      takenAtAsc: "撮影日時",
     },
     fields: {
       title: "タイトル",
@@ -131,7 +142,7 @@ export const jaJP = {
       uploadHintMore: "ドラッグまたはクリックで更に画像を追加",
       dropToUpload: "ドロップして画像を追加",
       supportedFormats:
-        "PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF 対応",
+        "PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF / MP4 / WebM / MOV / ugoira ZIP 対応",
       transcodePending: "変換待ち",
     },
   },
@@ -150,9 +161,10 @@ export const jaJP = {
     downloading: "ダウンロード中...",
     download: "ダウンロード",
     downloadAll: "すべて",
-    exifOnlyJpgTiff: "JPG/TIFF元画像のみEXIF対応",
+    exifOnlyJpgTiff: "JPG/TIFF/PNG/WebP/HEIC/AVIF元画像のみEXIF対応",
     exifLoadFailed: "EXIFの読み込みに失敗しました",
     createdAt: "作成日時",
+    palette: "カラーパレット",
     returnToPublic: "公開作品に戻る",
     addToCollections: "コレクションに追加",
     addToCollectionsDescription:
@@ -175,7 +187,7 @@ export const jaJP = {
     prev: "前へ",
     next: "次へ",
     noAIMetadata: "この画像にAIメタデータはありません",
-    exifOnlyJpgTiff: "JPG/TIFF元画像のみEXIF対応",
+    exifOnlyJpgTiff: "JPG/TIFF/PNG/WebP/HEIC/AVIF元画像のみEXIF対応",
     aiMetadata: "AIメタデータ",
     exif: "EXIF情報",
     file: "ファイル",
@@ -315,6 +327,12 @@ export const jaJP = {
     publicGalleryEnabled: "公開ギャラリーを有効化",
     publicGalleryHelp:
       "有効にすると、匿名ユーザーが公開作品一覧と詳細、関連画像、すべてのタグ情報にアクセスできます。",
+    publicStripEXIF: "公開原画から位置情報とシリアル番号を削除",
+    publicStripEXIFHelp:
+      "公開される原画とトランスコード画像は、EXIF と XMP から GPS 座標、カメラのシリアル番号、所有者名を取り除いたコピーから配信されます。",
+    animatedThumbnails: "GIF・APNG・WebP のアニメーションサムネイルを生成",
+    animatedThumbnailsHelp:
+      "アニメーション画像のアップロード時に、サムネイルサイズのループ GIF も生成します。GIF は標準で対応し、APNG とアニメーション WebP には ImageMagick が必要です。",
     imageMagickSection: "ImageMagick設定",
     imageMagickHelp:
       "PSDなどの形式のプレビュー変換に使用。v7はmagickコマンド、v6はconvertコマンドを使用。",
@@ -331,7 +349,29 @@ export const jaJP = {
       "ImageMagickが利用可能です（コマンド：{{command}}）。{{message}}",
     testFailed: "ImageMagickのテストに失敗しました",
     publicGalleryAriaLabel: "公開ギャラリーのヘルプ",
+    publicStripEXIFAriaLabel: "公開画像のプライバシーのヘルプ",
+    animatedThumbnailsAriaLabel: "アニメーションサムネイルのヘルプ",
     imageMagickAriaLabel: "ImageMagickのヘルプ",
+    converterPoolStatus:
+      "変換プール：実行中 {{active}}/{{max}}、待機中 {{waiting}}、コマンドごとのタイムアウト {{timeout}} 秒",
+    converterPoolStats:
+      "完了 {{completed}}、失敗 {{failed}}、タイムアウト {{timedOut}}",
+    imageMagickFormats: "対応フォーマット（{{count}}）",
+    ffmpegSection: "FFmpeg 設定",
+    ffmpegHelp:
+      "MP4 / WebM / MOV 動画のポスターフレームとアニメーションサムネイルの抽出、および Pixiv うごイラ ZIP のアニメーション WebP への変換に使用します。FFmpeg がない場合、動画はプレースホルダーのサムネイルになり、うごイラは GIF（ImageMagick 有効時は WebP）に変換されます。",
+    ffmpegEnabled: "FFmpeg 連携を有効にする",
+    ffmpegTestSuccess: "FFmpeg は利用可能です（コマンド: {{command}}）。{{message}}",
+    ffmpegTestFailed: "FFmpeg のテストに失敗しました",
+    ffmpegAriaLabel: "FFmpeg のヘルプ",
+    uploadLimitsSection: "アップロード制限",
+    uploadLimitsHelp:
+      "制限はアップロード本体を読み込む前にチェックされます。形式別の上限はその拡張子のファイルサイズ上限を上書きし、ピクセル上限を超える画像はデコード前に拒否されます。",
+    uploadLimitsAriaLabel: "アップロード制限のヘルプ",
+    uploadMaxRequestMB: "リクエストの最大サイズ (MB)",
+    uploadMaxFileMB: "ファイルの最大サイズ (MB)",
+    uploadMaxMegapixels: "画像の最大サイズ (メガピクセル)",
+    uploadFormatLimits: "形式別のファイルサイズ上限 (拡張子=MB)",
     language: "インターフェース言語",
     languageZhCN: "简体中文",
     languageZhTW: "繁體中文",
@@ -99,6 +99,12 @@ export const zhCN = {
     createFailed: "创建作品失败",
     deleteFailed: "删除失败",
     uploadFailed: "上传图片失败",
+    converterTimedOut: "{{command}} 未能在 {{timeout}} 内完成，已被终止",
+    uploadLimit: {
+      requestTooLarge: "上传内容超过单次请求 {{limit}}MB 的上限",
+      fileTooLarge: "{{filename}} 超过 {{format}} 文件 {{limit}}MB 的上限",
+      pixelsExceeded: "{{filename}} 超过 {{limit}} 百万像素的上限",
+    },
     noWorks: "暂无作品",
     sortOptions: {
       createdAtDesc: "创建时间",
@@ -109,6 +115,8 @@ export const zhCN = {
       ratingAsc: "评分",
       titleAsc: "标题",
       titleDesc: "标题",
+      takenAtDesc: "拍摄时间",
+      takenAtAsc: "拍摄时间",
     },
     fields: {
       title: "标题",
@@ -130,7 +138,7 @@ export const zhCN = {
       uploadHintMore: "可继续拖拽或点击添加更多图片",
       dropToUpload: "释放以添加图片",
       supportedFormats:
-        "支持 PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF",
+        "支持 PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF / MP4 / WebM / MOV / ugoira ZIP",
       transcodePending: "待转码",
     },
   },
@@ -149,9 +157,10 @@ export const zhCN = {
     downloading: "下载中...",
     download: "下载",
     downloadAll: "全部",
-    exifOnlyJpgTiff: "仅 JPG/TIFF 源图支持 EXIF",
+    exifOnlyJpgTiff: "仅 JPG/TIFF/PNG/WebP/HEIC/AVIF 源图支持 EXIF",
     exifLoadFailed: "加载 EXIF 失败",
     createdAt: "创建时间",
+    palette: "主色调",
     returnToPublic: "返回公开作品",
     addToCollections: "加入作品集",
     addToCollectionsDescription: "可多选作品集，已加入的作品集会自动勾选",
@@ -171,7 +180,7 @@ export const zhCN = {
     prev: "上一张",
     next: "下一张",
     noAIMetadata: "当前图片无 AI 元数据",
-    exifOnlyJpgTiff: "仅 JPG/TIFF 源图支持 EXIF",
+    exifOnlyJpgTiff: "仅 JPG/TIFF/PNG/WebP/HEIC/AVIF 源图支持 EXIF",
     aiMetadata: "AI 元数据",
     exif: "EXIF 信息",
     file: "文件",
@@ -307,6 +316,12 @@ export const zhCN = {
     publicGalleryEnabled: "启用公开展示",
     publicGalleryHelp:
       "勾选后，匿名用户可访问公开作品列表与详情、与之关联的图片及所有标签信息。",
+    publicStripEXIF: "公开原图时移除位置与序列号",
+    publicStripEXIFHelp:
+      "公开的原图与转码图将以移除了 EXIF 与 XMP 中 GPS 坐标、相机序列号和所有者名称的副本提供。",
+    animatedThumbnails: "为GIF、APNG和WebP生成动态缩略图",
+    animatedThumbnailsHelp:
+      "上传动图时额外生成缩略图尺寸的循环GIF。GIF可直接处理，APNG和动态WebP需要启用ImageMagick。",
     imageMagickSection: "ImageMagick 设置",
     imageMagickHelp:
       "用于 PSD 等格式转码预览。v7 使用 magick 命令，v6 使用 convert 命令。",
@@ -322,7 +337,29 @@ export const zhCN = {
     testSuccess: "ImageMagick 可用（命令：{{command}}）。{{message}}",
     testFailed: "ImageMagick 测试失败",
     publicGalleryAriaLabel: "公开展示说明",
+    publicStripEXIFAriaLabel: "公开图片隐私说明",
+    animatedThumbnailsAriaLabel: "动态缩略图说明",
     imageMagickAriaLabel: "ImageMagick 说明",
+    converterPoolStatus:
+      "转换进程池：运行中 {{active}}/{{max}}，等待中 {{waiting}}，单条命令超时 {{timeout}} 秒",
+    converterPoolStats:
+      "已完成 {{completed}}，失败 {{failed}}，超时 {{timedOut}}",
+    imageMagickFormats: "支持的格式（{{count}}）",
+    ffmpegSection: "FFmpeg设置",
+    ffmpegHelp:
+      "用于从MP4 / WebM / MOV视频中提取封面帧和动态缩略图，并将Pixiv动图（ugoira）压缩包转换为动态WebP。未启用时视频使用占位缩略图，ugoira转换为GIF（启用ImageMagick时转换为WebP）。",
+    ffmpegEnabled: "启用FFmpeg集成",
+    ffmpegTestSuccess: "FFmpeg可用（命令：{{command}}）。{{message}}",
+    ffmpegTestFailed: "FFmpeg测试失败",
+    ffmpegAriaLabel: "FFmpeg说明",
+    uploadLimitsSection: "上传限制",
+    uploadLimitsHelp:
+      "限制会在读取上传内容之前检查。按格式设置的上限会覆盖该扩展名的单文件上限，超过像素上限的图片会在解码前被拒绝。",
+    uploadLimitsAriaLabel: "上传限制说明",
+    uploadMaxRequestMB: "单次请求上限 (MB)",
+    uploadMaxFileMB: "单文件上限 (MB)",
+    uploadMaxMegapixels: "图片尺寸上限 (百万像素)",
+    uploadFormatLimits: "按格式的文件大小上限 (扩展名=MB)",
     language: "界面语言",
     languageZhCN: "简体中文",
     languageZhTW: "繁體中文",
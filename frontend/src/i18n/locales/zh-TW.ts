@@ -99,6 +99,12 @@ export const zhTW = {
     createFailed: "建立作品失敗",
     deleteFailed: "刪除失敗",
     uploadFailed: "上傳圖片失敗",
+    converterTimedOut: "{{command}} 未能在 {{timeout}} 內完成，已被終止",
+    uploadLimit: {
+      requestTooLarge: "上傳內容超過單次請求 {{limit}}MB 的上限",
+      fileTooLarge: "{{filename}} 超過 {{format}} 檔案 {{limit}}MB 的上限",
+      pixelsExceeded: "{{filename}} 超過 {{limit}} 百萬像素的上限",
+    },
     noWorks: "暫無作品",
     sortOptions: {
       createdAtDesc: "建立時間",
@@ -109,6 +115,8 @@ export const zhTW = {
       ratingAsc: "評分",
       titleAsc: "標題",
       titleDesc: "標題",
+      takenAtDesc: "拍攝時間",
+      takenAtAsc: "拍攝時間",
     },
     fields: {
       title: "標題",
@@ -130,7 +138,7 @@ export const zhTW = {
       uploadHintMore: "可繼續拖曳或點擊新增更多圖片",
       dropToUpload: "釋放以新增圖片",
       supportedFormats:
-        "支援 PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF",
+        "支援 PNG / JPG / GIF / WebP / BMP / TIFF / PSD / AI / HEIC / HEIF / AVIF / MP4 / WebM / MOV / ugoira ZIP",
       transcodePending: "待轉碼",
     },
   },
@@ -149,9 +157,10 @@ export const zhTW = {
     downloading: "下載中...",
     download: "下載",
     downloadAll: "全部",
-    exifOnlyJpgTiff: "僅 JPG/TIFF 原圖支援 EXIF",
+    exifOnlyJpgTiff: "僅 JPG/TIFF/PNG/WebP/HEIC/AVIF 原圖支援 EXIF",
     exifLoadFailed: "載入 EXIF 失敗",
     createdAt: "建立時間",
+    palette: "主色調",
     returnToPublic: "返回公開作品",
     addToCollections: "加入作品集",
     addToCollectionsDescription: "可多選作品集，已加入的作品集會自動勾選",
@@ -171,7 +180,7 @@ export const zhTW = {
     prev: "上一張",
     next: "下一張",
     noAIMetadata: "目前圖片無 AI 元資料",
-    exifOnlyJpgTiff: "僅 JPG/TIFF 原圖支援 EXIF",
+    exifOnlyJpgTiff: "僅 JPG/TIFF/PNG/WebP/HEIC/AVIF 原圖支援 EXIF",
     aiMetadata: "AI 元資料",
     exif: "EXIF 資訊",
     file: "檔案",
@@ -307,6 +316,12 @@ export const zhTW = {
     publicGalleryEnabled: "啟用公開展示",
     publicGalleryHelp:
       "勾選後，匿名使用者可存取公開作品列表與詳情、與之關聯的圖片及所有標籤資訊。",
+    publicStripEXIF: "公開原圖時移除位置與序號",
+    publicStripEXIFHelp:
+      "公開的原圖與轉碼圖將以移除了 EXIF 與 XMP 中 GPS 座標、相機序號和擁有者名稱的副本提供。",
+    animatedThumbnails: "為GIF、APNG和WebP產生動態縮圖",
+    animatedThumbnailsHelp:
+      "上傳動圖時額外產生縮圖尺寸的循環GIF。GIF可直接處理，APNG和動態WebP需要啟用ImageMagick。",
     imageMagickSection: "ImageMagick 設定",
     imageMagickHelp:
       "用於 PSD 等格式轉碼預覽。v7 使用 magick 命令，v6 使用 convert 命令。",
@@ -322,7 +337,29 @@ export const zhTW = {
     testSuccess: "ImageMagick 可用（命令：{{command}}）。{{message}}",
     testFailed: "ImageMagick 測試失敗",
     publicGalleryAriaLabel: "公開展示說明",
+    publicStripEXIFAriaLabel: "公開圖片隱私說明",
+    animatedThumbnailsAriaLabel: "動態縮圖說明",
     imageMagickAriaLabel: "ImageMagick 說明",
+    converterPoolStatus:
+      "轉換程序池：執行中 {{active}}/{{max}}，等待中 {{waiting}}，單一命令逾時 {{timeout}} 秒",
+    converterPoolStats:
+      "已完成 {{completed}}，失敗 {{failed}}，逾時 {{timedOut}}",
+    imageMagickFormats: "支援的格式（{{count}}）",
+    ffmpegSection: "FFmpeg設定",
+    ffmpegHelp:
+      "用於從MP4 / WebM / MOV影片中擷取封面影格和動態縮圖，並將Pixiv動圖（ugoira）壓縮檔轉換為動態WebP。未啟用時影片使用佔位縮圖，ugoira轉換為GIF（啟用ImageMagick時轉換為WebP）。",
+    ffmpegEnabled: "啟用FFmpeg整合",
+    ffmpegTestSuccess: "FFmpeg可用（指令：{{command}}）。{{message}}",
+    ffmpegTestFailed: "FFmpeg測試失敗",
+    ffmpegAriaLabel: "FFmpeg說明",
+    uploadLimitsSection: "上傳限制",
+    uploadLimitsHelp:
+      "限制會在讀取上傳內容之前檢查。依格式設定的上限會覆蓋該副檔名的單檔上限，超過像素上限的圖片會在解碼前被拒絕。",
+    uploadLimitsAriaLabel: "上傳限制說明",
+    uploadMaxRequestMB: "單次請求上限 (MB)",
+    uploadMaxFileMB: "單檔上限 (MB)",
+    uploadMaxMegapixels: "圖片尺寸上限 (百萬像素)",
+    uploadFormatLimits: "依格式的檔案大小上限 (副檔名=MB)",
     language: "介面語言",
     languageZhCN: "简体中文",
     languageZhTW: "繁體中文",
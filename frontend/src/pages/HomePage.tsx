@@ -440,6 +440,14 @@ export function HomePage() {
                       {t("works.sortOptions.titleDesc")}{" "}
                       <ArrowDown className="inline h-3 w-3" />
                     </SelectItem>
+                    <SelectItem value="taken_at:desc">
+                      {t("works.sortOptions.takenAtDesc")}{" "}
+                      <ArrowDown className="inline h-3 w-3" />
+                    </SelectItem>
+                    <SelectItem value="taken_at:asc">
+                      {t("works.sortOptions.takenAtAsc")}{" "}
+                      <ArrowUp className="inline h-3 w-3" />
+                    </SelectItem>
                   </SelectContent>
                 </Select>
               </div>
@@ -326,6 +326,14 @@ export function PublicWorksPage() {
                       {t("works.fields.title")}{" "}
                       <ArrowDown className="inline h-3 w-3" />
                     </SelectItem>
+                    <SelectItem value="taken_at:desc">
+                      {t("works.sortOptions.takenAtDesc")}{" "}
+                      <ArrowDown className="inline h-3 w-3" />
+                    </SelectItem>
+                    <SelectItem value="taken_at:asc">
+                      {t("works.sortOptions.takenAtAsc")}{" "}
+                      <ArrowUp className="inline h-3 w-3" />
+                    </SelectItem>
                   </SelectContent>
                 </Select>
               </div>
@@ -4,7 +4,7 @@ import { useTranslation } from "react-i18next";
 import { toast } from "sonner";
 import { systemService, authService } from "@/services";
 import { useAuthStore, useI18nStore, useSystemStore } from "@/stores";
-import type { SystemSettings } from "@/types/api";
+import type { ImageMagickTestResult, SystemSettings } from "@/types/api";
 import { CircleHelp, KeyRound } from "lucide-react";
 import { AdminLayout } from "@/components/AdminLayout";
 import { Button } from "@/components/ui/button";
@@ -48,10 +48,22 @@ export function SettingsPage() {
     site_title: "",
     imagemagick_enabled: false,
     imagemagick_version: "v7",
+    trash_retention_days: 30,
+    public_strip_sensitive_exif: true,
+    animated_thumbnails_enabled: false,
+    ffmpeg_enabled: false,
+    upload_max_request_mb: 1024,
+    upload_max_file_mb: 50,
+    upload_format_limits_mb: {},
+    upload_max_megapixels: 100,
   });
+  const [formatLimitsText, setFormatLimitsText] = useState("");
   const [loading, setLoading] = useState(true);
   const [saving, setSaving] = useState(false);
   const [testingImageMagick, setTestingImageMagick] = useState(false);
+  const [testingFFmpeg, setTestingFFmpeg] = useState(false);
+  const [imageMagickTestResult, setImageMagickTestResult] =
+    useState<ImageMagickTestResult | null>(null);
 
   // Password reset state
   const [passwordDialogOpen, setPasswordDialogOpen] = useState(false);
@@ -76,6 +88,9 @@ export function SettingsPage() {
         const data = res.data.data;
         setSettings(data);
         setLocalSettings(data);
+        setFormatLimitsText(
+          formatUploadFormatLimits(data.upload_format_limits_mb),
+        );
       }
     } catch (err) {
       console.error(t("settings.loadFailed"), err);
@@ -87,9 +102,17 @@ export function SettingsPage() {
   const handleSave = async () => {
     setSaving(true);
     try {
-      const res = await systemService.updateSettings(settings);
+      const payload = {
+        ...settings,
+        upload_format_limits_mb: parseUploadFormatLimits(formatLimitsText),
+      };
+      const res = await systemService.updateSettings(payload);
       if (res.data.code === 0) {
-        setSettings(settings);
+        setSettings(res.data.data);
+        setLocalSettings(res.data.data);
+        setFormatLimitsText(
+          formatUploadFormatLimits(res.data.data.upload_format_limits_mb),
+        );
         toast.success(t("settings.saveSuccess"));
       } else {
         toast.error(res.data.message || t("settings.saveFailed"));
@@ -114,6 +137,7 @@ export function SettingsPage() {
         settings.imagemagick_version,
       );
       if (res.data.code === 0) {
+        setImageMagickTestResult(res.data.data);
         toast.success(
           t("settings.testSuccess", {
             command: res.data.data.command,
@@ -121,15 +145,40 @@ export function SettingsPage() {
           }),
         );
       } else {
+        setImageMagickTestResult(res.data.data ?? null);
         toast.error(res.data.message || t("settings.testFailed"));
       }
     } catch (err: any) {
+      setImageMagickTestResult(err.response?.data?.data ?? null);
       toast.error(err.response?.data?.message || t("settings.testFailed"));
     } finally {
       setTestingImageMagick(false);
     }
   };
 
+  const handleTestFFmpeg = async () => {
+    setTestingFFmpeg(true);
+    try {
+      const res = await systemService.testFFmpeg();
+      if (res.data.code === 0) {
+        toast.success(
+          t("settings.ffmpegTestSuccess", {
+            command: res.data.data.command,
+            message: res.data.data.message,
+          }),
+        );
+      } else {
+        toast.error(res.data.message || t("settings.ffmpegTestFailed"));
+      }
+    } catch (err: any) {
+      toast.error(
+        err.response?.data?.message || t("settings.ffmpegTestFailed"),
+      );
+    } finally {
+      setTestingFFmpeg(false);
+    }
+  };
+
   const handleResetPassword = async () => {
     if (newPassword.length < 6) {
       toast.error(t("settings.passwordMinLength"));
@@ -244,6 +293,78 @@ export function SettingsPage() {
                   </Tooltip>
                 </TooltipProvider>
               </div>
+              <div className="flex items-center gap-2">
+                <Checkbox
+                  id="public_strip_sensitive_exif"
+                  checked={settings.public_strip_sensitive_exif}
+                  onCheckedChange={(checked) =>
+                    setLocalSettings({
+                      ...settings,
+                      public_strip_sensitive_exif: checked === true,
+                    })
+                  }
+                />
+                <label
+                  htmlFor="public_strip_sensitive_exif"
+                  className="text-sm text-foreground cursor-pointer"
+                >
+                  {t("settings.publicStripEXIF")}
+                </label>
+                <TooltipProvider>
+                  <Tooltip>
+                    <TooltipTrigger asChild>
+                      <Button
+                        type="button"
+                        variant="ghost"
+                        size="icon"
+                        className="h-5 w-5 rounded-full text-muted-foreground"
+                        aria-label={t("settings.publicStripEXIFAriaLabel")}
+                      >
+                        <CircleHelp className="h-4 w-4" />
+                      </Button>
+                    </TooltipTrigger>
+                    <TooltipContent side="top" sideOffset={6}>
+                      {t("settings.publicStripEXIFHelp")}
+                    </TooltipContent>
+                  </Tooltip>
+                </TooltipProvider>
+              </div>
+              <div className="flex items-center gap-2">
+                <Checkbox
+                  id="animated_thumbnails_enabled"
+                  checked={settings.animated_thumbnails_enabled}
+                  onCheckedChange={(checked) =>
+                    setLocalSettings({
+                      ...settings,
+                      animated_thumbnails_enabled: checked === true,
+                    })
+                  }
+                />
+                <label
+                  htmlFor="animated_thumbnails_enabled"
+                  className="text-sm text-foreground cursor-pointer"
+                >
+                  {t("settings.animatedThumbnails")}
+                </label>
+                <TooltipProvider>
+                  <Tooltip>
+                    <TooltipTrigger asChild>
+                      <Button
+                        type="button"
+                        variant="ghost"
+                        size="icon"
+                        className="h-5 w-5 rounded-full text-muted-foreground"
+                        aria-label={t("settings.animatedThumbnailsAriaLabel")}
+                      >
+                        <CircleHelp className="h-4 w-4" />
+                      </Button>
+                    </TooltipTrigger>
+                    <TooltipContent side="top" sideOffset={6}>
+                      {t("settings.animatedThumbnailsHelp")}
+                    </TooltipContent>
+                  </Tooltip>
+                </TooltipProvider>
+              </div>
               <div>
                 <label className="block text-sm font-medium text-foreground mb-2">
                   {t("settings.language")}
@@ -358,6 +479,184 @@ export function SettingsPage() {
                   </Button>
                 </div>
               </div>
+
+              {imageMagickTestResult && (
+                <div className="space-y-2 text-sm text-muted-foreground">
+                  <p>
+                    {t("settings.converterPoolStatus", {
+                      active: imageMagickTestResult.pool.active,
+                      max: imageMagickTestResult.pool.max_concurrency,
+                      waiting: imageMagickTestResult.pool.waiting,
+                      timeout: imageMagickTestResult.pool.timeout_seconds,
+                    })}
+                  </p>
+                  <p>
+                    {t("settings.converterPoolStats", {
+                      completed: imageMagickTestResult.pool.completed,
+                      failed: imageMagickTestResult.pool.failed,
+                      timedOut: imageMagickTestResult.pool.timed_out,
+                    })}
+                  </p>
+                  <details>
+                    <summary className="cursor-pointer text-foreground">
+                      {t("settings.imageMagickFormats", {
+                        count: imageMagickTestResult.formats.length,
+                      })}
+                    </summary>
+                    <div className="mt-2 max-h-48 overflow-y-auto font-mono text-xs">
+                      {imageMagickTestResult.formats.map((format) => (
+                        <div key={format.name} title={format.description}>
+                          {format.name} {format.mode}
+                        </div>
+                      ))}
+                    </div>
+                  </details>
+                </div>
+              )}
+            </div>
+          </div>
+
+          <div className="bg-card border border-border rounded-lg p-6">
+            <div className="flex items-center gap-2 mb-4">
+              <h2 className="text-lg font-medium text-foreground">
+                {t("settings.ffmpegSection")}
+              </h2>
+              <TooltipProvider>
+                <Tooltip>
+                  <TooltipTrigger asChild>
+                    <Button
+                      type="button"
+                      variant="ghost"
+                      size="icon"
+                      className="h-5 w-5 rounded-full text-muted-foreground"
+                      aria-label={t("settings.ffmpegAriaLabel")}
+                    >
+                      <CircleHelp className="h-4 w-4" />
+                    </Button>
+                  </TooltipTrigger>
+                  <TooltipContent side="top" sideOffset={6}>
+                    {t("settings.ffmpegHelp")}
+                  </TooltipContent>
+                </Tooltip>
+              </TooltipProvider>
+            </div>
+
+            <div className="flex items-center gap-3">
+              <div className="flex items-center gap-2">
+                <Checkbox
+                  id="ffmpeg_enabled"
+                  checked={settings.ffmpeg_enabled}
+                  onCheckedChange={(checked) =>
+                    setLocalSettings({
+                      ...settings,
+                      ffmpeg_enabled: checked === true,
+                    })
+                  }
+                />
+                <label
+                  htmlFor="ffmpeg_enabled"
+                  className="text-sm text-foreground cursor-pointer"
+                >
+                  {t("settings.ffmpegEnabled")}
+                </label>
+              </div>
+              <Button
+                variant="outline"
+                onClick={handleTestFFmpeg}
+                disabled={testingFFmpeg}
+              >
+                {testingFFmpeg
+                  ? t("settings.testing")
+                  : t("settings.testCommand")}
+              </Button>
+            </div>
+          </div>
+
+          <div className="bg-card border border-border rounded-lg p-6">
+            <div className="flex items-center gap-2 mb-4">
+              <h2 className="text-lg font-medium text-foreground">
+                {t("settings.uploadLimitsSection")}
+              </h2>
+              <TooltipProvider>
+                <Tooltip>
+                  <TooltipTrigger asChild>
+                    <Button
+                      type="button"
+                      variant="ghost"
+                      size="icon"
+                      className="h-5 w-5 rounded-full text-muted-foreground"
+                      aria-label={t("settings.uploadLimitsAriaLabel")}
+                    >
+                      <CircleHelp className="h-4 w-4" />
+                    </Button>
+                  </TooltipTrigger>
+                  <TooltipContent side="top" sideOffset={6}>
+                    {t("settings.uploadLimitsHelp")}
+                  </TooltipContent>
+                </Tooltip>
+              </TooltipProvider>
+            </div>
+
+            <div className="grid gap-4 sm:grid-cols-3">
+              <div>
+                <label className="block text-sm font-medium text-foreground mb-2">
+                  {t("settings.uploadMaxRequestMB")}
+                </label>
+                <Input
+                  type="number"
+                  min={1}
+                  value={settings.upload_max_request_mb}
+                  onChange={(e) =>
+                    setLocalSettings({
+                      ...settings,
+                      upload_max_request_mb: Number(e.target.value),
+                    })
+                  }
+                />
+              </div>
+              <div>
+                <label className="block text-sm font-medium text-foreground mb-2">
+                  {t("settings.uploadMaxFileMB")}
+                </label>
+                <Input
+                  type="number"
+                  min={1}
+                  value={settings.upload_max_file_mb}
+                  onChange={(e) =>
+                    setLocalSettings({
+                      ...settings,
+                      upload_max_file_mb: Number(e.target.value),
+                    })
+                  }
+                />
+              </div>
+              <div>
+                <label className="block text-sm font-medium text-foreground mb-2">
+                  {t("settings.uploadMaxMegapixels")}
+                </label>
+                <Input
+                  type="number"
+                  min={1}
+                  value={settings.upload_max_megapixels}
+                  onChange={(e) =>
+                    setLocalSettings({
+                      ...settings,
+                      upload_max_megapixels: Number(e.target.value),
+                    })
+                  }
+                />
+              </div>
+            </div>
+            <div className="mt-4">
+              <label className="block text-sm font-medium text-foreground mb-2">
+                {t("settings.uploadFormatLimits")}
+              </label>
+              <Input
+                type="text"
+                placeholder="psd=300, mp4=200"
+                value={formatLimitsText}
+                onChange={(e) => setFormatLimitsText(e.target.value)}
+              />
             </div>
           </div>
 
@@ -452,3 +751,25 @@ export function SettingsPage() {
     </AdminLayout>
   );
 }
+
+function formatUploadFormatLimits(
+  limits: Record<string, number> | undefined,
+) {
+  return Object.entries(limits ?? {})
+    .sort(([a], [b]) => a.localeCompare(b))
+    .map(([format, mb]) => `${format}=${mb}`)
+    .join(", ");
+}
+
+function parseUploadFormatLimits(text: string) {
+  const limits: Record<string, number> = {};
+  for (const part of text.split(/[,\n]/)) {
+    const [rawFormat, rawLimit] = part.split("=");
+    const format = rawFormat?.trim().replace(/^\./, "").toLowerCase();
+    const limit = Number(rawLimit?.trim());
+    if (format && Number.isFinite(limit) && limit > 0) {
+      limits[format] = Math.floor(limit);
+    }
+  }
+  return limits;
+}
@@ -1,6 +1,7 @@
 import { useCallback, useEffect, useMemo, useState } from "react";
 import { useLocation, useNavigate, useParams } from "react-router-dom";
 import { useTranslation } from "react-i18next";
+import type { TFunction } from "i18next";
 import { toast } from "sonner";
 import { CircleHelp, Sparkles, Plus, Trash2 } from "lucide-react";
 import { AdminLayout } from "@/components/AdminLayout";
@@ -38,7 +39,9 @@ import type {
   AIImageMetadataKeyValue,
   DuplicateImageInfo,
   Image,
+  ConverterError,
   Tag,
+  UploadLimitError,
   Work,
 } from "@/types/api";
 import { useAuthStore } from "@/stores";
@@ -340,6 +343,11 @@ export function WorkEditPage() {
       }
     } catch (err) {
       console.error(t("works.saveFailed"), err);
+      const uploadErrorMessage = describeUploadError(err, t);
+      if (uploadErrorMessage) {
+        toast.error(uploadErrorMessage);
+        return;
+      }
       const backendMessage =
         typeof err === "object" &&
         err !== null &&
@@ -698,7 +706,7 @@ export function WorkEditPage() {
                 id="imageUpload"
                 type="file"
                 multiple
-                accept="image/*,.psd,.ai,.heic,.heif,.avif"
+                accept="image/*,video/mp4,video/webm,video/quicktime,.psd,.ai,.heic,.heif,.avif,.mp4,.m4v,.mov,.webm,.zip"
                 className="hidden"
                 onChange={(e) => handleFiles(e.target.files)}
               />
@@ -805,6 +813,14 @@ export function WorkEditPage() {
                           {item.file.name}
                         </span>
                       </div>
+                    ) : isVideoUploadFile(item.file) ? (
+                      <video
+                        src={item.previewUrl}
+                        aria-label={isNew ? `upload-${index}` : `new-${index}`}
+                        className="w-full aspect-square object-cover"
+                        muted
+                        playsInline
+                      />
                     ) : (
                       <img
                         src={item.previewUrl}
@@ -1211,6 +1227,9 @@ function shouldShowTranscodePlaceholder(file: File): boolean {
     mime === "image/heic" ||
     mime === "image/heif" ||
     mime === "image/avif" ||
+    mime === "application/zip" ||
+    mime === "application/x-zip-compressed" ||
+    ext === "zip" ||
     ext === "tif" ||
     ext === "tiff" ||
     ext === "bmp" ||
@@ -1223,13 +1242,27 @@ function shouldShowTranscodePlaceholder(file: File): boolean {
   );
 }
 
+function isVideoUploadFile(file: File): boolean {
+  const ext = file.name.split(".").pop()?.toLowerCase() ?? "";
+  return (
+    file.type.toLowerCase().startsWith("video/") ||
+    ext === "mp4" ||
+    ext === "m4v" ||
+    ext === "mov" ||
+    ext === "webm"
+  );
+}
+
 function isSupportedUploadFile(file: File): boolean {
   const mime = file.type.toLowerCase();
   const ext = file.name.split(".").pop()?.toLowerCase() ?? "";
-  if (mime.startsWith("image/")) {
+  if (mime.startsWith("image/") || isVideoUploadFile(file)) {
     return true;
   }
   return (
+    ext === "zip" ||
+    mime === "application/zip" ||
+    mime === "application/x-zip-compressed" ||
     ext === "psd" ||
     ext === "ai" ||
     ext === "heic" ||
@@ -1242,3 +1275,43 @@ function isSupportedUploadFile(file: File): boolean {
     mime === "application/illustrator"
   );
 }
+
+function describeUploadError(err: unknown, t: TFunction): string {
+  const response = (
+    err as {
+      response?: {
+        data?: { code?: number; data?: UploadLimitError | ConverterError };
+      };
+    }
+  )?.response?.data;
+  if (response?.code === 5002) {
+    const converterError = response.data as ConverterError | undefined;
+    return converterError?.timed_out
+      ? t("works.converterTimedOut", {
+          command: converterError.command,
+          timeout: converterError.timeout,
+        })
+      : "";
+  }
+  const data = response?.data as UploadLimitError | undefined;
+  if (!data || typeof data.code !== "number") {
+    return "";
+  }
+  const limitMB = Math.floor(data.limit / (1024 * 1024));
+  switch (data.code) {
+    case 1101:
+      return t("works.uploadLimit.requestTooLarge", { limit: limitMB });
+    case 1102:
+      return t("works.uploadLimit.fileTooLarge", {
+        filename: data.filename,
+        format: data.format?.toUpperCase(),
+        limit: limitMB,
+      });
+    case 1103:
+      return t("works.uploadLimit.pixelsExceeded", {
+        filename: data.filename,
+        limit: Math.floor(data.limit / 1000000),
+      });
+  }
+  return "";
+}
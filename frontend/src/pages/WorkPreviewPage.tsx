@@ -96,25 +96,38 @@ function normalizePublicImagePath(
 function resolveDisplaySource(img: Image): {
   path: string;
   variant: "original" | "thumbnail" | "transcoded";
+  mediaType: "image" | "video";
 } {
+  if (img.media_type === "video" && img.original_path) {
+    return { path: img.original_path, variant: "original", mediaType: "video" };
+  }
   if (img.transcoded_path) {
-    return { path: img.transcoded_path, variant: "transcoded" };
+    return {
+      path: img.transcoded_path,
+      variant: "transcoded",
+      mediaType: "image",
+    };
   }
   if (img.original_path) {
-    return { path: img.original_path, variant: "original" };
+    return { path: img.original_path, variant: "original", mediaType: "image" };
   }
-  return { path: img.thumbnail_path, variant: "thumbnail" };
+  return { path: img.thumbnail_path, variant: "thumbnail", mediaType: "image" };
 }
 
 function supportsExifByOriginalPath(path?: string): boolean {
   if (!path) return false;
   const cleaned = path.toLowerCase();
-  return (
-    cleaned.endsWith(".jpg") ||
-    cleaned.endsWith(".jpeg") ||
-    cleaned.endsWith(".tif") ||
-    cleaned.endsWith(".tiff")
-  );
+  return [
+    ".jpg",
+    ".jpeg",
+    ".tif",
+    ".tiff",
+    ".png",
+    ".webp",
+    ".heic",
+    ".heif",
+    ".avif",
+  ].some((ext) => cleaned.endsWith(ext));
 }
 
 export function WorkPreviewPage({
@@ -641,6 +654,7 @@ export function WorkPreviewPage({
                           path={display.path}
                           alt={work?.title ?? ""}
                           variant={display.variant}
+                          mediaType={display.mediaType}
                           publicAccess={publicMode}
                           className="w-full max-h-155 object-contain bg-muted"
                         />
@@ -766,6 +780,23 @@ export function WorkPreviewPage({
               <div>
                 {t("preview.createdAt")}: {formatDateTime(work.created_at)}
               </div>
+              {images[0]?.palette?.length ? (
+                <div className="flex items-center gap-2">
+                  <span className="text-foreground">{t("preview.palette")}</span>
+                  <div className="flex h-4 w-40 overflow-hidden rounded border border-border">
+                    {images[0].palette.map((color) => (
+                      <div
+                        key={color.hex}
+                        title={`${color.hex} ${Math.round(color.weight * 100)}%`}
+                        style={{
+                          backgroundColor: color.hex,
+                          flexGrow: color.weight,
+                        }}
+                      />
+                    ))}
+                  </div>
+                </div>
+              ) : null}
             </div>
 
             {publicMode && (
@@ -1330,8 +1361,14 @@ export function WorkPreviewPage({
                   path={activeDisplay.path}
                   alt={work?.title ?? ""}
                   variant={activeDisplay.variant}
+                  mediaType={activeDisplay.mediaType}
+                  controls={activeDisplay.mediaType === "video"}
                   publicAccess={publicMode}
-                  className="max-h-[90vh] max-w-[90vw] object-contain pointer-events-none select-none"
+                  className={
+                    activeDisplay.mediaType === "video"
+                      ? "max-h-[90vh] max-w-[90vw] object-contain select-none"
+                      : "max-h-[90vh] max-w-[90vw] object-contain pointer-events-none select-none"
+                  }
                 />
               )}
             </div>
@@ -0,0 +1,22 @@
+import api from "@/services/api";
+import type {
+  ApiResponse,
+  AIMetadataKind,
+  AIMetadataUsageItem,
+} from "@/types/api";
+
+export const aiMetadataService = {
+  list: (
+    kind: AIMetadataKind,
+    params?: { keyword?: string; limit?: number },
+  ) =>
+    api.get<ApiResponse<{ items: AIMetadataUsageItem[] }>>(
+      `/api/ai-metadata/${kind}`,
+      { params },
+    ),
+
+  count: (kind: AIMetadataKind, keyword?: string) =>
+    api.get<ApiResponse<{ count: number }>>(`/api/ai-metadata/${kind}/count`, {
+      params: { keyword },
+    }),
+};
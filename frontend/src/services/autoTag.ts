@@ -0,0 +1,28 @@
+import api from "@/services/api";
+import type {
+  ApiResponse,
+  AutoTagRule,
+  AutoTagRuleRequest,
+  AutoTagPreviewRequest,
+  AutoTagPreviewResult,
+} from "@/types/api";
+
+export const autoTagService = {
+  list: () =>
+    api.get<ApiResponse<{ items: AutoTagRule[] }>>("/api/auto-tag-rules"),
+
+  create: (data: AutoTagRuleRequest) =>
+    api.post<ApiResponse<AutoTagRule>>("/api/auto-tag-rules", data),
+
+  update: (id: number, data: AutoTagRuleRequest) =>
+    api.put<ApiResponse<AutoTagRule>>(`/api/auto-tag-rules/${id}`, data),
+
+  delete: (id: number) =>
+    api.delete<ApiResponse<null>>(`/api/auto-tag-rules/${id}`),
+
+  preview: (data: AutoTagPreviewRequest) =>
+    api.post<ApiResponse<AutoTagPreviewResult>>(
+      "/api/auto-tag-rules/preview",
+      data,
+    ),
+};
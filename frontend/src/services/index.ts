@@ -4,3 +4,6 @@ export * from "@/services/system";
 export * from "@/services/work";
 export * from "@/services/tag";
 export * from "@/services/collection";
+export * from "@/services/trash";
+export * from "@/services/autoTag";
+export * from "@/services/aiMetadata";
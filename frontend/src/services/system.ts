@@ -6,6 +6,7 @@ import type {
   SystemSettings,
   SystemStatistics,
   ImageMagickTestResult,
+  FFmpegTestResult,
 } from "@/types/api";
 
 export const systemService = {
@@ -18,7 +19,7 @@ export const systemService = {
     api.get<ApiResponse<SystemSettings>>("/api/system/settings"),
 
   updateSettings: (data: Partial<SystemSettings>) =>
-    api.put<ApiResponse<void>>("/api/system/settings", data),
+    api.put<ApiResponse<SystemSettings>>("/api/system/settings", data),
 
   getStatistics: () =>
     api.get<ApiResponse<SystemStatistics>>("/api/system/statistics"),
@@ -30,4 +31,7 @@ export const systemService = {
         params: version ? { version } : undefined,
       },
     ),
+
+  testFFmpeg: () =>
+    api.get<ApiResponse<FFmpegTestResult>>("/api/system/ffmpeg/test"),
 };
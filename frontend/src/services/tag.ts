@@ -6,13 +6,24 @@ import type {
   UpdateTagRequest,
   BatchCreateTagsRequest,
   BatchCreateTagsResponse,
+  TagCategory,
+  TagCategoryName,
+  RelatedTagsResult,
+  TagSuggestion,
 } from "@/types/api";
 
 export const tagService = {
-  list: (params?: { keyword?: string; include_count?: boolean }) =>
-    api.get<ApiResponse<{ items: Tag[] }>>("/api/tags", { params }),
+  list: (params?: {
+    keyword?: string;
+    category?: TagCategoryName;
+    include_count?: boolean;
+  }) => api.get<ApiResponse<{ items: Tag[] }>>("/api/tags", { params }),
 
-  listPublic: (params?: { keyword?: string; include_count?: boolean }) =>
+  listPublic: (params?: {
+    keyword?: string;
+    category?: TagCategoryName;
+    include_count?: boolean;
+  }) =>
     api.get<ApiResponse<{ items: Tag[] }>>("/api/public/tags", { params }),
 
   create: (data: CreateTagRequest) =>
@@ -25,4 +36,52 @@ export const tagService = {
 
   batchCreate: (data: BatchCreateTagsRequest) =>
     api.post<ApiResponse<BatchCreateTagsResponse>>("/api/tags/batch", data),
+
+  updateParent: (id: number, parentId: number | null) =>
+    api.put<ApiResponse<Tag>>(`/api/tags/${id}/parent`, {
+      parent_id: parentId,
+    }),
+
+  addAlias: (id: number, name: string) =>
+    api.post<ApiResponse<Tag>>(`/api/tags/${id}/aliases`, { name }),
+
+  deleteAlias: (id: number, aliasId: number) =>
+    api.delete<ApiResponse<void>>(`/api/tags/${id}/aliases/${aliasId}`),
+
+  addImplication: (id: number, impliedTagId: number) =>
+    api.post<ApiResponse<Tag>>(`/api/tags/${id}/implications`, {
+      implied_tag_id: impliedTagId,
+    }),
+
+  deleteImplication: (id: number, impliedTagId: number) =>
+    api.delete<ApiResponse<void>>(
+      `/api/tags/${id}/implications/${impliedTagId}`,
+    ),
+
+  merge: (id: number, targetId: number, createAlias = false) =>
+    api.post<ApiResponse<Tag>>(`/api/tags/${id}/merge`, {
+      target_id: targetId,
+      create_alias: createAlias,
+    }),
+
+  listCategories: () =>
+    api.get<ApiResponse<{ items: TagCategory[] }>>("/api/tags/categories"),
+
+  updateCategory: (
+    name: TagCategoryName,
+    data: { color?: string; sort_priority?: number },
+  ) => api.put<ApiResponse<TagCategory>>(`/api/tags/categories/${name}`, data),
+
+  related: (params: {
+    tag_ids: string;
+    method?: "cooccurrence" | "pmi";
+    min_count?: number;
+    limit?: number;
+  }) =>
+    api.get<ApiResponse<RelatedTagsResult>>("/api/tags/related", { params }),
+
+  autocomplete: (q: string, limit?: number) =>
+    api.get<ApiResponse<{ items: TagSuggestion[] }>>("/api/tags/autocomplete", {
+      params: { q, limit },
+    }),
 };
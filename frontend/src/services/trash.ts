@@ -0,0 +1,34 @@
+import api from "@/services/api";
+import type {
+  ApiResponse,
+  WorkPagedResult,
+  TrashedImage,
+  RestoreTrashResponse,
+  EmptyTrashResponse,
+} from "@/types/api";
+
+export const trashService = {
+  listWorks: (params?: { page?: number; page_size?: number }) =>
+    api.get<ApiResponse<WorkPagedResult>>("/api/trash/works", { params }),
+
+  restoreWorks: (ids: number[]) =>
+    api.post<ApiResponse<RestoreTrashResponse>>("/api/trash/works/restore", {
+      ids,
+    }),
+
+  listImages: () =>
+    api.get<ApiResponse<{ items: TrashedImage[] }>>("/api/trash/images"),
+
+  restoreImages: (ids: number[]) =>
+    api.post<ApiResponse<RestoreTrashResponse>>("/api/trash/images/restore", {
+      ids,
+    }),
+
+  empty: (ids?: number[], imageIds?: number[]) =>
+    api.delete<ApiResponse<EmptyTrashResponse>>("/api/trash", {
+      data:
+        (ids && ids.length > 0) || (imageIds && imageIds.length > 0)
+          ? { ids, image_ids: imageIds }
+          : undefined,
+    }),
+};
@@ -5,11 +5,23 @@ import type {
   UpdateWorkRequest,
   WorkListParams,
   WorkPagedResult,
+  RandomWorksParams,
+  RandomWorksResult,
+  OnThisDayResult,
+  WorkTimelineResult,
+  WorkGeoResult,
   ImageUploadResponse,
   CheckDuplicateImagesRequest,
   CheckDuplicateImagesResponse,
+  RescanAIMetadataRequest,
+  RescanAIMetadataStatus,
   ImageExifInfo,
   AIImageMetadata,
+  AIMetadataFormat,
+  ImportAIMetadataRequest,
+  AuditLogPagedResult,
+  BatchEditRequest,
+  BatchEditResult,
 } from "@/types/api";
 
 export const workService = {
@@ -19,6 +31,34 @@ export const workService = {
   listPublic: (params?: WorkListParams) =>
     api.get<ApiResponse<WorkPagedResult>>("/api/public/works", { params }),
 
+  random: (params?: RandomWorksParams) =>
+    api.get<ApiResponse<RandomWorksResult>>("/api/works/random", { params }),
+
+  randomPublic: (params?: RandomWorksParams) =>
+    api.get<ApiResponse<RandomWorksResult>>("/api/public/works/random", {
+      params,
+    }),
+
+  onThisDay: (params?: WorkListParams & { date?: string; limit?: number }) =>
+    api.get<ApiResponse<OnThisDayResult>>("/api/works/on-this-day", {
+      params,
+    }),
+
+  onThisDayPublic: (params?: WorkListParams & { date?: string; limit?: number }) =>
+    api.get<ApiResponse<OnThisDayResult>>("/api/public/works/on-this-day", {
+      params,
+    }),
+
+  timeline: (params?: WorkListParams) =>
+    api.get<ApiResponse<WorkTimelineResult>>("/api/works/timeline", {
+      params,
+    }),
+
+  geo: (params?: WorkListParams & { limit?: number }) =>
+    api.get<ApiResponse<WorkGeoResult>>("/api/works/geo", {
+      params,
+    }),
+
   create: (data: FormData) =>
     api.post<ApiResponse<Work>>("/api/works", data, {
       headers: { "Content-Type": "multipart/form-data" },
@@ -32,6 +72,14 @@ export const workService = {
   update: (id: number, data: UpdateWorkRequest) =>
     api.put<ApiResponse<Work>>(`/api/works/${id}`, data),
 
+  history: (id: number, params?: { page?: number; page_size?: number }) =>
+    api.get<ApiResponse<AuditLogPagedResult>>(`/api/works/${id}/history`, {
+      params,
+    }),
+
+  revert: (id: number, logId: number) =>
+    api.post<ApiResponse<Work>>(`/api/works/${id}/history/${logId}/revert`),
+
   delete: (id: number) => api.delete<ApiResponse<void>>(`/api/works/${id}`),
 
   batchDelete: (ids: number[]) =>
@@ -45,6 +93,11 @@ export const workService = {
       is_public: isPublic,
     }),
 
+  batchEdit: (data: BatchEditRequest, filter?: WorkListParams) =>
+    api.post<ApiResponse<BatchEditResult>>("/api/works/batch/edit", data, {
+      params: filter,
+    }),
+
   addImages: (id: number, formData: FormData) =>
     api.post<ApiResponse<ImageUploadResponse>>(
       `/api/works/${id}/images`,
@@ -74,14 +127,41 @@ export const workService = {
       },
     ),
 
-  exportImages: () =>
+  exportImageAIMetadata: (
+    id: number,
+    imageId: number,
+    format: AIMetadataFormat = "a1111",
+  ) =>
+    api.get<Blob>(`/api/works/${id}/images/${imageId}/ai-metadata/export`, {
+      params: { format },
+      responseType: "blob",
+    }),
+
+  importImageAIMetadata: (
+    id: number,
+    imageId: number,
+    data: ImportAIMetadataRequest | FormData,
+  ) =>
+    api.post<ApiResponse<AIImageMetadata>>(
+      `/api/works/${id}/images/${imageId}/ai-metadata/import`,
+      data,
+      data instanceof FormData
+        ? { headers: { "Content-Type": "multipart/form-data" } }
+        : undefined,
+    ),
+
+  exportImages: (embedMetadata?: boolean) =>
     api.get("/api/works/export/images", {
+      params: embedMetadata ? { embed_metadata: true } : undefined,
       responseType: "blob",
     }),
 
-  downloadImages: (id: number, imageId?: number) =>
+  downloadImages: (id: number, imageId?: number, embedMetadata?: boolean) =>
     api.get<Blob>(`/api/works/${id}/download`, {
-      params: typeof imageId === "number" ? { image_id: imageId } : undefined,
+      params: {
+        image_id: typeof imageId === "number" ? imageId : undefined,
+        embed_metadata: embedMetadata || undefined,
+      },
       responseType: "blob",
     }),
 
@@ -91,6 +171,17 @@ export const workService = {
       data,
     ),
 
+  rescanAIMetadata: (data?: RescanAIMetadataRequest) =>
+    api.post<ApiResponse<RescanAIMetadataStatus>>(
+      "/api/works/images/ai-metadata/rescan",
+      data ?? {},
+    ),
+
+  getAIMetadataRescanStatus: () =>
+    api.get<ApiResponse<RescanAIMetadataStatus>>(
+      "/api/works/images/ai-metadata/rescan",
+    ),
+
   getImageExif: (id: number, imageId: number) =>
     api.get<ApiResponse<ImageExifInfo>>(
       `/api/works/${id}/images/${imageId}/exif`,
@@ -50,12 +50,63 @@ export interface SystemSettings {
   site_title: string;
   imagemagick_enabled: boolean;
   imagemagick_version: "v6" | "v7";
+  trash_retention_days: number;
+  public_strip_sensitive_exif: boolean;
+  animated_thumbnails_enabled: boolean;
+  ffmpeg_enabled: boolean;
+  upload_max_request_mb: number;
+  upload_max_file_mb: number;
+  upload_format_limits_mb: Record<string, number>;
+  upload_max_megapixels: number;
+}
+
+export interface UploadLimitError {
+  code: number;
+  message: string;
+  filename?: string;
+  format?: string;
+  limit: number;
+  actual?: number;
+}
+
+export interface ConverterPoolStatus {
+  max_concurrency: number;
+  active: number;
+  waiting: number;
+  completed: number;
+  failed: number;
+  timed_out: number;
+  timeout_seconds: number;
+}
+
+export interface ImageMagickFormat {
+  name: string;
+  mode: string;
+  description: string;
 }
 
 export interface ImageMagickTestResult {
   available: boolean;
   command: string;
   message: string;
+  pool: ConverterPoolStatus;
+  formats: ImageMagickFormat[];
+}
+
+export interface FFmpegTestResult {
+  available: boolean;
+  command: string;
+  message: string;
+  pool: ConverterPoolStatus;
+}
+
+export interface ConverterError {
+  command: string;
+  exit_code: number;
+  stderr?: string;
+  timed_out: boolean;
+  timeout?: string;
+  canceled: boolean;
 }
 
 export interface SystemStatistics {
@@ -64,6 +115,24 @@ export interface SystemStatistics {
   tag_count: number;
   collection_count: number;
   duplicate_image_groups: DuplicateImageGroup[];
+  top_tag_pairs: TagPairStat[];
+  tags_used_once: TagStatItem[];
+  tags_used_once_count: number;
+  unused_tags: TagStatItem[];
+  unused_tag_count: number;
+}
+
+export interface TagStatItem {
+  id: number;
+  name: string;
+  category: string;
+  work_count: number;
+}
+
+export interface TagPairStat {
+  tag_a: TagStatItem;
+  tag_b: TagStatItem;
+  count: number;
 }
 
 export interface DuplicateImageGroup {
@@ -79,20 +148,79 @@ export interface DuplicateImageWorkRef {
 }
 
 // Tag
+export interface TagAlias {
+  id: number;
+  name: string;
+}
+
 export interface Tag {
   id: number;
   name: string;
   is_system: boolean;
+  category: TagCategoryName;
+  transliteration?: string;
+  parent_id?: number;
+  aliases?: TagAlias[];
+  implied_tag_ids?: number[];
   created_at: string;
   work_count?: number;
 }
 
+export type TagCategoryName =
+  | "artist"
+  | "character"
+  | "series"
+  | "meta"
+  | "general";
+
+export interface TagCategory {
+  name: TagCategoryName;
+  color: string;
+  sort_priority: number;
+}
+
+export interface TagGroup {
+  category: TagCategoryName;
+  color: string;
+  sort_priority: number;
+  tags: Tag[];
+}
+
+export interface RelatedTag {
+  id: number;
+  name: string;
+  category: TagCategoryName;
+  co_count: number;
+  work_count: number;
+  score: number;
+}
+
+export interface RelatedTagsResult {
+  method: "cooccurrence" | "pmi";
+  context_work_count: number;
+  total_work_count: number;
+  items: RelatedTag[];
+}
+
+export interface TagSuggestion {
+  id: number;
+  name: string;
+  category: TagCategoryName;
+  work_count: number;
+  matched: string;
+  match_type: "name" | "alias" | "transliteration";
+}
+
 export interface CreateTagRequest {
   name: string;
+  category?: TagCategoryName;
+  transliteration?: string;
 }
 
 export interface UpdateTagRequest {
   name: string;
+  category?: TagCategoryName;
+  transliteration?: string;
 }
 
 export interface BatchCreateTagsRequest {
@@ -112,12 +240,25 @@ export interface Image {
   transcoded_path?: string;
   image_hash?: string;
   ai_metadata?: AIImageMetadata;
+  palette?: ImagePaletteColor[];
   file_size?: number;
   width: number;
   height: number;
+  frame_count?: number;
+  duration_ms?: number;
+  loop_count?: number;
+  animated_thumbnail_path?: string;
+  media_type?: MediaType;
   sort_order: number;
 }
 
+export type MediaType = "image" | "video" | "ugoira";
+
+export interface ImagePaletteColor {
+  hex: string;
+  weight: number;
+}
+
 export interface AIImageMetadata {
   checkpoint: string;
   prompt: string;
@@ -130,6 +271,58 @@ export interface AIImageMetadataKeyValue {
   values: string[];
 }
 
+// Auto tag rules
+export type AutoTagMatchType = "prompt_token" | "regex" | "checkpoint" | "lora";
+
+export interface AutoTagRule {
+  id: number;
+  name: string;
+  match_type: AutoTagMatchType;
+  pattern: string;
+  enabled: boolean;
+  tag?: Tag;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface AutoTagRuleRequest {
+  name?: string;
+  match_type: AutoTagMatchType;
+  pattern: string;
+  tag_id: number;
+  enabled?: boolean;
+}
+
+export interface AutoTagPreviewRequest {
+  work_id?: number;
+  ai_metadata?: AIImageMetadata;
+}
+
+export interface AutoTagMatch {
+  rule_id: number;
+  rule_name: string;
+  match_type: AutoTagMatchType | "ai_metadata";
+  matched: string;
+  tag_id: number;
+}
+
+export interface AutoTagPreviewResult {
+  matches: AutoTagMatch[];
+  tags: Tag[];
+  new_tag_ids: number[];
+}
+
+export type AIMetadataKind = "checkpoints" | "loras" | "samplers" | "seeds";
+
+export interface AIMetadataUsageItem {
+  id?: number;
+  name: string;
+  image_count: number;
+  work_count: number;
+  min_weight?: number;
+  max_weight?: number;
+}
+
 export interface Work {
   id: number;
   title: string;
@@ -138,9 +331,11 @@ export interface Work {
   is_public: boolean;
   created_at: string;
   updated_at: string;
+  deleted_at?: string;
   cover_image?: Image;
   image_count?: number;
   tags?: Tag[];
+  tag_groups?: TagGroup[];
 }
 
 export interface CreateWorkRequest {
@@ -169,6 +364,42 @@ export interface WorkListParams {
   is_public?: boolean;
   sort_by?: string;
   sort_order?: string;
+  created_from?: string;
+  created_to?: string;
+  width_min?: number;
+  width_max?: number;
+  height_min?: number;
+  height_max?: number;
+  aspect_ratio?: "landscape" | "portrait" | "square" | "panorama" | "tall";
+  formats?: string;
+  image_count_min?: number;
+  image_count_max?: number;
+  has_ai_metadata?: boolean;
+  untagged?: boolean;
+  not_in_collection?: boolean;
+  ai_checkpoint_id?: number;
+  ai_sampler_id?: number;
+  ai_lora_id?: number;
+  ai_lora_weight_min?: number;
+  ai_lora_weight_max?: number;
+  ai_seed?: string;
+  exif_camera?: string;
+  exif_lens?: string;
+  iso_min?: number;
+  iso_max?: number;
+  focal_length_min?: number;
+  focal_length_max?: number;
+  taken_from?: string;
+  taken_to?: string;
+  has_gps?: boolean;
+  min_lat?: number;
+  max_lat?: number;
+  min_lng?: number;
+  max_lng?: number;
+  color?: string;
+  color_tolerance?: number;
+  animated?: boolean;
+  media_type?: MediaType;
 }
 
 export interface WorkPagedResult {
@@ -179,6 +410,47 @@ export interface WorkPagedResult {
   total_pages: number;
 }
 
+export interface RandomWorksParams extends WorkListParams {
+  count?: number;
+  seed?: string;
+}
+
+export interface RandomWorksResult {
+  seed: string;
+  items: Work[];
+}
+
+export interface OnThisDayResult {
+  date: string;
+  items: Work[];
+}
+
+export interface WorkTimelineBucket {
+  year: number;
+  month: number;
+  count: number;
+}
+
+export interface WorkTimelineResult {
+  items: WorkTimelineBucket[];
+  undated: number;
+}
+
+export interface WorkGeoPoint {
+  work_id: number;
+  image_id: number;
+  title: string;
+  thumbnail_path: string;
+  latitude: number;
+  longitude: number;
+  taken_at?: string;
+}
+
+export interface WorkGeoResult {
+  items: WorkGeoPoint[];
+  truncated: boolean;
+}
+
 // Collection
 export interface CollectionPath {
   id: number;
@@ -245,6 +517,11 @@ export interface UploadedImage {
   width: number;
   height: number;
   original_filename: string;
+  frame_count: number;
+  duration_ms?: number;
+  loop_count: number;
+  animated_thumbnail_path?: string;
+  media_type: MediaType;
 }
 
 export interface ImageUploadResponse {
@@ -266,16 +543,136 @@ export interface CheckDuplicateImagesResponse {
   duplicates: DuplicateImageInfo[];
 }
 
+export interface RescanAIMetadataRequest {
+  work_ids?: number[];
+  overwrite?: boolean;
+}
+
+export interface RescanAIMetadataStatus {
+  running: boolean;
+  total: number;
+  scanned: number;
+  updated: number;
+  skipped: number;
+  failed: number;
+  started_at: string | null;
+  finished_at: string | null;
+}
+
+export type AIMetadataFormat = "a1111" | "civitai";
+
+export interface ImportAIMetadataRequest {
+  format?: AIMetadataFormat;
+  content: string;
+}
+
 export interface ImageExifField {
   key: string;
   value: string;
 }
 
+export interface ImageExifSummary {
+  camera_make?: string;
+  camera_model?: string;
+  lens_model?: string;
+  focal_length?: number;
+  f_number?: number;
+  exposure_time?: string;
+  iso?: number;
+  taken_at?: string;
+  gps_latitude?: number;
+  gps_longitude?: number;
+}
+
 export interface ImageExifInfo {
   work_id: number;
   image_id: number;
   has_exif: boolean;
   fields: ImageExifField[];
+  summary?: ImageExifSummary;
   format: string;
   filename: string;
 }
+
+// Trash
+export interface TrashedImage extends Image {
+  work_id: number;
+  deleted_at: string;
+}
+
+export interface RestoreTrashResponse {
+  restored_count: number;
+}
+
+export interface EmptyTrashResponse {
+  purged_works: number;
+  purged_images: number;
+}
+
+// Audit log
+export interface AuditFieldChange {
+  field: string;
+  before: unknown;
+  after: unknown;
+}
+
+export interface AuditLogEntry {
+  id: number;
+  user_id: number;
+  username: string;
+  action: string;
+  entity_type: string;
+  entity_id: number;
+  work_id: number;
+  before?: Record<string, unknown>;
+  after?: Record<string, unknown>;
+  changes: AuditFieldChange[];
+  created_at: string;
+}
+
+export interface AuditLogPagedResult {
+  items: AuditLogEntry[];
+  total: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+}
+
+// Batch edit
+export type BatchEditOperationType =
+  | "add_tags"
+  | "remove_tags"
+  | "set_rating"
+  | "set_public"
+  | "prepend_title"
+  | "add_to_collection"
+  | "remove_from_collection";
+
+export interface BatchEditOperation {
+  type: BatchEditOperationType;
+  tag_ids?: number[];
+  rating?: number;
+  is_public?: boolean;
+  text?: string;
+  collection_id?: number;
+}
+
+export interface BatchEditRequest {
+  work_ids?: number[];
+  use_filter?: boolean;
+  all?: boolean;
+  operations: BatchEditOperation[];
+}
+
+export interface BatchEditItemResult {
+  work_id: number;
+  status: "updated" | "unchanged" | "not_found";
+}
+
+export interface BatchEditResult {
+  total: number;
+  updated: number;
+  unchanged: number;
+  not_found: number;
+  items: BatchEditItemResult[];
+}
@@ -8,10 +8,11 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	JWT      JWTConfig      `yaml:"jwt"`
-	Storage  StorageConfig  `yaml:"storage"`
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Converters ConvertersConfig `yaml:"converters"`
 }
 
 type ServerConfig struct {
@@ -29,6 +30,11 @@ type JWTConfig struct {
 	ExpireHours int    `yaml:"expire_hours"`
 }
 
+type ConvertersConfig struct {
+	MaxConcurrency int `yaml:"max_concurrency"`
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
 type StorageConfig struct {
 	Main       string                `yaml:"main"`
 	Backup     string                `yaml:"backup"`
@@ -92,6 +98,12 @@ func Load(configPath string) error {
 			provider.Region = "us-east-1"
 		}
 	}
+	if GlobalConfig.Converters.MaxConcurrency <= 0 {
+		GlobalConfig.Converters.MaxConcurrency = 2
+	}
+	if GlobalConfig.Converters.TimeoutSeconds <= 0 {
+		GlobalConfig.Converters.TimeoutSeconds = 120
+	}
 	if GlobalConfig.Storage.BackupMode != "write_only" && GlobalConfig.Storage.BackupMode != "mirror" {
 		return fmt.Errorf("invalid storage.backup_mode: %s (allowed: write_only, mirror)", GlobalConfig.Storage.BackupMode)
 	}
@@ -91,11 +91,23 @@ func AutoMigrate() error {
 		&model.User{},
 		&model.Setting{},
 		&model.Tag{},
+		&model.TagAlias{},
+		&model.TagImplication{},
+		&model.TagCategory{},
 		&model.Work{},
 		&model.WorkImage{},
 		&model.WorkTag{},
 		&model.Collection{},
 		&model.CollectionWork{},
+		&model.AuditLog{},
+		&model.AutoTagRule{},
+		&model.AICheckpoint{},
+		&model.AILora{},
+		&model.AISampler{},
+		&model.WorkImageGeneration{},
+		&model.WorkImageLora{},
+		&model.WorkImageEXIF{},
+		&model.WorkImageColor{},
 	)
 }
 
@@ -105,7 +117,10 @@ func InitializeDefaultData() error {
 		if err != nil {
 			return err
 		}
-		return ensureOptionalSettings()
+		if err := ensureOptionalSettings(); err != nil {
+			return err
+		}
+		return ensureTagCategories()
 	}
 
 	transaction := DB.Begin()
@@ -139,9 +154,9 @@ func InitializeDefaultData() error {
 	}
 
 	systemTags := []model.Tag{
-		{Name: "AI", IsSystem: true},
-		{Name: "R18", IsSystem: true},
-		{Name: "R18G", IsSystem: true},
+		{Name: "AI", IsSystem: true, Category: model.TagCategoryMeta},
+		{Name: "R18", IsSystem: true, Category: model.TagCategoryMeta},
+		{Name: "R18G", IsSystem: true, Category: model.TagCategoryMeta},
 	}
 	for _, tag := range systemTags {
 		if err := transaction.FirstOrCreate(&tag, model.Tag{Name: tag.Name}).Error; err != nil {
@@ -153,7 +168,10 @@ func InitializeDefaultData() error {
 	if err := transaction.Commit().Error; err != nil {
 		return err
 	}
-	return ensureOptionalSettings()
+	if err := ensureOptionalSettings(); err != nil {
+		return err
+	}
+	return ensureTagCategories()
 }
 
 func ensureOptionalSettings() error {
@@ -162,6 +180,14 @@ func ensureOptionalSettings() error {
 		{Key: "site_title", Value: "Illust Nest"},
 		{Key: "imagemagick_enabled", Value: "false"},
 		{Key: "imagemagick_version", Value: "v7"},
+		{Key: "trash_retention_days", Value: "30"},
+		{Key: "public_strip_sensitive_exif", Value: "true"},
+		{Key: "animated_thumbnails_enabled", Value: "false"},
+		{Key: "ffmpeg_enabled", Value: "false"},
+		{Key: "upload_max_request_mb", Value: "1024"},
+		{Key: "upload_max_file_mb", Value: "50"},
+		{Key: "upload_format_limits_mb", Value: "{}"},
+		{Key: "upload_max_megapixels", Value: "100"},
 	}
 	for _, item := range defaults {
 		if err := DB.Where("key = ?", item.Key).FirstOrCreate(&model.Setting{
@@ -173,3 +199,20 @@ func ensureOptionalSettings() error {
 	}
 	return nil
 }
+
+func ensureTagCategories() error {
+	defaults := []model.TagCategory{
+		{Name: model.TagCategoryArtist, Color: "#c0392b", SortPriority: 50},
+		{Name: model.TagCategoryCharacter, Color: "#27ae60", SortPriority: 40},
+		{Name: model.TagCategorySeries, Color: "#8e44ad", SortPriority: 30},
+		{Name: model.TagCategoryGeneral, Color: "#2980b9", SortPriority: 20},
+		{Name: model.TagCategoryMeta, Color: "#e67e22", SortPriority: 10},
+	}
+	for _, item := range defaults {
+		category := item
+		if err := DB.Where("name = ?", item.Name).FirstOrCreate(&category).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"illust-nest/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func currentActor(c *gin.Context) *service.Actor {
+	actor := &service.Actor{}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok {
+			actor.UserID = id
+		}
+	}
+	if username, exists := c.Get("username"); exists {
+		if name, ok := username.(string); ok {
+			actor.Username = name
+		}
+	}
+	return actor
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"illust-nest/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AIMetadataHandler struct {
+	aiMetadataService *service.AIMetadataService
+}
+
+func NewAIMetadataHandler(aiMetadataService *service.AIMetadataService) *AIMetadataHandler {
+	return &AIMetadataHandler{aiMetadataService: aiMetadataService}
+}
+
+func (h *AIMetadataHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	items, err := h.aiMetadataService.ListUsage(c.Param("kind"), c.Query("keyword"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrAIMetadataKindNotFound) {
+			NotFound(c)
+		} else {
+			InternalError(c)
+		}
+		return
+	}
+
+	Success(c, gin.H{"items": items})
+}
+
+func (h *AIMetadataHandler) Count(c *gin.Context) {
+	count, err := h.aiMetadataService.CountUsage(c.Param("kind"), c.Query("keyword"))
+	if err != nil {
+		if errors.Is(err, service.ErrAIMetadataKindNotFound) {
+			NotFound(c)
+		} else {
+			InternalError(c)
+		}
+		return
+	}
+
+	Success(c, gin.H{"count": count})
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"illust-nest/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AutoTagHandler struct {
+	autoTagService *service.AutoTagService
+}
+
+func NewAutoTagHandler(autoTagService *service.AutoTagService) *AutoTagHandler {
+	return &AutoTagHandler{autoTagService: autoTagService}
+}
+
+func (h *AutoTagHandler) List(c *gin.Context) {
+	rules, err := h.autoTagService.GetRules()
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, gin.H{"items": rules})
+}
+
+func (h *AutoTagHandler) Create(c *gin.Context) {
+	var req service.AutoTagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	rule, err := h.autoTagService.CreateRule(&req)
+	if err != nil {
+		respondAutoTagError(c, err)
+		return
+	}
+
+	Success(c, rule)
+}
+
+func (h *AutoTagHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid rule id")
+		return
+	}
+
+	var req service.AutoTagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	rule, err := h.autoTagService.UpdateRule(uint(id), &req)
+	if err != nil {
+		respondAutoTagError(c, err)
+		return
+	}
+
+	Success(c, rule)
+}
+
+func (h *AutoTagHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid rule id")
+		return
+	}
+
+	if err := h.autoTagService.DeleteRule(uint(id)); err != nil {
+		respondAutoTagError(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+func (h *AutoTagHandler) Preview(c *gin.Context) {
+	var req service.AutoTagPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.autoTagService.Preview(&req)
+	if err != nil {
+		respondAutoTagError(c, err)
+		return
+	}
+
+	Success(c, result)
+}
+
+func respondAutoTagError(c *gin.Context, err error) {
+	if ve, ok := err.(*service.ValidationError); ok {
+		Error(c, ve.Code, ve.Message)
+	} else if errors.Is(err, service.ErrAutoTagRuleNotFound) || errors.Is(err, service.ErrWorkNotFound) {
+		NotFound(c)
+	} else {
+		InternalError(c)
+	}
+}
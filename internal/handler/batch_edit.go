@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"illust-nest/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BatchEditHandler struct {
+	batchEditService *service.BatchEditService
+	workService      *service.WorkService
+}
+
+func NewBatchEditHandler(batchEditService *service.BatchEditService, workService *service.WorkService) *BatchEditHandler {
+	return &BatchEditHandler{
+		batchEditService: batchEditService,
+		workService:      workService,
+	}
+}
+
+func (h *BatchEditHandler) Apply(c *gin.Context) {
+	var req service.BatchEditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	var filter *service.WorkListParams
+	if req.UseFilter {
+		filter = parseWorkFilterQuery(c, h.workService)
+	}
+
+	result, err := h.batchEditService.Apply(currentActor(c), &req, filter)
+	if err != nil {
+		if ve, ok := err.(*service.ValidationError); ok {
+			ValidationErrorWithType(c, ve)
+		} else {
+			InternalErrorWithMessage(c, err.Error())
+		}
+		return
+	}
+
+	Success(c, result)
+}
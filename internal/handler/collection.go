@@ -82,7 +82,7 @@ func (h *CollectionHandler) SyncByWork(c *gin.Context) {
 		return
 	}
 
-	if err := h.collectionService.SyncWorkCollections(uint(workID), &req); err != nil {
+	if err := h.collectionService.SyncWorkCollections(currentActor(c), uint(workID), &req); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
 		} else {
@@ -156,6 +156,7 @@ func (h *CollectionHandler) GetWorks(c *gin.Context) {
 		SortBy:    c.DefaultQuery("sort_by", "created_at"),
 		SortOrder: c.DefaultQuery("sort_order", "desc"),
 	}
+	parseWorkAttributeQuery(c, params)
 
 	result, err := h.collectionService.GetCollectionWorks(uint(id), params)
 	if err != nil {
@@ -177,7 +178,7 @@ func (h *CollectionHandler) Create(c *gin.Context) {
 		return
 	}
 
-	collection, err := h.collectionService.CreateCollection(&req)
+	collection, err := h.collectionService.CreateCollection(currentActor(c), &req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
@@ -206,7 +207,7 @@ func (h *CollectionHandler) Update(c *gin.Context) {
 		return
 	}
 
-	collection, err := h.collectionService.UpdateCollection(uint(id), &req)
+	collection, err := h.collectionService.UpdateCollection(currentActor(c), uint(id), &req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
@@ -231,7 +232,7 @@ func (h *CollectionHandler) Delete(c *gin.Context) {
 
 	recursive := c.DefaultQuery("recursive", "false") == "true"
 
-	if err := h.collectionService.DeleteCollection(uint(id), recursive); err != nil {
+	if err := h.collectionService.DeleteCollection(currentActor(c), uint(id), recursive); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
 		} else {
@@ -257,7 +258,7 @@ func (h *CollectionHandler) AddWorks(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.collectionService.AddWorks(uint(id), &req)
+	resp, err := h.collectionService.AddWorks(currentActor(c), uint(id), &req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
@@ -296,7 +297,7 @@ func (h *CollectionHandler) RemoveWorks(c *gin.Context) {
 		WorkIDs: ids,
 	}
 
-	resp, err := h.collectionService.RemoveWorks(uint(id), req)
+	resp, err := h.collectionService.RemoveWorks(currentActor(c), uint(id), req)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
@@ -322,7 +323,7 @@ func (h *CollectionHandler) UpdateSortOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.collectionService.UpdateSortOrder(nil, &req); err != nil {
+	if err := h.collectionService.UpdateSortOrder(currentActor(c), nil, &req); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
 		} else if strings.Contains(err.Error(), "single-level") || strings.Contains(err.Error(), "flat collections") {
@@ -350,7 +351,7 @@ func (h *CollectionHandler) UpdateWorkSortOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.collectionService.UpdateWorkSortOrder(uint(id), &req); err != nil {
+	if err := h.collectionService.UpdateWorkSortOrder(currentActor(c), uint(id), &req); err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			NotFound(c)
 		} else {
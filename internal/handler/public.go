@@ -1,11 +1,8 @@
 package handler
 
 import (
-	"context"
 	"errors"
 	"illust-nest/internal/service"
-	"io"
-	"mime"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -73,6 +70,7 @@ func (h *PublicHandler) ListWorks(c *gin.Context) {
 		SortBy:    c.DefaultQuery("sort_by", "created_at"),
 		SortOrder: c.DefaultQuery("sort_order", "desc"),
 	}
+	parseWorkAttributeQuery(c, params)
 
 	result, err := h.workService.GetPublicWorks(params)
 	if err != nil {
@@ -83,11 +81,43 @@ func (h *PublicHandler) ListWorks(c *gin.Context) {
 	Success(c, result)
 }
 
+func (h *PublicHandler) RandomWorks(c *gin.Context) {
+	params := parseWorkFilterQuery(c, h.workService)
+	h.workService.RestrictToPublic(params)
+	count, seed := parseRandomQuery(c)
+
+	result, err := h.workService.GetRandomWorks(params, count, seed)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *PublicHandler) OnThisDayWorks(c *gin.Context) {
+	params := parseWorkFilterQuery(c, h.workService)
+	h.workService.RestrictToPublic(params)
+	date, ok := parseOnThisDayDate(c)
+	if !ok {
+		BadRequest(c, "invalid date")
+		return
+	}
+
+	result, err := h.workService.GetOnThisDayWorks(params, date, parseOnThisDayLimit(c))
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
 func (h *PublicHandler) ListTags(c *gin.Context) {
 	keyword := c.Query("keyword")
 	includeCount := c.DefaultQuery("include_count", "false") == "true"
 
-	tags, err := h.tagService.GetTags(keyword, includeCount)
+	tags, err := h.tagService.GetTags(keyword, c.Query("category"), includeCount)
 	if err != nil {
 		InternalError(c)
 		return
@@ -147,7 +177,7 @@ func (h *PublicHandler) GetImageEXIF(c *gin.Context) {
 		return
 	}
 
-	info, err := h.workService.GetImageEXIF(workID, targetImageID)
+	info, err := h.workService.GetPublicImageEXIF(workID, targetImageID)
 	if err != nil {
 		if errors.Is(err, service.ErrImageNotFound) {
 			NotFound(c)
@@ -196,25 +226,16 @@ func (h *PublicHandler) servePublicImage(c *gin.Context, prefix string, isThumbn
 		return
 	}
 
-	storage, err := service.GetStorageProvider()
+	file, objectInfo, err := h.workService.OpenPublicImage(relativePath, isThumbnail)
 	if err != nil {
+		if errors.Is(err, service.ErrImageNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
 		InternalError(c)
 		return
 	}
-
-	file, objectInfo, err := storage.Get(context.Background(), relativePath)
-	if err != nil {
-		c.AbortWithStatus(http.StatusNotFound)
-		return
-	}
 	defer file.Close()
 
-	if objectInfo.ContentType != "" {
-		c.Header("Content-Type", objectInfo.ContentType)
-	} else if contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(relativePath))); contentType != "" {
-		c.Header("Content-Type", contentType)
-	}
-	c.Header("Cache-Control", "public, max-age=31536000, immutable")
-	c.Status(http.StatusOK)
-	_, _ = io.Copy(c.Writer, file)
+	ServeStoredObject(c, file, objectInfo, relativePath, "public, max-age=31536000, immutable")
 }
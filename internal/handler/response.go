@@ -44,6 +44,14 @@ func BadRequest(c *gin.Context, message string) {
 	Error(c, 1001, message)
 }
 
+func BadRequestWithData(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusBadRequest, Response{
+		Code:    1001,
+		Message: message,
+		Data:    data,
+	})
+}
+
 func NotFound(c *gin.Context) {
 	Error(c, 1002, "resource not found")
 }
@@ -87,3 +95,23 @@ func InternalErrorWithMessage(c *gin.Context, message string) {
 func ValidationErrorWithType(c *gin.Context, err *service.ValidationError) {
 	Error(c, err.Code, err.Message)
 }
+
+func UploadLimitExceeded(c *gin.Context, err *service.UploadLimitError) {
+	c.JSON(http.StatusRequestEntityTooLarge, Response{
+		Code:    err.Code,
+		Message: err.Message,
+		Data:    err,
+	})
+}
+
+func ConverterFailed(c *gin.Context, message string, err *service.ConverterError) {
+	statusCode := http.StatusInternalServerError
+	if err.TimedOut {
+		statusCode = http.StatusGatewayTimeout
+	}
+	c.JSON(statusCode, Response{
+		Code:    5002,
+		Message: message,
+		Data:    err,
+	})
+}
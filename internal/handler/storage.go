@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"illust-nest/internal/service"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errStoredObjectSeekBackward = errors.New("stored object cannot seek backward")
+
+type storedObjectReader struct {
+	reader io.Reader
+	size   int64
+	read   int64
+	offset int64
+}
+
+func (r *storedObjectReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("negative seek position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *storedObjectReader) Read(p []byte) (int, error) {
+	if r.offset < r.read {
+		return 0, errStoredObjectSeekBackward
+	}
+	if r.offset > r.read {
+		skipped, err := io.CopyN(io.Discard, r.reader, r.offset-r.read)
+		r.read += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	r.offset += int64(n)
+	return n, err
+}
+
+func ServeStoredObject(c *gin.Context, file io.Reader, info service.ObjectInfo, logicalPath, cacheControl string) {
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(strings.ToLower(filepath.Ext(logicalPath)))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", cacheControl)
+
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, "", info.ModTime, seeker)
+		return
+	}
+	if info.Size > 0 {
+		http.ServeContent(c.Writer, c.Request, "", info.ModTime, &storedObjectReader{reader: file, size: info.Size})
+		return
+	}
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, file)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"illust-nest/internal/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeStoredObjectRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	content := []byte("0123456789abcdefghij")
+	info := service.ObjectInfo{Size: int64(len(content)), ModTime: time.Unix(1700000000, 0)}
+
+	tests := []struct {
+		name string
+		open func() io.Reader
+	}{
+		{name: "seekable", open: func() io.Reader { return bytes.NewReader(content) }},
+		{name: "sequential", open: func() io.Reader { return io.MultiReader(bytes.NewReader(content)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/uploads/originals/clip.mp4", nil)
+			c.Request.Header.Set("Range", "bytes=5-9")
+
+			ServeStoredObject(c, tt.open(), info, "uploads/originals/clip.mp4", "max-age=60")
+
+			if recorder.Code != http.StatusPartialContent {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusPartialContent)
+			}
+			if got := recorder.Body.String(); got != "56789" {
+				t.Fatalf("body = %q, want %q", got, "56789")
+			}
+			if got := recorder.Header().Get("Content-Range"); got != "bytes 5-9/20" {
+				t.Fatalf("Content-Range = %q", got)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "video/mp4" {
+				t.Fatalf("Content-Type = %q, want video/mp4", got)
+			}
+			if got := recorder.Header().Get("Accept-Ranges"); got != "bytes" {
+				t.Fatalf("Accept-Ranges = %q, want bytes", got)
+			}
+		})
+	}
+}
+
+func TestServeStoredObjectUnknownType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/uploads/originals/page", nil)
+
+	body := []byte("<html><script>alert(1)</script></html>")
+	ServeStoredObject(c, io.MultiReader(bytes.NewReader(body)), service.ObjectInfo{Size: int64(len(body))}, "uploads/originals/page", "max-age=60")
+
+	if recorder.Code != http.StatusOK || recorder.Body.String() != string(body) {
+		t.Fatalf("response = %d %q", recorder.Code, recorder.Body.String())
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/octet-stream" {
+		t.Fatalf("Content-Type = %q, want application/octet-stream", got)
+	}
+	if got := recorder.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Fatalf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+}
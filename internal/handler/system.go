@@ -112,13 +112,22 @@ func (h *SystemHandler) TestImageMagick(c *gin.Context) {
 	_ = userID
 
 	version := c.Query("version")
-	result, err := h.systemService.TestImageMagickCommand(version)
+	result, err := h.systemService.TestImageMagickCommand(c.Request.Context(), version)
 	if err != nil {
 		InternalErrorWithMessage(c, err.Error())
 		return
 	}
 	if !result.Available {
-		BadRequest(c, result.Message)
+		BadRequestWithData(c, result.Message, result)
+		return
+	}
+	Success(c, result)
+}
+
+func (h *SystemHandler) TestFFmpeg(c *gin.Context) {
+	result := h.systemService.TestFFmpegCommand(c.Request.Context())
+	if !result.Available {
+		BadRequestWithData(c, result.Message, result)
 		return
 	}
 	Success(c, result)
@@ -20,7 +20,7 @@ func (h *TagHandler) List(c *gin.Context) {
 	keyword := c.Query("keyword")
 	includeCount := c.DefaultQuery("include_count", "false") == "true"
 
-	tags, err := h.tagService.GetTags(keyword, includeCount)
+	tags, err := h.tagService.GetTags(keyword, c.Query("category"), includeCount)
 	if err != nil {
 		InternalError(c)
 		return
@@ -117,3 +117,232 @@ func (h *TagHandler) BatchCreate(c *gin.Context) {
 
 	Success(c, resp)
 }
+
+func (h *TagHandler) UpdateParent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid tag id")
+		return
+	}
+
+	var req service.UpdateTagParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	tag, err := h.tagService.UpdateParent(uint(id), &req)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, tag)
+}
+
+func (h *TagHandler) AddAlias(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid tag id")
+		return
+	}
+
+	var req service.CreateTagAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	tag, err := h.tagService.AddAlias(uint(id), &req)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, tag)
+}
+
+func (h *TagHandler) DeleteAlias(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid tag id")
+		return
+	}
+	aliasID, err := strconv.ParseUint(c.Param("aliasId"), 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid alias id")
+		return
+	}
+
+	if err := h.tagService.DeleteAlias(uint(id), uint(aliasID)); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+func (h *TagHandler) AddImplication(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid tag id")
+		return
+	}
+
+	var req service.CreateTagImplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	tag, err := h.tagService.AddImplication(uint(id), &req)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, tag)
+}
+
+func (h *TagHandler) DeleteImplication(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid tag id")
+		return
+	}
+	impliedID, err := strconv.ParseUint(c.Param("impliedId"), 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid implied tag id")
+		return
+	}
+
+	if err := h.tagService.DeleteImplication(uint(id), uint(impliedID)); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, nil)
+}
+
+func (h *TagHandler) Merge(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid tag id")
+		return
+	}
+
+	var req service.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	tag, err := h.tagService.MergeTag(currentActor(c), uint(id), &req)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, tag)
+}
+
+func (h *TagHandler) ListCategories(c *gin.Context) {
+	categories, err := h.tagService.GetCategories()
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, gin.H{"items": categories})
+}
+
+func (h *TagHandler) UpdateCategory(c *gin.Context) {
+	var req service.UpdateTagCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	category, err := h.tagService.UpdateCategory(c.Param("name"), &req)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	Success(c, category)
+}
+
+func (h *TagHandler) Related(c *gin.Context) {
+	var tagIDs []uint
+	for _, part := range strings.Split(c.Query("tag_ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			BadRequest(c, "invalid tag_ids")
+			return
+		}
+		tagIDs = append(tagIDs, uint(id))
+	}
+	if len(tagIDs) == 0 {
+		BadRequest(c, "tag_ids is required")
+		return
+	}
+
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 && val <= 100 {
+			limit = val
+		}
+	}
+
+	minCount := 1
+	if v := c.Query("min_count"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			minCount = val
+		}
+	}
+
+	result, err := h.tagService.GetRelatedTags(tagIDs, c.Query("method"), minCount, limit)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	limit := 10
+	if v := c.Query("limit"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 && val <= 50 {
+			limit = val
+		}
+	}
+
+	suggestions, err := h.tagService.Autocomplete(c.Query("q"), limit)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, gin.H{"items": suggestions})
+}
+
+func respondTagError(c *gin.Context, err error) {
+	if ve, ok := err.(*service.ValidationError); ok {
+		Error(c, ve.Code, ve.Message)
+	} else if strings.Contains(err.Error(), "not found") {
+		NotFound(c)
+	} else if strings.Contains(err.Error(), "cannot merge") {
+		Forbidden(c)
+	} else {
+		InternalError(c)
+	}
+}
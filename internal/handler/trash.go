@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"illust-nest/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+func (h *TrashHandler) ListWorks(c *gin.Context) {
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if val, err := strconv.Atoi(ps); err == nil && val > 0 && val <= 100 {
+			pageSize = val
+		}
+	}
+
+	result, err := h.trashService.ListWorks(page, pageSize)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *TrashHandler) RestoreWorks(c *gin.Context) {
+	var req service.TrashIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	count, err := h.trashService.RestoreWorks(req.IDs)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, &service.RestoreTrashResponse{RestoredCount: count})
+}
+
+func (h *TrashHandler) ListImages(c *gin.Context) {
+	images, err := h.trashService.ListImages()
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, gin.H{"items": images})
+}
+
+func (h *TrashHandler) RestoreImages(c *gin.Context) {
+	var req service.TrashIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	count, err := h.trashService.RestoreImages(req.IDs)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, &service.RestoreTrashResponse{RestoredCount: count})
+}
+
+func (h *TrashHandler) Empty(c *gin.Context) {
+	var req service.EmptyTrashRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	result, err := h.trashService.Empty(req.IDs, req.ImageIDs)
+	if err != nil {
+		InternalErrorWithMessage(c, err.Error())
+		return
+	}
+
+	Success(c, result)
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"illust-nest/internal/service"
 	"io"
+	"mime/multipart"
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -16,6 +18,8 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
+const uploadFormMaxMemory = 32 << 20
+
 type WorkHandler struct {
 	workService  *service.WorkService
 	imageService *service.ImageService
@@ -83,6 +87,7 @@ func (h *WorkHandler) List(c *gin.Context) {
 		SortBy:    c.DefaultQuery("sort_by", "created_at"),
 		SortOrder: c.DefaultQuery("sort_order", "desc"),
 	}
+	parseWorkAttributeQuery(c, params)
 
 	result, err := h.workService.GetWorks(params)
 	if err != nil {
@@ -93,6 +98,267 @@ func (h *WorkHandler) List(c *gin.Context) {
 	Success(c, result)
 }
 
+func (h *WorkHandler) Random(c *gin.Context) {
+	params := parseWorkFilterQuery(c, h.workService)
+	count, seed := parseRandomQuery(c)
+
+	result, err := h.workService.GetRandomWorks(params, count, seed)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *WorkHandler) OnThisDay(c *gin.Context) {
+	params := parseWorkFilterQuery(c, h.workService)
+	date, ok := parseOnThisDayDate(c)
+	if !ok {
+		BadRequest(c, "invalid date")
+		return
+	}
+
+	result, err := h.workService.GetOnThisDayWorks(params, date, parseOnThisDayLimit(c))
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *WorkHandler) Timeline(c *gin.Context) {
+	params := parseWorkFilterQuery(c, h.workService)
+	result, err := h.workService.GetTimeline(params)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *WorkHandler) Geo(c *gin.Context) {
+	params := parseWorkFilterQuery(c, h.workService)
+	bounds, ok := parseGeoBoundsQuery(c)
+	if !ok {
+		BadRequest(c, service.ErrInvalidGeoBounds.Error())
+		return
+	}
+
+	result, err := h.workService.GetGeoPoints(params, bounds, parsePositiveIntQuery(c.Query("limit")))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidGeoBounds) {
+			BadRequest(c, err.Error())
+			return
+		}
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func parseWorkFilterQuery(c *gin.Context, workService *service.WorkService) *service.WorkListParams {
+	params := &service.WorkListParams{
+		Keyword:   c.Query("keyword"),
+		RatingMin: -1,
+		RatingMax: -1,
+	}
+	if p := c.Query("is_public"); p != "" {
+		if val, err := strconv.ParseBool(p); err == nil {
+			params.IsPublic = &val
+		}
+	}
+	if r := c.Query("rating_min"); r != "" {
+		if val, err := strconv.Atoi(r); err == nil {
+			params.RatingMin = val
+		}
+	}
+	if r := c.Query("rating_max"); r != "" {
+		if val, err := strconv.Atoi(r); err == nil {
+			params.RatingMax = val
+		}
+	}
+	if t := c.Query("tag_ids"); t != "" {
+		if ids, err := workService.ParseTagIDs(t); err == nil {
+			params.TagIDs = ids
+		}
+	}
+	parseWorkAttributeQuery(c, params)
+	return params
+}
+
+func parseRandomQuery(c *gin.Context) (int, int64) {
+	count := 1
+	if v := c.Query("count"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			count = val
+		}
+	}
+	if count > 50 {
+		count = 50
+	}
+
+	seed := time.Now().UnixNano()
+	if v := strings.TrimSpace(c.Query("seed")); v != "" {
+		if val, err := strconv.ParseInt(v, 10, 64); err == nil {
+			seed = val
+		}
+	}
+	return count, seed
+}
+
+func parseOnThisDayLimit(c *gin.Context) int {
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			limit = val
+		}
+	}
+	if limit > 50 {
+		limit = 50
+	}
+	return limit
+}
+
+func parseOnThisDayDate(c *gin.Context) (time.Time, bool) {
+	value := strings.TrimSpace(c.Query("date"))
+	if value == "" {
+		return time.Now(), true
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func parseWorkAttributeQuery(c *gin.Context, params *service.WorkListParams) {
+	if t, ok := parseDateQuery(c.Query("created_from"), false); ok {
+		params.CreatedFrom = &t
+	}
+	if t, ok := parseDateQuery(c.Query("created_to"), true); ok {
+		params.CreatedTo = &t
+	}
+	params.WidthMin = parsePositiveIntQuery(c.Query("width_min"))
+	params.WidthMax = parsePositiveIntQuery(c.Query("width_max"))
+	params.HeightMin = parsePositiveIntQuery(c.Query("height_min"))
+	params.HeightMax = parsePositiveIntQuery(c.Query("height_max"))
+	params.AspectRatio = c.Query("aspect_ratio")
+	if f := strings.TrimSpace(c.Query("formats")); f != "" {
+		params.Formats = strings.Split(f, ",")
+	}
+	params.ImageCountMin = parsePositiveIntQuery(c.Query("image_count_min"))
+	params.ImageCountMax = parsePositiveIntQuery(c.Query("image_count_max"))
+	if v := c.Query("has_ai_metadata"); v != "" {
+		if val, err := strconv.ParseBool(v); err == nil {
+			params.HasAIMetadata = &val
+		}
+	}
+	params.AICheckpointID = parseUintQuery(c.Query("ai_checkpoint_id"))
+	params.AISamplerID = parseUintQuery(c.Query("ai_sampler_id"))
+	params.AILoraID = parseUintQuery(c.Query("ai_lora_id"))
+	params.AILoraWeightMin = parseFloatQuery(c.Query("ai_lora_weight_min"))
+	params.AILoraWeightMax = parseFloatQuery(c.Query("ai_lora_weight_max"))
+	params.AISeed = c.Query("ai_seed")
+	if v := c.Query("untagged"); v != "" {
+		params.Untagged, _ = strconv.ParseBool(v)
+	}
+	if v := c.Query("not_in_collection"); v != "" {
+		params.NotInCollection, _ = strconv.ParseBool(v)
+	}
+	params.EXIFCamera = c.Query("exif_camera")
+	params.EXIFLens = c.Query("exif_lens")
+	params.ISOMin = parsePositiveIntQuery(c.Query("iso_min"))
+	params.ISOMax = parsePositiveIntQuery(c.Query("iso_max"))
+	params.FocalLengthMin = parseFloatQuery(c.Query("focal_length_min"))
+	params.FocalLengthMax = parseFloatQuery(c.Query("focal_length_max"))
+	if t, ok := parseDateQuery(c.Query("taken_from"), false); ok {
+		params.TakenFrom = &t
+	}
+	if t, ok := parseDateQuery(c.Query("taken_to"), true); ok {
+		params.TakenTo = &t
+	}
+	if v := c.Query("has_gps"); v != "" {
+		if val, err := strconv.ParseBool(v); err == nil {
+			params.HasGPS = &val
+		}
+	}
+	params.Color = strings.TrimSpace(c.Query("color"))
+	params.ColorTolerance = parseFloatQuery(c.Query("color_tolerance"))
+	if v := c.Query("animated"); v != "" {
+		if val, err := strconv.ParseBool(v); err == nil {
+			params.Animated = &val
+		}
+	}
+	params.MediaType = strings.ToLower(strings.TrimSpace(c.Query("media_type")))
+	if bounds, ok := parseGeoBoundsQuery(c); ok && bounds != nil {
+		params.GPSBounds = bounds
+	}
+}
+
+func parseGeoBoundsQuery(c *gin.Context) (*service.GeoBounds, bool) {
+	minLat, maxLat := c.Query("min_lat"), c.Query("max_lat")
+	minLng, maxLng := c.Query("min_lng"), c.Query("max_lng")
+	if strings.TrimSpace(minLat+maxLat+minLng+maxLng) == "" {
+		return nil, true
+	}
+	values := []*float64{parseFloatQuery(minLat), parseFloatQuery(maxLat), parseFloatQuery(minLng), parseFloatQuery(maxLng)}
+	for _, value := range values {
+		if value == nil {
+			return nil, false
+		}
+	}
+	return &service.GeoBounds{MinLat: *values[0], MaxLat: *values[1], MinLng: *values[2], MaxLng: *values[3]}, true
+}
+
+func parseDateQuery(value string, endOfDay bool) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, true
+}
+
+func parsePositiveIntQuery(value string) int {
+	if value == "" {
+		return 0
+	}
+	val, err := strconv.Atoi(value)
+	if err != nil || val < 0 {
+		return 0
+	}
+	return val
+}
+
+func parseUintQuery(value string) uint {
+	val, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(val)
+}
+
+func parseFloatQuery(value string) *float64 {
+	val, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &val
+}
+
 func (h *WorkHandler) Get(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
@@ -111,9 +377,9 @@ func (h *WorkHandler) Get(c *gin.Context) {
 }
 
 func (h *WorkHandler) Create(c *gin.Context) {
-	form, err := c.MultipartForm()
-	if err != nil {
-		BadRequest(c, err.Error())
+	limits := h.imageService.GetUploadLimits()
+	form, ok := parseUploadForm(c, limits)
+	if !ok {
 		return
 	}
 
@@ -159,15 +425,22 @@ func (h *WorkHandler) Create(c *gin.Context) {
 		return
 	}
 
-	for _, file := range files {
-		if file.Size > service.MaxUploadFileSizeBytes {
-			BadRequest(c, "文件大小不能超过50MB")
+	uploadedImages, err := h.imageService.UploadImages(c.Request.Context(), files, limits)
+	if err != nil {
+		var limitErr *service.UploadLimitError
+		if errors.As(err, &limitErr) {
+			UploadLimitExceeded(c, limitErr)
+			return
+		}
+		if errors.Is(err, service.ErrImageMalformed) || errors.Is(err, service.ErrVideoMalformed) || errors.Is(err, service.ErrInvalidUgoiraArchive) {
+			BadRequest(c, err.Error())
+			return
+		}
+		var convErr *service.ConverterError
+		if errors.As(err, &convErr) {
+			ConverterFailed(c, err.Error(), convErr)
 			return
 		}
-	}
-
-	uploadedImages, err := h.imageService.UploadImages(files)
-	if err != nil {
 		InternalErrorWithMessage(c, err.Error())
 		return
 	}
@@ -182,12 +455,12 @@ func (h *WorkHandler) Create(c *gin.Context) {
 		if i < len(imageHashes) {
 			uploadedImages[i].ImageHash = imageHashes[i]
 		}
-		if i < len(aiMetadataList) {
+		if i < len(aiMetadataList) && aiMetadataList[i] != nil {
 			uploadedImages[i].AIMetadata = aiMetadataList[i]
 		}
 	}
 
-	work, err := h.workService.CreateWork(req, uploadedImages)
+	work, err := h.workService.CreateWork(currentActor(c), req, uploadedImages)
 	if err != nil {
 		InternalErrorWithMessage(c, err.Error())
 		return
@@ -210,7 +483,7 @@ func (h *WorkHandler) Update(c *gin.Context) {
 		return
 	}
 
-	work, err := h.workService.UpdateWork(uint(id), &req)
+	work, err := h.workService.UpdateWork(currentActor(c), uint(id), &req)
 	if err != nil {
 		if errors.Is(err, service.ErrWorkNotFound) {
 			NotFound(c)
@@ -223,6 +496,68 @@ func (h *WorkHandler) Update(c *gin.Context) {
 	Success(c, work)
 }
 
+func (h *WorkHandler) History(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid work id")
+		return
+	}
+
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if val, err := strconv.Atoi(p); err == nil && val > 0 {
+			page = val
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if val, err := strconv.Atoi(ps); err == nil && val > 0 && val <= 100 {
+			pageSize = val
+		}
+	}
+
+	result, err := h.workService.GetWorkHistory(uint(id), page, pageSize)
+	if err != nil {
+		InternalError(c)
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *WorkHandler) Revert(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid work id")
+		return
+	}
+
+	logIDParam := c.Param("logId")
+	logID, err := strconv.ParseUint(logIDParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid history id")
+		return
+	}
+
+	work, err := h.workService.RevertWork(currentActor(c), uint(id), uint(logID))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWorkNotFound), errors.Is(err, service.ErrAuditLogNotFound):
+			NotFound(c)
+		case errors.Is(err, service.ErrAuditLogNotRevertible):
+			BadRequest(c, err.Error())
+		default:
+			InternalErrorWithMessage(c, err.Error())
+		}
+		return
+	}
+
+	Success(c, work)
+}
+
 func (h *WorkHandler) Delete(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
@@ -231,7 +566,7 @@ func (h *WorkHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.workService.DeleteWork(uint(id)); err != nil {
+	if err := h.workService.DeleteWork(currentActor(c), uint(id)); err != nil {
 		if errors.Is(err, service.ErrWorkNotFound) {
 			NotFound(c)
 		} else {
@@ -262,9 +597,10 @@ func (h *WorkHandler) DownloadImages(c *gin.Context) {
 		return
 	}
 
+	embedMetadata, _ := strconv.ParseBool(c.Query("embed_metadata"))
 	imageIDParam := strings.TrimSpace(c.Query("image_id"))
 	if imageIDParam == "" {
-		h.downloadAllOriginalsAsZip(c, work)
+		h.downloadAllOriginalsAsZip(c, work, embedMetadata)
 		return
 	}
 
@@ -307,6 +643,17 @@ func (h *WorkHandler) DownloadImages(c *gin.Context) {
 		filename = "work-" + strconv.FormatUint(uint64(workID), 10)
 	}
 	filename = filename + "-P" + strconv.FormatUint(uint64(target.SortOrder+1), 10) + ext
+	if embedMetadata && service.CanEmbedFileMetadata(target.OriginalPath) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			InternalError(c)
+			return
+		}
+		data = service.EmbedFileMetadata(data, service.NewWorkEmbeddedFileMetadata(work, target))
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Data(200, "application/octet-stream", data)
+		return
+	}
 	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
 	c.Header("Content-Type", "application/octet-stream")
 	c.Status(200)
@@ -345,7 +692,7 @@ func (h *WorkHandler) GetImageEXIF(c *gin.Context) {
 	Success(c, info)
 }
 
-func (h *WorkHandler) downloadAllOriginalsAsZip(c *gin.Context, work *service.WorkInfo) {
+func (h *WorkHandler) downloadAllOriginalsAsZip(c *gin.Context, work *service.WorkInfo, embedMetadata bool) {
 	filename := sanitizeFilename(work.Title)
 	if filename == "" {
 		filename = "work-" + strconv.FormatUint(uint64(work.ID), 10)
@@ -385,6 +732,16 @@ func (h *WorkHandler) downloadAllOriginalsAsZip(c *gin.Context, work *service.Wo
 			continue
 		}
 
+		if embedMetadata && service.CanEmbedFileMetadata(image.OriginalPath) {
+			data, readErr := io.ReadAll(file)
+			file.Close()
+			if readErr != nil {
+				continue
+			}
+			_, _ = entryWriter.Write(service.EmbedFileMetadata(data, service.NewWorkEmbeddedFileMetadata(work, &image)))
+			continue
+		}
+
 		_, copyErr := io.Copy(entryWriter, file)
 		file.Close()
 		if copyErr != nil {
@@ -420,7 +777,7 @@ func (h *WorkHandler) BatchDelete(c *gin.Context) {
 		return
 	}
 
-	count, err := h.workService.BatchDeleteWorks(req.IDs)
+	count, err := h.workService.BatchDeleteWorks(currentActor(c), req.IDs)
 	if err != nil {
 		InternalError(c)
 		return
@@ -437,9 +794,9 @@ func (h *WorkHandler) AddImages(c *gin.Context) {
 		return
 	}
 
-	form, err := c.MultipartForm()
-	if err != nil {
-		BadRequest(c, err.Error())
+	limits := h.imageService.GetUploadLimits()
+	form, ok := parseUploadForm(c, limits)
+	if !ok {
 		return
 	}
 
@@ -448,15 +805,23 @@ func (h *WorkHandler) AddImages(c *gin.Context) {
 		BadRequest(c, "at least one image is required")
 		return
 	}
-	for _, file := range files {
-		if file.Size > service.MaxUploadFileSizeBytes {
-			BadRequest(c, "文件大小不能超过50MB")
-			return
-		}
-	}
 
-	uploadedImages, err := h.imageService.UploadImages(files)
+	uploadedImages, err := h.imageService.UploadImages(c.Request.Context(), files, limits)
 	if err != nil {
+		var limitErr *service.UploadLimitError
+		if errors.As(err, &limitErr) {
+			UploadLimitExceeded(c, limitErr)
+			return
+		}
+		if errors.Is(err, service.ErrImageMalformed) || errors.Is(err, service.ErrVideoMalformed) || errors.Is(err, service.ErrInvalidUgoiraArchive) {
+			BadRequest(c, err.Error())
+			return
+		}
+		var convErr *service.ConverterError
+		if errors.As(err, &convErr) {
+			ConverterFailed(c, err.Error(), convErr)
+			return
+		}
 		InternalErrorWithMessage(c, err.Error())
 		return
 	}
@@ -471,12 +836,12 @@ func (h *WorkHandler) AddImages(c *gin.Context) {
 		if i < len(imageHashes) {
 			uploadedImages[i].ImageHash = imageHashes[i]
 		}
-		if i < len(aiMetadataList) {
+		if i < len(aiMetadataList) && aiMetadataList[i] != nil {
 			uploadedImages[i].AIMetadata = aiMetadataList[i]
 		}
 	}
 
-	images, err := h.workService.AddImages(uint(id), uploadedImages)
+	images, err := h.workService.AddImages(currentActor(c), uint(id), uploadedImages)
 	if err != nil {
 		InternalErrorWithMessage(c, err.Error())
 		return
@@ -500,8 +865,10 @@ func (h *WorkHandler) DeleteImage(c *gin.Context) {
 		return
 	}
 
-	if err := h.workService.DeleteImage(uint(id), uint(imageID)); err != nil {
-		if errors.Is(err, service.ErrWorkNotFound) || errors.Is(err, service.ErrCannotDeleteLastImage) {
+	if err := h.workService.DeleteImage(currentActor(c), uint(id), uint(imageID)); err != nil {
+		if errors.Is(err, service.ErrImageNotFound) {
+			NotFound(c)
+		} else if errors.Is(err, service.ErrWorkNotFound) || errors.Is(err, service.ErrCannotDeleteLastImage) {
 			BadRequest(c, err.Error())
 		} else {
 			InternalError(c)
@@ -534,7 +901,7 @@ func (h *WorkHandler) UpdateImageOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.workService.UpdateImageOrder(uint(id), req.ImageIDs); err != nil {
+	if err := h.workService.UpdateImageOrder(currentActor(c), uint(id), req.ImageIDs); err != nil {
 		InternalError(c)
 		return
 	}
@@ -563,7 +930,7 @@ func (h *WorkHandler) UpdateImageAIMetadata(c *gin.Context) {
 		return
 	}
 
-	if err := h.workService.UpdateImageAIMetadata(uint(id), uint(imageID), req.AIMetadata); err != nil {
+	if err := h.workService.UpdateImageAIMetadata(currentActor(c), uint(id), uint(imageID), req.AIMetadata); err != nil {
 		if errors.Is(err, service.ErrImageNotFound) {
 			NotFound(c)
 			return
@@ -579,6 +946,96 @@ func (h *WorkHandler) UpdateImageAIMetadata(c *gin.Context) {
 	Success(c, nil)
 }
 
+func (h *WorkHandler) ExportImageAIMetadata(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid work id")
+		return
+	}
+
+	imageIDParam := c.Param("imageId")
+	imageID, err := strconv.ParseUint(imageIDParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid image id")
+		return
+	}
+
+	export, err := h.workService.ExportImageAIMetadata(uint(id), uint(imageID), c.Query("format"))
+	if err != nil {
+		if errors.Is(err, service.ErrImageNotFound) || errors.Is(err, service.ErrAIMetadataNotFound) {
+			NotFound(c)
+			return
+		}
+		if errors.Is(err, service.ErrAIMetadataFormatUnsupported) {
+			BadRequest(c, err.Error())
+			return
+		}
+		InternalErrorWithMessage(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+sanitizeFilename(export.Filename)+`"`)
+	c.Data(200, export.ContentType, export.Content)
+}
+
+func (h *WorkHandler) ImportImageAIMetadata(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid work id")
+		return
+	}
+
+	imageIDParam := c.Param("imageId")
+	imageID, err := strconv.ParseUint(imageIDParam, 10, 32)
+	if err != nil {
+		BadRequest(c, "invalid image id")
+		return
+	}
+
+	var req service.ImportAIMetadataRequest
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		req.Format = c.PostForm("format")
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			BadRequest(c, "file is required")
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			BadRequest(c, err.Error())
+			return
+		}
+		content, err := io.ReadAll(io.LimitReader(file, 1<<20+1))
+		file.Close()
+		if err != nil {
+			BadRequest(c, err.Error())
+			return
+		}
+		req.Content = string(content)
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	metadata, err := h.workService.ImportImageAIMetadata(currentActor(c), uint(id), uint(imageID), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrImageNotFound) {
+			NotFound(c)
+			return
+		}
+		if errors.Is(err, service.ErrAIMetadataFormatUnsupported) || errors.Is(err, service.ErrAIMetadataImportInvalid) || errors.Is(err, service.ErrAIMetadataRequiredFields) {
+			BadRequest(c, err.Error())
+			return
+		}
+		InternalErrorWithMessage(c, err.Error())
+		return
+	}
+
+	Success(c, metadata)
+}
+
 func (h *WorkHandler) CheckDuplicateImages(c *gin.Context) {
 	var req service.DuplicateImageCheckRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -597,6 +1054,32 @@ func (h *WorkHandler) CheckDuplicateImages(c *gin.Context) {
 	})
 }
 
+func (h *WorkHandler) RescanAIMetadata(c *gin.Context) {
+	var req service.RescanAIMetadataRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	status, err := h.workService.StartAIMetadataRescan(currentActor(c), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrAIMetadataRescanRunning) {
+			Conflict(c, err.Error())
+			return
+		}
+		InternalErrorWithMessage(c, err.Error())
+		return
+	}
+
+	Success(c, status)
+}
+
+func (h *WorkHandler) GetAIMetadataRescanStatus(c *gin.Context) {
+	Success(c, h.workService.GetAIMetadataRescanStatus())
+}
+
 type BatchUpdatePublicRequest struct {
 	IDs      []uint `json:"ids" binding:"required,min=1"`
 	IsPublic *bool  `json:"is_public" binding:"required"`
@@ -617,7 +1100,7 @@ func (h *WorkHandler) BatchUpdatePublic(c *gin.Context) {
 		return
 	}
 
-	count, err := h.workService.BatchUpdatePublicStatus(req.IDs, *req.IsPublic)
+	count, err := h.workService.BatchUpdatePublicStatus(currentActor(c), req.IDs, *req.IsPublic)
 	if err != nil {
 		InternalError(c)
 		return
@@ -627,6 +1110,7 @@ func (h *WorkHandler) BatchUpdatePublic(c *gin.Context) {
 }
 
 func (h *WorkHandler) ExportImages(c *gin.Context) {
+	embedMetadata, _ := strconv.ParseBool(c.Query("embed_metadata"))
 	records, err := h.workService.ListExportImages()
 	if err != nil {
 		InternalError(c)
@@ -668,7 +1152,13 @@ func (h *WorkHandler) ExportImages(c *gin.Context) {
 			continue
 		}
 
-		_, _ = io.Copy(entryWriter, file)
+		if embedMetadata && service.CanEmbedFileMetadata(cleanPath) {
+			if data, readErr := io.ReadAll(file); readErr == nil {
+				_, _ = entryWriter.Write(service.EmbedFileMetadata(data, record.EmbeddedMetadata()))
+			}
+		} else {
+			_, _ = io.Copy(entryWriter, file)
+		}
 		file.Close()
 		added[cleanPath] = struct{}{}
 	}
@@ -723,6 +1213,81 @@ func (h *WorkHandler) ExportImages(c *gin.Context) {
 	_, _ = indexEntry.Write(indexBuffer.Bytes())
 }
 
+func parseUploadForm(c *gin.Context, limits *service.UploadLimits) (*multipart.Form, bool) {
+	if limitErr := limits.RequestTooLarge(c.Request.ContentLength); limitErr != nil {
+		UploadLimitExceeded(c, limitErr)
+		return nil, false
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxRequestBytes)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		BadRequest(c, err.Error())
+		return nil, false
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		pipeWriter.CloseWithError(copyUploadParts(reader, writer, limits))
+	}()
+	form, err := multipart.NewReader(pipeReader, writer.Boundary()).ReadForm(uploadFormMaxMemory)
+	pipeReader.CloseWithError(io.ErrClosedPipe)
+	<-copied
+	if err != nil {
+		if form != nil {
+			_ = form.RemoveAll()
+		}
+		var limitErr *service.UploadLimitError
+		if errors.As(err, &limitErr) {
+			UploadLimitExceeded(c, limitErr)
+			return nil, false
+		}
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			UploadLimitExceeded(c, limits.RequestLimitError())
+			return nil, false
+		}
+		BadRequest(c, err.Error())
+		return nil, false
+	}
+	c.Request.MultipartForm = form
+	return form, true
+}
+
+func copyUploadParts(reader *multipart.Reader, writer *multipart.Writer, limits *service.UploadLimits) error {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return writer.Close()
+		}
+		if err != nil {
+			return err
+		}
+		dst, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		filename := part.FileName()
+		if filename == "" {
+			if _, err := io.Copy(dst, part); err != nil {
+				return err
+			}
+			continue
+		}
+		_, limit := limits.FileLimit(filename)
+		written, err := io.Copy(dst, io.LimitReader(part, limit+1))
+		if err != nil {
+			return err
+		}
+		if written > limit {
+			return limits.FileLimitError(filename, written)
+		}
+	}
+}
+
 func parseImageHashes(values []string) []string {
 	hashes := make([]string, 0, len(values))
 	for _, raw := range values {
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"illust-nest/internal/service"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type uploadTestFile struct {
+	name string
+	size int
+}
+
+func newUploadTestContext(t *testing.T, files []uploadTestFile) (*gin.Context, *httptest.ResponseRecorder, *countingReader) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("title", "upload"); err != nil {
+		t.Fatalf("write field: %v", err)
+	}
+	for _, file := range files {
+		part, err := writer.CreateFormFile("images", file.name)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		if _, err := part.Write(bytes.Repeat([]byte{'x'}, file.size)); err != nil {
+			t.Fatalf("write form file: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	counter := &countingReader{reader: &body}
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/works", counter)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Request.ContentLength = int64(body.Len())
+	return c, recorder, counter
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func testUploadLimits() *service.UploadLimits {
+	return &service.UploadLimits{
+		MaxRequestBytes: 64 << 20,
+		MaxFileBytes:    4 << 20,
+		FormatMaxBytes:  map[string]int64{"png": 1 << 10},
+	}
+}
+
+func TestParseUploadFormAcceptsFilesWithinLimits(t *testing.T) {
+	c, _, _ := newUploadTestContext(t, []uploadTestFile{{name: "a.png", size: 1 << 10}, {name: "b.jpg", size: 2 << 20}})
+	form, ok := parseUploadForm(c, testUploadLimits())
+	if !ok {
+		t.Fatalf("parseUploadForm() rejected files within limits")
+	}
+	if got := form.Value["title"]; len(got) != 1 || got[0] != "upload" {
+		t.Fatalf("title = %v, want upload", got)
+	}
+	files := form.File["images"]
+	if len(files) != 2 || files[0].Filename != "a.png" || files[0].Size != 1<<10 || files[1].Size != 2<<20 {
+		t.Fatalf("files = %+v, want a.png and b.jpg with their sizes", files)
+	}
+	if c.Request.MultipartForm != form {
+		t.Fatalf("request MultipartForm was not set for cleanup")
+	}
+}
+
+func TestParseUploadFormRejectsOversizedPartEarly(t *testing.T) {
+	c, recorder, counter := newUploadTestContext(t, []uploadTestFile{{name: "small.png", size: 4 << 10}, {name: "big.jpg", size: 32 << 20}})
+	if _, ok := parseUploadForm(c, testUploadLimits()); ok {
+		t.Fatalf("parseUploadForm() accepted an oversized PNG")
+	}
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+	var response struct {
+		Code int `json:"code"`
+		Data struct {
+			Filename string `json:"filename"`
+			Format   string `json:"format"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Code != service.ErrCodeUploadFileTooLarge || response.Data.Filename != "small.png" || response.Data.Format != "png" {
+		t.Fatalf("response = %+v, want file limit error for small.png", response)
+	}
+	if counter.read > 8<<20 {
+		t.Fatalf("read %d bytes of the request before rejecting it", counter.read)
+	}
+}
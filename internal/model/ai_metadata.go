@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+type AICheckpoint struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AILora struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AISampler struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkImageGeneration struct {
+	ImageID      uint    `gorm:"primaryKey" json:"image_id"`
+	WorkID       uint    `gorm:"not null;index" json:"work_id"`
+	CheckpointID *uint   `gorm:"index" json:"checkpoint_id,omitempty"`
+	SamplerID    *uint   `gorm:"index" json:"sampler_id,omitempty"`
+	Seed         string  `gorm:"type:varchar(32);not null;default:'';index" json:"seed"`
+	Steps        int     `gorm:"not null;default:0" json:"steps"`
+	CFGScale     float64 `gorm:"not null;default:0" json:"cfg_scale"`
+}
+
+type WorkImageLora struct {
+	ImageID uint     `gorm:"primaryKey" json:"image_id"`
+	LoraID  uint     `gorm:"primaryKey;index" json:"lora_id"`
+	Weight  *float64 `json:"weight,omitempty"`
+}
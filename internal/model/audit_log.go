@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;default:0" json:"user_id"`
+	Username   string    `gorm:"type:varchar(50);not null;default:''" json:"username"`
+	Action     string    `gorm:"type:varchar(50);not null" json:"action"`
+	EntityType string    `gorm:"type:varchar(30);not null;index:idx_audit_log_entity" json:"entity_type"`
+	EntityID   uint      `gorm:"not null;default:0;index:idx_audit_log_entity" json:"entity_id"`
+	WorkID     uint      `gorm:"not null;default:0;index" json:"work_id"`
+	Before     string    `gorm:"type:text;default:''" json:"before"`
+	After      string    `gorm:"type:text;default:''" json:"after"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	AutoTagMatchPromptToken = "prompt_token"
+	AutoTagMatchRegex       = "regex"
+	AutoTagMatchCheckpoint  = "checkpoint"
+	AutoTagMatchLora        = "lora"
+)
+
+type AutoTagRule struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null;default:''" json:"name"`
+	MatchType string    `gorm:"type:varchar(20);not null;index" json:"match_type"`
+	Pattern   string    `gorm:"type:varchar(500);not null" json:"pattern"`
+	TagID     uint      `gorm:"not null;index" json:"tag_id"`
+	Enabled   bool      `gorm:"default:true;not null" json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type WorkImageColor struct {
+	ImageID uint    `gorm:"primaryKey" json:"image_id"`
+	Rank    int     `gorm:"primaryKey" json:"rank"`
+	WorkID  uint    `gorm:"not null;index" json:"work_id"`
+	Hex     string  `gorm:"type:varchar(7);not null" json:"hex"`
+	Weight  float64 `gorm:"not null;default:0" json:"weight"`
+	LabL    float64 `gorm:"column:lab_l;not null;default:0" json:"lab_l"`
+	LabA    float64 `gorm:"column:lab_a;not null;default:0" json:"lab_a"`
+	LabB    float64 `gorm:"column:lab_b;not null;default:0" json:"lab_b"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type WorkImageEXIF struct {
+	ImageID      uint       `gorm:"primaryKey" json:"image_id"`
+	WorkID       uint       `gorm:"not null;index" json:"work_id"`
+	HasEXIF      bool       `gorm:"not null;default:false" json:"has_exif"`
+	Orientation  int        `gorm:"not null;default:0" json:"orientation"`
+	CameraMake   string     `gorm:"type:varchar(100);not null;default:'';index" json:"camera_make"`
+	CameraModel  string     `gorm:"type:varchar(100);not null;default:'';index" json:"camera_model"`
+	LensModel    string     `gorm:"type:varchar(200);not null;default:'';index" json:"lens_model"`
+	FocalLength  *float64   `gorm:"index" json:"focal_length,omitempty"`
+	FNumber      *float64   `json:"f_number,omitempty"`
+	ExposureTime *float64   `json:"exposure_time,omitempty"`
+	ISO          *int       `gorm:"column:iso;index" json:"iso,omitempty"`
+	TakenAt      *time.Time `gorm:"index" json:"taken_at,omitempty"`
+	GPSLatitude  *float64   `gorm:"column:gps_latitude" json:"gps_latitude,omitempty"`
+	GPSLongitude *float64   `gorm:"column:gps_longitude" json:"gps_longitude,omitempty"`
+	Fields       string     `gorm:"type:text;not null;default:''" json:"-"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
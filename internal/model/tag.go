@@ -2,10 +2,40 @@ package model
 
 import "time"
 
+const (
+	TagCategoryArtist    = "artist"
+	TagCategoryCharacter = "character"
+	TagCategorySeries    = "series"
+	TagCategoryMeta      = "meta"
+	TagCategoryGeneral   = "general"
+)
+
 type Tag struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Name            string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
+	IsSystem        bool      `gorm:"default:false;not null" json:"is_system"`
+	Category        string    `gorm:"type:varchar(20);not null;default:'general';index" json:"category"`
+	Transliteration string    `gorm:"type:varchar(200);not null;default:'';index" json:"transliteration,omitempty"`
+	ParentID        *uint     `gorm:"index" json:"parent_id,omitempty"`
+	WorkCount       int       `gorm:"-" json:"work_count,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type TagAlias struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Name      string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
-	IsSystem  bool      `gorm:"default:false;not null" json:"is_system"`
-	WorkCount int       `gorm:"-" json:"work_count,omitempty"`
+	TagID     uint      `gorm:"not null;index" json:"tag_id"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type TagImplication struct {
+	TagID        uint      `gorm:"primaryKey;not null" json:"tag_id"`
+	ImpliedTagID uint      `gorm:"primaryKey;not null;index" json:"implied_tag_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TagCategory struct {
+	Name         string `gorm:"primaryKey;type:varchar(20)" json:"name"`
+	Color        string `gorm:"type:varchar(20);not null;default:''" json:"color"`
+	SortPriority int    `gorm:"not null;default:0" json:"sort_priority"`
+}
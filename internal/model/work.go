@@ -1,35 +1,53 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MediaTypeImage  = "image"
+	MediaTypeVideo  = "video"
+	MediaTypeUgoira = "ugoira"
+)
 
 type Work struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Title       string    `gorm:"type:varchar(200);not null" json:"title"`
-	Description string    `gorm:"type:text" json:"description"`
-	Rating      int       `gorm:"default:0;not null" json:"rating"`
-	IsPublic    bool      `gorm:"default:false;not null" json:"is_public"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Title       string         `gorm:"type:varchar(200);not null" json:"title"`
+	Description string         `gorm:"type:text" json:"description"`
+	Rating      int            `gorm:"default:0;not null" json:"rating"`
+	IsPublic    bool           `gorm:"default:false;not null" json:"is_public"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
 	Images     []WorkImage `gorm:"foreignKey:WorkID" json:"images"`
-	Tags       []Tag       `gorm:"many2many:work_tags" json:"tags,omitempty"`
+	Tags       []Tag       `gorm:"many2many:work_tag" json:"tags,omitempty"`
 	CoverImage WorkImage   `gorm:"-" json:"cover_image,omitempty"`
 	ImageCount int         `gorm:"-" json:"image_count,omitempty"`
 }
 
 type WorkImage struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	WorkID         uint      `gorm:"not null;index" json:"work_id"`
-	StoragePath    string    `gorm:"type:varchar(255);not null" json:"storage_path"`
-	TranscodedPath string    `gorm:"type:varchar(255);default:''" json:"transcoded_path,omitempty"`
-	ThumbnailPath  string    `gorm:"type:varchar(255);not null" json:"thumbnail_path"`
-	ImageHash      string    `gorm:"type:varchar(64);not null;default:'';index:idx_work_images_image_hash" json:"image_hash,omitempty"`
-	AIMetadata     string    `gorm:"type:text;default:''" json:"ai_metadata,omitempty"`
-	FileSize       int64     `gorm:"not null" json:"file_size"`
-	Width          int       `gorm:"not null" json:"width"`
-	Height         int       `gorm:"not null" json:"height"`
-	SortOrder      int       `gorm:"default:0;not null" json:"sort_order"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID                    uint           `gorm:"primaryKey" json:"id"`
+	WorkID                uint           `gorm:"not null;index" json:"work_id"`
+	StoragePath           string         `gorm:"type:varchar(255);not null" json:"storage_path"`
+	TranscodedPath        string         `gorm:"type:varchar(255);default:''" json:"transcoded_path,omitempty"`
+	ThumbnailPath         string         `gorm:"type:varchar(255);not null" json:"thumbnail_path"`
+	ImageHash             string         `gorm:"type:varchar(64);not null;default:'';index:idx_work_images_image_hash" json:"image_hash,omitempty"`
+	AIMetadata            string         `gorm:"type:text;default:''" json:"ai_metadata,omitempty"`
+	Palette               string         `gorm:"type:text;default:''" json:"palette,omitempty"`
+	FileSize              int64          `gorm:"not null" json:"file_size"`
+	Width                 int            `gorm:"not null" json:"width"`
+	Height                int            `gorm:"not null" json:"height"`
+	FrameCount            int            `gorm:"default:0;not null;index" json:"frame_count"`
+	DurationMs            int            `gorm:"default:0;not null" json:"duration_ms"`
+	LoopCount             int            `gorm:"default:0;not null" json:"loop_count"`
+	AnimatedThumbnailPath string         `gorm:"type:varchar(255);default:''" json:"animated_thumbnail_path,omitempty"`
+	MediaType             string         `gorm:"type:varchar(20);not null;default:'image';index" json:"media_type"`
+	SortOrder             int            `gorm:"default:0;not null" json:"sort_order"`
+	CreatedAt             time.Time      `json:"created_at"`
+	DeletedAt             gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 type WorkTag struct {
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AIMetadataKindCheckpoint = "checkpoints"
+	AIMetadataKindLora       = "loras"
+	AIMetadataKindSampler    = "samplers"
+	AIMetadataKindSeed       = "seeds"
+)
+
+type ImageGenerationFields struct {
+	Checkpoint string
+	Sampler    string
+	Seed       string
+	Steps      int
+	CFGScale   float64
+	Loras      []ImageLoraFields
+}
+
+type ImageLoraFields struct {
+	Name   string
+	Weight *float64
+}
+
+type AIMetadataUsageRow struct {
+	ID         uint     `gorm:"column:id"`
+	Name       string   `gorm:"column:name"`
+	ImageCount int64    `gorm:"column:image_count"`
+	WorkCount  int64    `gorm:"column:work_count"`
+	MinWeight  *float64 `gorm:"column:min_weight"`
+	MaxWeight  *float64 `gorm:"column:max_weight"`
+}
+
+type AIMetadataRepository struct {
+	DB *gorm.DB
+}
+
+func NewAIMetadataRepository(db *gorm.DB) *AIMetadataRepository {
+	return &AIMetadataRepository{DB: db}
+}
+
+func (r *AIMetadataRepository) SyncImage(imageID, workID uint, fields *ImageGenerationFields) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("image_id = ?", imageID).Delete(&model.WorkImageLora{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("image_id = ?", imageID).Delete(&model.WorkImageGeneration{}).Error; err != nil {
+			return err
+		}
+		if fields == nil {
+			return nil
+		}
+
+		generation := &model.WorkImageGeneration{
+			ImageID:  imageID,
+			WorkID:   workID,
+			Seed:     fields.Seed,
+			Steps:    fields.Steps,
+			CFGScale: fields.CFGScale,
+		}
+		if fields.Checkpoint != "" {
+			checkpoint := model.AICheckpoint{Name: fields.Checkpoint}
+			if err := tx.Where("name = ?", checkpoint.Name).FirstOrCreate(&checkpoint).Error; err != nil {
+				return err
+			}
+			generation.CheckpointID = &checkpoint.ID
+		}
+		if fields.Sampler != "" {
+			sampler := model.AISampler{Name: fields.Sampler}
+			if err := tx.Where("name = ?", sampler.Name).FirstOrCreate(&sampler).Error; err != nil {
+				return err
+			}
+			generation.SamplerID = &sampler.ID
+		}
+		if err := tx.Create(generation).Error; err != nil {
+			return err
+		}
+
+		seen := make(map[uint]struct{}, len(fields.Loras))
+		for _, item := range fields.Loras {
+			lora := model.AILora{Name: item.Name}
+			if err := tx.Where("name = ?", lora.Name).FirstOrCreate(&lora).Error; err != nil {
+				return err
+			}
+			if _, ok := seen[lora.ID]; ok {
+				continue
+			}
+			seen[lora.ID] = struct{}{}
+			if err := tx.Create(&model.WorkImageLora{ImageID: imageID, LoraID: lora.ID, Weight: item.Weight}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *AIMetadataRepository) FindImagesWithoutGeneration() ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Where("ai_metadata <> '' AND NOT EXISTS (SELECT 1 FROM work_image_generation g WHERE g.image_id = work_image.id)").
+		Order("id ASC").
+		Find(&images).Error
+	return images, err
+}
+
+func (r *AIMetadataRepository) ListUsage(kind, keyword string, limit int) ([]AIMetadataUsageRow, error) {
+	query, args, ok := aiMetadataUsageQuery(kind, keyword)
+	if !ok {
+		return nil, nil
+	}
+
+	var rows []AIMetadataUsageRow
+	err := r.DB.Raw(query+" ORDER BY image_count DESC, name ASC LIMIT ?", append(args, limit)...).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *AIMetadataRepository) CountUsage(kind, keyword string) (int64, error) {
+	query, args, ok := aiMetadataUsageQuery(kind, keyword)
+	if !ok {
+		return 0, nil
+	}
+
+	var count int64
+	err := r.DB.Raw("SELECT COUNT(*) FROM ("+query+") usage", args...).Scan(&count).Error
+	return count, err
+}
+
+func aiMetadataUsageQuery(kind, keyword string) (string, []interface{}, bool) {
+	const imageJoin = `
+		JOIN work_image wi ON wi.id = g.image_id AND wi.deleted_at IS NULL
+		JOIN work ON work.id = wi.work_id AND work.deleted_at IS NULL`
+	const counts = `COUNT(DISTINCT wi.id) AS image_count, COUNT(DISTINCT wi.work_id) AS work_count`
+
+	var query string
+	nameColumn := "d.name"
+	switch kind {
+	case AIMetadataKindCheckpoint:
+		query = `SELECT d.id AS id, d.name AS name, ` + counts + `
+			FROM ai_checkpoint d
+			JOIN work_image_generation g ON g.checkpoint_id = d.id` + imageJoin
+	case AIMetadataKindSampler:
+		query = `SELECT d.id AS id, d.name AS name, ` + counts + `
+			FROM ai_sampler d
+			JOIN work_image_generation g ON g.sampler_id = d.id` + imageJoin
+	case AIMetadataKindLora:
+		query = `SELECT d.id AS id, d.name AS name, ` + counts + `, MIN(l.weight) AS min_weight, MAX(l.weight) AS max_weight
+			FROM ai_lora d
+			JOIN work_image_lora l ON l.lora_id = d.id
+			JOIN work_image_generation g ON g.image_id = l.image_id` + imageJoin
+	case AIMetadataKindSeed:
+		nameColumn = "g.seed"
+		query = `SELECT 0 AS id, g.seed AS name, ` + counts + `
+			FROM work_image_generation g` + imageJoin
+	default:
+		return "", nil, false
+	}
+
+	var args []interface{}
+	query += " WHERE " + nameColumn + " <> ''"
+	if keyword != "" {
+		query += " AND " + nameColumn + ` LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(keyword)+"%")
+	}
+	query += " GROUP BY " + nameColumn
+	if kind != AIMetadataKindSeed {
+		query += ", d.id"
+	}
+	return query, args, true
+}
+
+func deleteImageGenerations(tx *gorm.DB, imageIDs interface{}) error {
+	if err := tx.Where("image_id IN (?)", imageIDs).Delete(&model.WorkImageLora{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("image_id IN (?)", imageIDs).Delete(&model.WorkImageGeneration{}).Error
+}
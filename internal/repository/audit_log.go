@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{DB: db}
+}
+
+func (r *AuditLogRepository) Create(entry *model.AuditLog) error {
+	return r.DB.Create(entry).Error
+}
+
+func (r *AuditLogRepository) FindByID(id uint) (*model.AuditLog, error) {
+	var entry model.AuditLog
+	if err := r.DB.First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *AuditLogRepository) FindByWorkID(workID uint, page, pageSize int) ([]model.AuditLog, int64, error) {
+	var entries []model.AuditLog
+	var total int64
+
+	query := r.DB.Model(&model.AuditLog{}).Where("work_id = ?", workID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AutoTagRuleRepository struct {
+	DB *gorm.DB
+}
+
+func NewAutoTagRuleRepository(db *gorm.DB) *AutoTagRuleRepository {
+	return &AutoTagRuleRepository{DB: db}
+}
+
+func (r *AutoTagRuleRepository) Create(rule *model.AutoTagRule) error {
+	return r.DB.Create(rule).Error
+}
+
+func (r *AutoTagRuleRepository) FindByID(id uint) (*model.AutoTagRule, error) {
+	var rule model.AutoTagRule
+	if err := r.DB.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *AutoTagRuleRepository) FindAll() ([]model.AutoTagRule, error) {
+	var rules []model.AutoTagRule
+	err := r.DB.Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *AutoTagRuleRepository) FindEnabled() ([]model.AutoTagRule, error) {
+	var rules []model.AutoTagRule
+	err := r.DB.Where("enabled = ?", true).Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *AutoTagRuleRepository) Update(rule *model.AutoTagRule) error {
+	return r.DB.Save(rule).Error
+}
+
+func (r *AutoTagRuleRepository) Delete(id uint) (int64, error) {
+	result := r.DB.Delete(&model.AutoTagRule{}, id)
+	return result.RowsAffected, result.Error
+}
@@ -27,13 +27,20 @@ func (r *CollectionRepository) FindByID(id uint) (*model.Collection, error) {
 		return nil, err
 	}
 
-	var count int64
-	r.DB.Model(&model.CollectionWork{}).Where("collection_id = ?", id).Count(&count)
-	collection.WorkCount = int(count)
+	collection.WorkCount = int(r.countWorks(id))
 
 	return &collection, nil
 }
 
+func (r *CollectionRepository) countWorks(collectionID uint) int64 {
+	var count int64
+	r.DB.Model(&model.CollectionWork{}).
+		Joins("JOIN work ON work.id = collection_work.work_id AND work.deleted_at IS NULL").
+		Where("collection_work.collection_id = ?", collectionID).
+		Count(&count)
+	return count
+}
+
 func (r *CollectionRepository) FindAll() ([]model.Collection, error) {
 	var collections []model.Collection
 	err := r.DB.Order("sort_order ASC").Find(&collections).Error
@@ -52,9 +59,7 @@ func (r *CollectionRepository) FindTree(parentID *uint) ([]model.Collection, err
 	}
 
 	for i := range collections {
-		var count int64
-		r.DB.Model(&model.CollectionWork{}).Where("collection_id = ?", collections[i].ID).Count(&count)
-		collections[i].WorkCount = int(count)
+		collections[i].WorkCount = int(r.countWorks(collections[i].ID))
 	}
 
 	return collections, nil
@@ -213,10 +218,26 @@ func (r *CollectionRepository) FindByWorkID(workID uint) ([]model.Collection, er
 	}
 
 	for i := range collections {
-		var count int64
-		r.DB.Model(&model.CollectionWork{}).Where("collection_id = ?", collections[i].ID).Count(&count)
-		collections[i].WorkCount = int(count)
+		collections[i].WorkCount = int(r.countWorks(collections[i].ID))
 	}
 
 	return collections, nil
 }
+
+func (r *CollectionRepository) FindCollectionIDsByWorkID(workID uint) ([]uint, error) {
+	var collectionIDs []uint
+	err := r.DB.Model(&model.CollectionWork{}).
+		Where("work_id = ?", workID).
+		Order("collection_id ASC").
+		Pluck("collection_id", &collectionIDs).Error
+	return collectionIDs, err
+}
+
+func (r *CollectionRepository) FindWorkIDs(collectionID uint) ([]uint, error) {
+	var workIDs []uint
+	err := r.DB.Model(&model.CollectionWork{}).
+		Where("collection_id = ?", collectionID).
+		Order("sort_order ASC, work_id ASC").
+		Pluck("work_id", &workIDs).Error
+	return workIDs, err
+}
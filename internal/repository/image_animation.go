@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ImageAnimationRepository struct {
+	DB *gorm.DB
+}
+
+func NewImageAnimationRepository(db *gorm.DB) *ImageAnimationRepository {
+	return &ImageAnimationRepository{DB: db}
+}
+
+func (r *ImageAnimationRepository) Save(imageID uint, frameCount, durationMs, loopCount int) error {
+	return r.DB.Model(&model.WorkImage{}).Where("id = ?", imageID).Updates(map[string]interface{}{
+		"frame_count": frameCount,
+		"duration_ms": durationMs,
+		"loop_count":  loopCount,
+	}).Error
+}
+
+func (r *ImageAnimationRepository) FindImagesWithoutFrameCount() ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Where("(frame_count = 0 OR frame_count IS NULL) AND media_type = ?", model.MediaTypeImage).
+		Order("id ASC").
+		Find(&images).Error
+	return images, err
+}
+
+func mediaTypeCondition(mediaType string) (string, []interface{}) {
+	return "EXISTS (SELECT 1 FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND wi.media_type = ?)", []interface{}{mediaType}
+}
+
+func animatedCondition(animated bool) string {
+	condition := "EXISTS (SELECT 1 FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND wi.frame_count > 1)"
+	if animated {
+		return condition
+	}
+	return "NOT " + condition
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ImageEXIFRepository struct {
+	DB *gorm.DB
+}
+
+func NewImageEXIFRepository(db *gorm.DB) *ImageEXIFRepository {
+	return &ImageEXIFRepository{DB: db}
+}
+
+func (r *ImageEXIFRepository) Save(record *model.WorkImageEXIF) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("image_id = ?", record.ImageID).Delete(&model.WorkImageEXIF{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(record).Error
+	})
+}
+
+func (r *ImageEXIFRepository) FindByImageID(imageID uint) (*model.WorkImageEXIF, error) {
+	var record model.WorkImageEXIF
+	if err := r.DB.Where("image_id = ?", imageID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *ImageEXIFRepository) FindImagesWithoutEXIF() ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Where("NOT EXISTS (SELECT 1 FROM work_image_exif e WHERE e.image_id = work_image.id)").
+		Order("id ASC").
+		Find(&images).Error
+	return images, err
+}
+
+func deleteImageEXIF(tx *gorm.DB, imageIDs interface{}) error {
+	return tx.Where("image_id IN (?)", imageIDs).Delete(&model.WorkImageEXIF{}).Error
+}
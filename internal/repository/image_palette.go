@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ColorMatch struct {
+	L         float64
+	A         float64
+	B         float64
+	Tolerance float64
+	MinWeight float64
+}
+
+type ImagePaletteRepository struct {
+	DB *gorm.DB
+}
+
+func NewImagePaletteRepository(db *gorm.DB) *ImagePaletteRepository {
+	return &ImagePaletteRepository{DB: db}
+}
+
+func (r *ImagePaletteRepository) Save(imageID uint, palette string, colors []model.WorkImageColor) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.WorkImage{}).Where("id = ?", imageID).Update("palette", palette).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("image_id = ?", imageID).Delete(&model.WorkImageColor{}).Error; err != nil {
+			return err
+		}
+		if len(colors) == 0 {
+			return nil
+		}
+		return tx.Create(&colors).Error
+	})
+}
+
+func (r *ImagePaletteRepository) FindImagesWithoutPalette() ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Where("palette = '' OR palette IS NULL").
+		Order("id ASC").
+		Find(&images).Error
+	return images, err
+}
+
+func colorMatchCondition(match ColorMatch) (string, []interface{}) {
+	condition := "EXISTS (SELECT 1 FROM work_image wi JOIN work_image_color c ON c.image_id = wi.id " +
+		"WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND c.weight >= ? AND " +
+		"(c.lab_l - ?) * (c.lab_l - ?) + (c.lab_a - ?) * (c.lab_a - ?) + (c.lab_b - ?) * (c.lab_b - ?) <= ?)"
+	return condition, []interface{}{
+		match.MinWeight,
+		match.L, match.L, match.A, match.A, match.B, match.B,
+		match.Tolerance * match.Tolerance,
+	}
+}
+
+func deleteImageColors(tx *gorm.DB, imageIDs interface{}) error {
+	return tx.Where("image_id IN (?)", imageIDs).Delete(&model.WorkImageColor{}).Error
+}
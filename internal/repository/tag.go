@@ -3,11 +3,68 @@ package repository
 import (
 	"errors"
 	"illust-nest/internal/model"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
-var ErrTagNotFound = errors.New("tag not found")
+var (
+	ErrTagNotFound    = errors.New("tag not found")
+	ErrTagParentCycle = errors.New("tag parent cycle")
+)
+
+const tagDescendantsSQL = `WITH RECURSIVE tag_tree(id) AS (
+	SELECT id FROM tag WHERE id IN ?
+	UNION
+	SELECT tag.id FROM tag JOIN tag_tree ON tag.parent_id = tag_tree.id
+) SELECT id FROM tag_tree`
+
+const tagImplicationsSQL = `WITH RECURSIVE implied(id) AS (
+	SELECT id FROM tag WHERE id IN ?
+	UNION
+	SELECT ti.implied_tag_id FROM tag_implication ti JOIN implied ON ti.tag_id = implied.id
+) SELECT id FROM implied`
+
+const tagUsageSQL = `SELECT wt.tag_id AS tag_id, COUNT(*) AS work_count
+	FROM work_tag wt JOIN work ON work.id = wt.work_id AND work.deleted_at IS NULL
+	GROUP BY wt.tag_id`
+
+type RelatedTagRow struct {
+	ID        uint
+	Name      string
+	Category  string
+	CoCount   int64
+	WorkCount int64
+}
+
+type TagPairRow struct {
+	TagAID        uint
+	TagAName      string
+	TagACategory  string
+	TagAWorkCount int64
+	TagBID        uint
+	TagBName      string
+	TagBCategory  string
+	TagBWorkCount int64
+	Count         int64
+}
+
+type TagUsageRow struct {
+	ID        uint
+	Name      string
+	Category  string
+	WorkCount int64
+}
+
+type TagSuggestionRow struct {
+	ID        uint
+	Name      string
+	Category  string
+	WorkCount int64
+	Matched   string
+	MatchType string
+	MatchRank int
+}
 
 type TagRepository struct {
 	DB *gorm.DB
@@ -45,15 +102,21 @@ func (r *TagRepository) FindByName(name string) (*model.Tag, error) {
 	return &tag, nil
 }
 
-func (r *TagRepository) FindAll(keyword string, includeCount bool) ([]model.Tag, error) {
+func (r *TagRepository) FindAll(keyword, category string, includeCount bool) ([]model.Tag, error) {
 	var tags []model.Tag
-	query := r.DB.Model(&model.Tag{})
+	query := r.DB.Model(&model.Tag{}).
+		Select("tag.*").
+		Joins("LEFT JOIN tag_category ON tag_category.name = tag.category")
 
 	if keyword != "" {
-		query = query.Where("name LIKE ?", "%"+keyword+"%")
+		query = query.Where("tag.name LIKE ? OR tag.id IN (SELECT tag_id FROM tag_alias WHERE name LIKE ?)", "%"+keyword+"%", "%"+keyword+"%")
+	}
+
+	if category != "" {
+		query = query.Where("tag.category = ?", category)
 	}
 
-	err := query.Order("is_system DESC, id ASC").Find(&tags).Error
+	err := query.Order("COALESCE(tag_category.sort_priority, 0) DESC, tag.is_system DESC, tag.id ASC").Find(&tags).Error
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +124,10 @@ func (r *TagRepository) FindAll(keyword string, includeCount bool) ([]model.Tag,
 	if includeCount {
 		for i := range tags {
 			var count int64
-			r.DB.Model(&model.WorkTag{}).Where("tag_id = ?", tags[i].ID).Count(&count)
+			r.DB.Model(&model.WorkTag{}).
+				Joins("JOIN work ON work.id = work_tag.work_id AND work.deleted_at IS NULL").
+				Where("work_tag.tag_id = ?", tags[i].ID).
+				Count(&count)
 			tags[i].WorkCount = int(count)
 		}
 	}
@@ -74,7 +140,24 @@ func (r *TagRepository) Update(tag *model.Tag) error {
 }
 
 func (r *TagRepository) Delete(id uint) error {
-	return r.DB.Delete(&model.Tag{}, id).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&model.WorkTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&model.TagAlias{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ? OR implied_tag_id = ?", id, id).Delete(&model.TagImplication{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Tag{}).Where("parent_id = ?", id).Update("parent_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&model.AutoTagRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, id).Error
+	})
 }
 
 func (r *TagRepository) FirstOrCreate(tag *model.Tag) (*model.Tag, error) {
@@ -86,6 +169,10 @@ func (r *TagRepository) FirstOrCreate(tag *model.Tag) (*model.Tag, error) {
 }
 
 func (r *TagRepository) GetOrCreateByName(name string) (*model.Tag, error) {
+	if alias, err := r.FindAliasByName(name); err == nil {
+		return r.FindByID(alias.TagID)
+	}
+
 	var tag model.Tag
 	err := r.DB.Where("name = ?", name).FirstOrCreate(&tag, model.Tag{Name: name}).Error
 	if err != nil {
@@ -105,3 +192,390 @@ func (r *TagRepository) CountNonSystem() (int64, error) {
 	err := r.DB.Model(&model.Tag{}).Where("is_system = ?", false).Count(&count).Error
 	return count, err
 }
+
+func (r *TagRepository) FindAliasByName(name string) (*model.TagAlias, error) {
+	var alias model.TagAlias
+	err := r.DB.Where("name = ?", name).First(&alias).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (r *TagRepository) FindAliasesByTagIDs(ids []uint) (map[uint][]model.TagAlias, error) {
+	result := make(map[uint][]model.TagAlias)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var aliases []model.TagAlias
+	if err := r.DB.Where("tag_id IN ?", ids).Order("name ASC").Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		result[alias.TagID] = append(result[alias.TagID], alias)
+	}
+	return result, nil
+}
+
+func (r *TagRepository) CreateAlias(alias *model.TagAlias) error {
+	return r.DB.Create(alias).Error
+}
+
+func (r *TagRepository) DeleteAlias(tagID, aliasID uint) (int64, error) {
+	result := r.DB.Where("id = ? AND tag_id = ?", aliasID, tagID).Delete(&model.TagAlias{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *TagRepository) FindImplicationsByTagIDs(ids []uint) (map[uint][]uint, error) {
+	result := make(map[uint][]uint)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var implications []model.TagImplication
+	if err := r.DB.Where("tag_id IN ?", ids).Order("implied_tag_id ASC").Find(&implications).Error; err != nil {
+		return nil, err
+	}
+	for _, implication := range implications {
+		result[implication.TagID] = append(result[implication.TagID], implication.ImpliedTagID)
+	}
+	return result, nil
+}
+
+func (r *TagRepository) FindImpliedTagIDs(ids []uint) ([]uint, error) {
+	return expandImpliedTagIDs(r.DB, ids)
+}
+
+func (r *TagRepository) FindDescendantTagIDs(ids []uint) ([]uint, error) {
+	if len(ids) == 0 {
+		return []uint{}, nil
+	}
+	var result []uint
+	err := r.DB.Raw(tagDescendantsSQL, ids).Scan(&result).Error
+	return result, err
+}
+
+func (r *TagRepository) CreateImplication(tagID, impliedTagID uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model.TagImplication{TagID: tagID, ImpliedTagID: impliedTagID}).Error; err != nil {
+			return err
+		}
+
+		return applyImpliedTagsToWorks(tx, tagID)
+	})
+}
+
+func (r *TagRepository) DeleteImplication(tagID, impliedTagID uint) (int64, error) {
+	result := r.DB.Where("tag_id = ? AND implied_tag_id = ?", tagID, impliedTagID).Delete(&model.TagImplication{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *TagRepository) UpdateParent(id uint, parentID *uint) error {
+	return r.DB.Model(&model.Tag{}).Where("id = ?", id).Update("parent_id", parentID).Error
+}
+
+func expandImpliedTagIDs(db *gorm.DB, ids []uint) ([]uint, error) {
+	if len(ids) == 0 {
+		return []uint{}, nil
+	}
+	var result []uint
+	err := db.Raw(tagImplicationsSQL, ids).Scan(&result).Error
+	return result, err
+}
+
+func (r *TagRepository) Merge(sourceID, targetID uint, aliasName string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO work_tag (work_id, tag_id)
+			SELECT wt.work_id, ? FROM work_tag wt
+			WHERE wt.tag_id = ? AND NOT EXISTS (
+				SELECT 1 FROM work_tag existing WHERE existing.work_id = wt.work_id AND existing.tag_id = ?
+			)`, targetID, sourceID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", sourceID).Delete(&model.WorkTag{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.TagAlias{}).Where("tag_id = ?", sourceID).Update("tag_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.AutoTagRule{}).Where("tag_id = ?", sourceID).Update("tag_id", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`INSERT INTO tag_implication (tag_id, implied_tag_id, created_at)
+			SELECT ?, ti.implied_tag_id, ti.created_at FROM tag_implication ti
+			WHERE ti.tag_id = ? AND ti.implied_tag_id <> ? AND NOT EXISTS (
+				SELECT 1 FROM tag_implication existing WHERE existing.tag_id = ? AND existing.implied_tag_id = ti.implied_tag_id
+			)`, targetID, sourceID, targetID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO tag_implication (tag_id, implied_tag_id, created_at)
+			SELECT ti.tag_id, ?, ti.created_at FROM tag_implication ti
+			WHERE ti.implied_tag_id = ? AND ti.tag_id <> ? AND NOT EXISTS (
+				SELECT 1 FROM tag_implication existing WHERE existing.tag_id = ti.tag_id AND existing.implied_tag_id = ?
+			)`, targetID, sourceID, targetID, targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ? OR implied_tag_id = ?", sourceID, sourceID).Delete(&model.TagImplication{}).Error; err != nil {
+			return err
+		}
+
+		var source model.Tag
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			return err
+		}
+		var sourceDescendants []uint
+		if err := tx.Raw(tagDescendantsSQL, []uint{sourceID}).Scan(&sourceDescendants).Error; err != nil {
+			return err
+		}
+		for _, id := range sourceDescendants {
+			if id == targetID {
+				if err := tx.Model(&model.Tag{}).Where("id = ?", targetID).Update("parent_id", source.ParentID).Error; err != nil {
+					return err
+				}
+				break
+			}
+		}
+		if err := tx.Model(&model.Tag{}).Where("parent_id = ?", sourceID).Update("parent_id", targetID).Error; err != nil {
+			return err
+		}
+
+		var target model.Tag
+		if err := tx.First(&target, targetID).Error; err != nil {
+			return err
+		}
+		if target.ParentID != nil {
+			var targetDescendants []uint
+			if err := tx.Raw(tagDescendantsSQL, []uint{targetID}).Scan(&targetDescendants).Error; err != nil {
+				return err
+			}
+			for _, id := range targetDescendants {
+				if id == *target.ParentID {
+					return ErrTagParentCycle
+				}
+			}
+		}
+
+		if err := tx.Delete(&model.Tag{}, sourceID).Error; err != nil {
+			return err
+		}
+
+		if aliasName != "" {
+			if err := tx.Create(&model.TagAlias{Name: aliasName, TagID: targetID}).Error; err != nil {
+				return err
+			}
+		}
+
+		return applyImpliedTagsToWorks(tx, targetID)
+	})
+}
+
+func applyImpliedTagsToWorks(tx *gorm.DB, tagID uint) error {
+	impliedIDs, err := expandImpliedTagIDs(tx, []uint{tagID})
+	if err != nil {
+		return err
+	}
+	for _, id := range impliedIDs {
+		if id == tagID {
+			continue
+		}
+		if err := tx.Exec(`INSERT INTO work_tag (work_id, tag_id)
+			SELECT wt.work_id, ? FROM work_tag wt
+			WHERE wt.tag_id = ? AND NOT EXISTS (
+				SELECT 1 FROM work_tag existing WHERE existing.work_id = wt.work_id AND existing.tag_id = ?
+			)`, id, tagID, id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TagRepository) FindCategories() ([]model.TagCategory, error) {
+	var categories []model.TagCategory
+	err := r.DB.Order("sort_priority DESC, name ASC").Find(&categories).Error
+	return categories, err
+}
+
+func (r *TagRepository) FindCategory(name string) (*model.TagCategory, error) {
+	var category model.TagCategory
+	err := r.DB.Where("name = ?", name).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *TagRepository) UpdateCategory(category *model.TagCategory) error {
+	return r.DB.Save(category).Error
+}
+
+func (r *TagRepository) CountTaggedWorks(tagIDs []uint) (int64, error) {
+	var count int64
+	query := r.DB.Model(&model.Work{})
+	if len(tagIDs) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM work_tag wt WHERE wt.work_id = work.id AND wt.tag_id IN ?)", tagIDs)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *TagRepository) FindTaggedWorkIDs(tagIDs []uint) ([]uint, error) {
+	if len(tagIDs) == 0 {
+		return []uint{}, nil
+	}
+	var ids []uint
+	err := r.DB.Model(&model.WorkTag{}).Where("tag_id IN ?", tagIDs).Distinct().Order("work_id ASC").Pluck("work_id", &ids).Error
+	return ids, err
+}
+
+func (r *TagRepository) FindRelatedTags(tagIDs []uint, byRatio bool, minCount, limit int) ([]RelatedTagRow, error) {
+	order := "co_count DESC"
+	if byRatio {
+		order = "CAST(co_count AS REAL) / tag_usage.work_count DESC, co_count DESC"
+	}
+
+	var rows []RelatedTagRow
+	err := r.DB.Raw(`WITH context AS (
+			SELECT DISTINCT wt.work_id FROM work_tag wt
+			JOIN work ON work.id = wt.work_id AND work.deleted_at IS NULL
+			WHERE wt.tag_id IN ?
+		), tag_usage AS (`+tagUsageSQL+`)
+		SELECT tag.id AS id, tag.name AS name, tag.category AS category,
+			COUNT(*) AS co_count, tag_usage.work_count AS work_count
+		FROM work_tag wt
+		JOIN context ON context.work_id = wt.work_id
+		JOIN tag ON tag.id = wt.tag_id
+		JOIN tag_usage ON tag_usage.tag_id = wt.tag_id
+		WHERE wt.tag_id NOT IN ?
+		GROUP BY tag.id, tag.name, tag.category, tag_usage.work_count
+		HAVING COUNT(*) >= ?
+		ORDER BY `+order+`, tag.id ASC
+		LIMIT ?`, tagIDs, tagIDs, minCount, limit).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *TagRepository) FindTopTagPairs(limit int) ([]TagPairRow, error) {
+	var rows []TagPairRow
+	err := r.DB.Raw(`SELECT a.tag_id AS tag_a_id, ta.name AS tag_a_name, ta.category AS tag_a_category, ua.work_count AS tag_a_work_count,
+			b.tag_id AS tag_b_id, tb.name AS tag_b_name, tb.category AS tag_b_category, ub.work_count AS tag_b_work_count,
+			COUNT(*) AS count
+		FROM work_tag a
+		JOIN work_tag b ON b.work_id = a.work_id AND a.tag_id < b.tag_id
+		JOIN work ON work.id = a.work_id AND work.deleted_at IS NULL
+		JOIN tag ta ON ta.id = a.tag_id
+		JOIN tag tb ON tb.id = b.tag_id
+		JOIN (`+tagUsageSQL+`) ua ON ua.tag_id = a.tag_id
+		JOIN (`+tagUsageSQL+`) ub ON ub.tag_id = b.tag_id
+		GROUP BY a.tag_id, ta.name, ta.category, ua.work_count, b.tag_id, tb.name, tb.category, ub.work_count
+		ORDER BY count DESC, a.tag_id ASC, b.tag_id ASC
+		LIMIT ?`, limit).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *TagRepository) FindTagsUsedOnce(limit int) ([]TagUsageRow, int64, error) {
+	var total int64
+	if err := r.DB.Raw(`SELECT COUNT(*) FROM (` + tagUsageSQL + `) tag_usage WHERE tag_usage.work_count = 1`).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []TagUsageRow
+	err := r.DB.Raw(`SELECT tag.id AS id, tag.name AS name, tag.category AS category, tag_usage.work_count AS work_count
+		FROM tag JOIN (`+tagUsageSQL+`) tag_usage ON tag_usage.tag_id = tag.id
+		WHERE tag_usage.work_count = 1
+		ORDER BY tag.name ASC
+		LIMIT ?`, limit).Scan(&rows).Error
+	return rows, total, err
+}
+
+func (r *TagRepository) FindUnusedTags(limit int) ([]TagUsageRow, int64, error) {
+	query := r.DB.Model(&model.Tag{}).
+		Where("tag.is_system = ?", false).
+		Where("NOT EXISTS (SELECT 1 FROM work_tag wt JOIN work ON work.id = wt.work_id AND work.deleted_at IS NULL WHERE wt.tag_id = tag.id)")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []TagUsageRow
+	err := query.Select("tag.id AS id, tag.name AS name, tag.category AS category, 0 AS work_count").
+		Order("tag.name ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+func (r *TagRepository) Suggest(terms []string, limit int) ([]TagSuggestionRow, error) {
+	if len(terms) == 0 {
+		return []TagSuggestionRow{}, nil
+	}
+
+	conditions := func(column string) (string, string, []interface{}) {
+		match := make([]string, 0, len(terms))
+		prefix := make([]string, 0, len(terms))
+		var matchArgs, prefixArgs []interface{}
+		for _, term := range terms {
+			escaped := escapeLike(term)
+			match = append(match, column+" LIKE ? ESCAPE '\\'")
+			matchArgs = append(matchArgs, "%"+escaped+"%")
+			prefix = append(prefix, column+" LIKE ? ESCAPE '\\'")
+			prefixArgs = append(prefixArgs, escaped+"%")
+		}
+		return "(" + strings.Join(prefix, " OR ") + ")", "(" + strings.Join(match, " OR ") + ")", append(prefixArgs, matchArgs...)
+	}
+
+	namePrefix, nameMatch, nameArgs := conditions("name")
+	translitPrefix, translitMatch, translitArgs := conditions("transliteration")
+
+	args := make([]interface{}, 0, 2*len(nameArgs)+len(translitArgs)+1)
+	args = append(args, nameArgs...)
+	args = append(args, nameArgs...)
+	args = append(args, translitArgs...)
+	args = append(args, limit)
+
+	var rows []TagSuggestionRow
+	err := r.DB.Raw(`SELECT tag.id AS id, tag.name AS name, tag.category AS category,
+			COALESCE(tag_usage.work_count, 0) AS work_count,
+			matches.matched AS matched, matches.match_type AS match_type, MIN(matches.match_rank) AS match_rank
+		FROM (
+			SELECT id AS tag_id, name AS matched, 'name' AS match_type,
+				CASE WHEN `+namePrefix+` THEN 0 ELSE 2 END AS match_rank
+			FROM tag WHERE `+nameMatch+`
+			UNION ALL
+			SELECT tag_id, name, 'alias',
+				CASE WHEN `+namePrefix+` THEN 1 ELSE 3 END
+			FROM tag_alias WHERE `+nameMatch+`
+			UNION ALL
+			SELECT id, transliteration, 'transliteration',
+				CASE WHEN `+translitPrefix+` THEN 1 ELSE 3 END
+			FROM tag WHERE transliteration <> '' AND `+translitMatch+`
+		) matches
+		JOIN tag ON tag.id = matches.tag_id
+		LEFT JOIN (`+tagUsageSQL+`) tag_usage ON tag_usage.tag_id = tag.id
+		GROUP BY tag.id
+		ORDER BY CASE WHEN MIN(matches.match_rank) <= 1 THEN 0 ELSE 1 END ASC,
+			work_count DESC, MIN(matches.match_rank) ASC, LENGTH(tag.name) ASC, tag.id ASC
+		LIMIT ?`, args...).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *TagRepository) FindWithoutTransliteration() ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.DB.Where("transliteration = ''").Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) UpdateTransliteration(id uint, transliteration string) error {
+	return r.DB.Model(&model.Tag{}).Where("id = ?", id).Update("transliteration", transliteration).Error
+}
+
+func escapeLike(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "%", "\\%")
+	return strings.ReplaceAll(value, "_", "\\_")
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"illust-nest/internal/model"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sqlite handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(
+		&model.Tag{},
+		&model.TagAlias{},
+		&model.TagImplication{},
+		&model.Work{},
+		&model.WorkTag{},
+		&model.AutoTagRule{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func createTestTag(t *testing.T, db *gorm.DB, name string, parentID *uint) uint {
+	t.Helper()
+	tag := &model.Tag{Name: name, Category: "general", ParentID: parentID}
+	if err := db.Create(tag).Error; err != nil {
+		t.Fatalf("create tag %s: %v", name, err)
+	}
+	return tag.ID
+}
+
+func tagParent(t *testing.T, db *gorm.DB, id uint) *uint {
+	t.Helper()
+	var tag model.Tag
+	if err := db.First(&tag, id).Error; err != nil {
+		t.Fatalf("load tag %d: %v", id, err)
+	}
+	return tag.ParentID
+}
+
+func TestTagMergeIntoDescendantKeepsTreeAcyclic(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth int
+	}{
+		{name: "direct child", depth: 1},
+		{name: "grandchild", depth: 2},
+		{name: "great-grandchild", depth: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			repo := NewTagRepository(db)
+
+			root := createTestTag(t, db, "root", nil)
+			source := createTestTag(t, db, "source", &root)
+			sibling := createTestTag(t, db, "sibling", &source)
+			parent := source
+			chain := []uint{}
+			for i := 0; i < tt.depth; i++ {
+				parent = createTestTag(t, db, "chain"+string(rune('a'+i)), &parent)
+				chain = append(chain, parent)
+			}
+			target := chain[len(chain)-1]
+
+			if err := repo.Merge(source, target, ""); err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+
+			if got := tagParent(t, db, target); got == nil || *got != root {
+				t.Fatalf("target parent = %v, want %d", got, root)
+			}
+			if got := tagParent(t, db, sibling); got == nil || *got != target {
+				t.Fatalf("sibling parent = %v, want %d", got, target)
+			}
+			if len(chain) > 1 {
+				if got := tagParent(t, db, chain[0]); got == nil || *got != target {
+					t.Fatalf("first chain tag parent = %v, want %d", got, target)
+				}
+			}
+
+			descendants, err := repo.FindDescendantTagIDs([]uint{root})
+			if err != nil {
+				t.Fatalf("FindDescendantTagIDs() error = %v", err)
+			}
+			if len(descendants) != 1+1+len(chain) {
+				t.Fatalf("root descendants = %v, want root, sibling and chain", descendants)
+			}
+		})
+	}
+}
+
+func TestTagMergeIntoUnrelatedTagReparentsChildren(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTagRepository(db)
+
+	source := createTestTag(t, db, "source", nil)
+	child := createTestTag(t, db, "child", &source)
+	target := createTestTag(t, db, "target", nil)
+
+	if err := repo.Merge(source, target, "old-name"); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if got := tagParent(t, db, child); got == nil || *got != target {
+		t.Fatalf("child parent = %v, want %d", got, target)
+	}
+	if got := tagParent(t, db, target); got != nil {
+		t.Fatalf("target parent = %v, want nil", *got)
+	}
+	if _, err := repo.FindByID(source); err == nil {
+		t.Fatalf("source tag still exists")
+	}
+}
@@ -2,14 +2,63 @@ package repository
 
 import (
 	"illust-nest/internal/model"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	WorkBatchAddTags              = "add_tags"
+	WorkBatchRemoveTags           = "remove_tags"
+	WorkBatchSetRating            = "set_rating"
+	WorkBatchSetPublic            = "set_public"
+	WorkBatchPrependTitle         = "prepend_title"
+	WorkBatchAddToCollection      = "add_to_collection"
+	WorkBatchRemoveFromCollection = "remove_from_collection"
+)
+
+type WorkBatchOperation struct {
+	Type         string
+	TagIDs       []uint
+	Rating       int
+	IsPublic     bool
+	Text         string
+	CollectionID uint
+}
+
 type WorkRepository struct {
 	DB *gorm.DB
 }
 
+type WorkRatingRow struct {
+	ID     uint `gorm:"column:id"`
+	Rating int  `gorm:"column:rating"`
+}
+
+type GeoBounds struct {
+	MinLat float64
+	MaxLat float64
+	MinLng float64
+	MaxLng float64
+}
+
+type WorkTimelineRow struct {
+	Year  int   `gorm:"column:year"`
+	Month int   `gorm:"column:month"`
+	Count int64 `gorm:"column:count"`
+}
+
+type WorkGeoPointRow struct {
+	WorkID        uint       `gorm:"column:work_id"`
+	ImageID       uint       `gorm:"column:image_id"`
+	Title         string     `gorm:"column:title"`
+	ThumbnailPath string     `gorm:"column:thumbnail_path"`
+	Latitude      float64    `gorm:"column:gps_latitude"`
+	Longitude     float64    `gorm:"column:gps_longitude"`
+	TakenAt       *time.Time `gorm:"column:taken_at"`
+}
+
 type DuplicateImageHashCount struct {
 	ImageHash string `gorm:"column:image_hash"`
 	Count     int64  `gorm:"column:count"`
@@ -38,11 +87,18 @@ func (r *WorkRepository) Create(work *model.Work) error {
 			for _, tag := range work.Tags {
 				tagIds = append(tagIds, tag.ID)
 			}
+			tagIds, err := expandImpliedTagIDs(tx, tagIds)
+			if err != nil {
+				return err
+			}
 			for _, tagID := range tagIds {
 				if err := tx.Create(&model.WorkTag{WorkID: work.ID, TagID: tagID}).Error; err != nil {
 					return err
 				}
 			}
+			if err := tx.Where("id IN ?", tagIds).Find(&work.Tags).Error; err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -76,14 +132,154 @@ func (r *WorkRepository) FindAll(params map[string]interface{}, page, pageSize i
 		}).
 		Preload("Tags")
 
+	query = applyWorkListFilters(query, params)
+
+	query = applyWorkSort(query, params)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Find(&works).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return works, total, nil
+}
+
+func (r *WorkRepository) FindRatingsByFilters(params map[string]interface{}) ([]WorkRatingRow, error) {
+	var rows []WorkRatingRow
+	query := r.DB.Model(&model.Work{}).Select("work.id AS id, work.rating AS rating")
+	query = applyWorkListFilters(query, params)
+	err := query.Order("work.id ASC").Scan(&rows).Error
+	return rows, err
+}
+
+func (r *WorkRepository) FindIDsByFilters(params map[string]interface{}) ([]uint, error) {
+	var ids []uint
+	query := r.DB.Model(&model.Work{})
+	query = applyWorkListFilters(query, params)
+	err := query.Order("work.id ASC").Pluck("work.id", &ids).Error
+	return ids, err
+}
+
+func (r *WorkRepository) FindByIDs(ids []uint) ([]model.Work, error) {
+	if len(ids) == 0 {
+		return []model.Work{}, nil
+	}
+
+	var works []model.Work
+	err := r.DB.Model(&model.Work{}).
+		Where("work.id IN ?", ids).
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		Preload("Tags").
+		Find(&works).Error
+	return works, err
+}
+
+func (r *WorkRepository) FindCreatedOnMonthDay(monthDay string, beforeYear int, params map[string]interface{}, limit int) ([]model.Work, error) {
+	var works []model.Work
+	query := r.DB.Model(&model.Work{}).
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		Preload("Tags").
+		Where("strftime('%m-%d', work.created_at, 'localtime') = ?", monthDay).
+		Where("CAST(strftime('%Y', work.created_at, 'localtime') AS INTEGER) < ?", beforeYear)
+	query = applyWorkListFilters(query, params)
+	err := query.Order("work.created_at DESC").Limit(limit).Find(&works).Error
+	return works, err
+}
+
+func (r *WorkRepository) CountByTakenMonth(params map[string]interface{}) ([]WorkTimelineRow, int64, error) {
+	works := applyWorkListFilters(
+		r.DB.Model(&model.Work{}).Select("work.id, (SELECT MIN(e.taken_at) "+workEXIFSubquery+") AS taken_at"),
+		params,
+	)
+
+	var rows []WorkTimelineRow
+	err := r.DB.Table("(?) AS t", works).
+		Select("CAST(strftime('%Y', t.taken_at, 'localtime') AS INTEGER) AS year, " +
+			"CAST(strftime('%m', t.taken_at, 'localtime') AS INTEGER) AS month, COUNT(*) AS count").
+		Where("t.taken_at IS NOT NULL").
+		Group("year, month").
+		Order("year ASC, month ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var undated int64
+	err = r.DB.Table("(?) AS t", works).Where("t.taken_at IS NULL").Count(&undated).Error
+	return rows, undated, err
+}
+
+func (r *WorkRepository) FindGeoPoints(bounds GeoBounds, params map[string]interface{}, limit int) ([]WorkGeoPointRow, error) {
+	works := applyWorkListFilters(r.DB.Model(&model.Work{}).Select("work.id"), params)
+	condition, args := geoBoundsCondition(bounds)
+
+	var rows []WorkGeoPointRow
+	err := r.DB.Table("work_image_exif e").
+		Select("wi.work_id, e.image_id, work.title, wi.thumbnail_path, e.gps_latitude, e.gps_longitude, e.taken_at").
+		Joins("JOIN work_image wi ON wi.id = e.image_id AND wi.deleted_at IS NULL").
+		Joins("JOIN work ON work.id = wi.work_id AND work.deleted_at IS NULL").
+		Where(condition, args...).
+		Where("work.id IN (?)", works).
+		Order("e.taken_at IS NULL, e.taken_at DESC, e.image_id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func geoBoundsCondition(bounds GeoBounds) (string, []interface{}) {
+	condition := "e.gps_latitude BETWEEN ? AND ? AND "
+	if bounds.MinLng > bounds.MaxLng {
+		condition += "(e.gps_longitude >= ? OR e.gps_longitude <= ?)"
+	} else {
+		condition += "e.gps_longitude BETWEEN ? AND ?"
+	}
+	return condition, []interface{}{bounds.MinLat, bounds.MaxLat, bounds.MinLng, bounds.MaxLng}
+}
+
+const workEXIFSubquery = "FROM work_image wi JOIN work_image_exif e ON e.image_id = wi.id WHERE wi.work_id = work.id AND wi.deleted_at IS NULL"
+
+func applyWorkSort(query *gorm.DB, params map[string]interface{}) *gorm.DB {
+	sortOrder := "desc"
+	if s, ok := params["sort_order"].(string); ok && strings.EqualFold(s, "asc") {
+		sortOrder = "asc"
+	}
+
+	sortBy, _ := params["sort_by"].(string)
+	switch sortBy {
+	case "updated_at", "rating", "title":
+		return query.Order("work." + sortBy + " " + sortOrder)
+	case "taken_at":
+		return orderWorksByEXIF(query, "(SELECT MIN(e.taken_at) "+workEXIFSubquery+")", sortOrder)
+	case "iso":
+		return orderWorksByEXIF(query, "(SELECT MAX(e.iso) "+workEXIFSubquery+")", sortOrder)
+	case "focal_length":
+		return orderWorksByEXIF(query, "(SELECT MAX(e.focal_length) "+workEXIFSubquery+")", sortOrder)
+	default:
+		return query.Order("work.created_at " + sortOrder)
+	}
+}
+
+func orderWorksByEXIF(query *gorm.DB, expr, sortOrder string) *gorm.DB {
+	return query.Order(expr + " IS NULL").Order(expr + " " + sortOrder).Order("work.created_at " + sortOrder)
+}
+
+func applyWorkListFilters(query *gorm.DB, params map[string]interface{}) *gorm.DB {
 	if keyword, ok := params["keyword"].(string); ok && keyword != "" {
 		query = query.Where("title LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	if tagIDs, ok := params["tag_ids"].([]uint); ok && len(tagIDs) > 0 {
-		query = query.Joins("JOIN work_tags ON work_tags.work_id = works.id").
-			Where("work_tags.tag_id IN ?", tagIDs).
-			Group("works.id")
+		query = query.Joins("JOIN work_tag ON work_tag.work_id = work.id").
+			Where("work_tag.tag_id IN ("+tagDescendantsSQL+")", tagIDs).
+			Group("work.id")
 	}
 
 	if ratingMin, ok := params["rating_min"].(int); ok {
@@ -98,26 +294,196 @@ func (r *WorkRepository) FindAll(params map[string]interface{}, page, pageSize i
 		query = query.Where("is_public = ?", isPublic)
 	}
 
-	sortBy := "created_at"
-	if s, ok := params["sort_by"].(string); ok && s != "" {
-		sortBy = s
+	return applyWorkAttributeFilters(query, params)
+}
+
+func applyWorkAttributeFilters(query *gorm.DB, params map[string]interface{}) *gorm.DB {
+	if createdFrom, ok := params["created_from"].(time.Time); ok {
+		query = query.Where("work.created_at >= ?", createdFrom)
 	}
-	sortOrder := "desc"
-	if s, ok := params["sort_order"].(string); ok && s != "" {
-		sortOrder = s
+	if createdTo, ok := params["created_to"].(time.Time); ok {
+		query = query.Where("work.created_at <= ?", createdTo)
 	}
-	query = query.Order(sortBy + " " + sortOrder)
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	var imageConditions []string
+	var imageArgs []interface{}
+	if widthMin, ok := params["width_min"].(int); ok {
+		imageConditions = append(imageConditions, "wi.width >= ?")
+		imageArgs = append(imageArgs, widthMin)
+	}
+	if widthMax, ok := params["width_max"].(int); ok {
+		imageConditions = append(imageConditions, "wi.width <= ?")
+		imageArgs = append(imageArgs, widthMax)
+	}
+	if heightMin, ok := params["height_min"].(int); ok {
+		imageConditions = append(imageConditions, "wi.height >= ?")
+		imageArgs = append(imageArgs, heightMin)
+	}
+	if heightMax, ok := params["height_max"].(int); ok {
+		imageConditions = append(imageConditions, "wi.height <= ?")
+		imageArgs = append(imageArgs, heightMax)
+	}
+	if aspectRatio, ok := params["aspect_ratio"].(string); ok {
+		switch aspectRatio {
+		case "landscape":
+			imageConditions = append(imageConditions, "wi.width > wi.height * 1.1")
+		case "portrait":
+			imageConditions = append(imageConditions, "wi.height > wi.width * 1.1")
+		case "square":
+			imageConditions = append(imageConditions, "wi.width <= wi.height * 1.1 AND wi.height <= wi.width * 1.1")
+		case "panorama":
+			imageConditions = append(imageConditions, "wi.width >= wi.height * 2")
+		case "tall":
+			imageConditions = append(imageConditions, "wi.height >= wi.width * 2")
+		}
+	}
+	if exts, ok := params["formats"].([]string); ok && len(exts) > 0 {
+		formatConditions := make([]string, 0, len(exts))
+		for _, ext := range exts {
+			formatConditions = append(formatConditions, "LOWER(wi.storage_path) LIKE ?")
+			imageArgs = append(imageArgs, "%"+ext)
+		}
+		imageConditions = append(imageConditions, "("+strings.Join(formatConditions, " OR ")+")")
+	}
+	if len(imageConditions) > 0 {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND "+strings.Join(imageConditions, " AND ")+")",
+			imageArgs...,
+		)
 	}
 
-	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Find(&works).Error; err != nil {
-		return nil, 0, err
+	if imageCountMin, ok := params["image_count_min"].(int); ok {
+		query = query.Where("(SELECT COUNT(*) FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL) >= ?", imageCountMin)
+	}
+	if imageCountMax, ok := params["image_count_max"].(int); ok {
+		query = query.Where("(SELECT COUNT(*) FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL) <= ?", imageCountMax)
 	}
 
-	return works, total, nil
+	if hasAIMetadata, ok := params["has_ai_metadata"].(bool); ok {
+		if hasAIMetadata {
+			query = query.Where("EXISTS (SELECT 1 FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND wi.ai_metadata <> '')")
+		} else {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM work_image wi WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND wi.ai_metadata <> '')")
+		}
+	}
+
+	var generationConditions []string
+	var generationArgs []interface{}
+	if checkpointID, ok := params["ai_checkpoint_id"].(uint); ok {
+		generationConditions = append(generationConditions, "g.checkpoint_id = ?")
+		generationArgs = append(generationArgs, checkpointID)
+	}
+	if samplerID, ok := params["ai_sampler_id"].(uint); ok {
+		generationConditions = append(generationConditions, "g.sampler_id = ?")
+		generationArgs = append(generationArgs, samplerID)
+	}
+	if seed, ok := params["ai_seed"].(string); ok {
+		generationConditions = append(generationConditions, "g.seed = ?")
+		generationArgs = append(generationArgs, seed)
+	}
+	var loraConditions []string
+	if loraID, ok := params["ai_lora_id"].(uint); ok {
+		loraConditions = append(loraConditions, "l.lora_id = ?")
+		generationArgs = append(generationArgs, loraID)
+	}
+	if weightMin, ok := params["ai_lora_weight_min"].(float64); ok {
+		loraConditions = append(loraConditions, "l.weight >= ?")
+		generationArgs = append(generationArgs, weightMin)
+	}
+	if weightMax, ok := params["ai_lora_weight_max"].(float64); ok {
+		loraConditions = append(loraConditions, "l.weight <= ?")
+		generationArgs = append(generationArgs, weightMax)
+	}
+	if len(generationConditions) > 0 || len(loraConditions) > 0 {
+		joins := "JOIN work_image_generation g ON g.image_id = wi.id"
+		if len(loraConditions) > 0 {
+			joins += " JOIN work_image_lora l ON l.image_id = wi.id"
+		}
+		conditions := append(generationConditions, loraConditions...)
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM work_image wi "+joins+" WHERE wi.work_id = work.id AND wi.deleted_at IS NULL AND "+strings.Join(conditions, " AND ")+")",
+			generationArgs...,
+		)
+	}
+
+	query = applyWorkEXIFFilters(query, params)
+
+	if match, ok := params["color"].(ColorMatch); ok {
+		condition, matchArgs := colorMatchCondition(match)
+		query = query.Where(condition, matchArgs...)
+	}
+
+	if animated, ok := params["animated"].(bool); ok {
+		query = query.Where(animatedCondition(animated))
+	}
+	if mediaType, ok := params["media_type"].(string); ok {
+		condition, mediaArgs := mediaTypeCondition(mediaType)
+		query = query.Where(condition, mediaArgs...)
+	}
+
+	if untagged, ok := params["untagged"].(bool); ok && untagged {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM work_tag wt WHERE wt.work_id = work.id)")
+	}
+
+	if notInCollection, ok := params["not_in_collection"].(bool); ok && notInCollection {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM collection_work cw WHERE cw.work_id = work.id)")
+	}
+
+	return query
+}
+
+func applyWorkEXIFFilters(query *gorm.DB, params map[string]interface{}) *gorm.DB {
+	var conditions []string
+	var args []interface{}
+	if camera, ok := params["exif_camera"].(string); ok {
+		conditions = append(conditions, `(e.camera_make || ' ' || e.camera_model) LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(camera)+"%")
+	}
+	if lens, ok := params["exif_lens"].(string); ok {
+		conditions = append(conditions, `e.lens_model LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(lens)+"%")
+	}
+	if isoMin, ok := params["iso_min"].(int); ok {
+		conditions = append(conditions, "e.iso >= ?")
+		args = append(args, isoMin)
+	}
+	if isoMax, ok := params["iso_max"].(int); ok {
+		conditions = append(conditions, "e.iso <= ?")
+		args = append(args, isoMax)
+	}
+	if focalMin, ok := params["focal_length_min"].(float64); ok {
+		conditions = append(conditions, "e.focal_length >= ?")
+		args = append(args, focalMin)
+	}
+	if focalMax, ok := params["focal_length_max"].(float64); ok {
+		conditions = append(conditions, "e.focal_length <= ?")
+		args = append(args, focalMax)
+	}
+	if takenFrom, ok := params["taken_from"].(time.Time); ok {
+		conditions = append(conditions, "e.taken_at >= ?")
+		args = append(args, takenFrom)
+	}
+	if takenTo, ok := params["taken_to"].(time.Time); ok {
+		conditions = append(conditions, "e.taken_at <= ?")
+		args = append(args, takenTo)
+	}
+	if bounds, ok := params["gps_bounds"].(GeoBounds); ok {
+		condition, boundsArgs := geoBoundsCondition(bounds)
+		conditions = append(conditions, condition)
+		args = append(args, boundsArgs...)
+	}
+	if hasGPS, ok := params["has_gps"].(bool); ok {
+		gpsCondition := "EXISTS (SELECT 1 " + workEXIFSubquery + " AND e.gps_latitude IS NOT NULL AND e.gps_longitude IS NOT NULL)"
+		if hasGPS {
+			query = query.Where(gpsCondition)
+		} else {
+			query = query.Where("NOT " + gpsCondition)
+		}
+	}
+	if len(conditions) > 0 {
+		query = query.Where("EXISTS (SELECT 1 "+workEXIFSubquery+" AND "+strings.Join(conditions, " AND ")+")", args...)
+	}
+	return query
 }
 
 func (r *WorkRepository) Update(work *model.Work, tagIDs []uint) error {
@@ -135,6 +501,10 @@ func (r *WorkRepository) Update(work *model.Work, tagIDs []uint) error {
 			return err
 		}
 
+		tagIDs, err := expandImpliedTagIDs(tx, tagIDs)
+		if err != nil {
+			return err
+		}
 		for _, tagID := range tagIDs {
 			if err := tx.Create(&model.WorkTag{WorkID: work.ID, TagID: tagID}).Error; err != nil {
 				return err
@@ -146,37 +516,135 @@ func (r *WorkRepository) Update(work *model.Work, tagIDs []uint) error {
 }
 
 func (r *WorkRepository) Delete(id uint) error {
-	return r.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("work_id = ?", id).Delete(&model.WorkTag{}).Error; err != nil {
+	return r.DB.Delete(&model.Work{}, id).Error
+}
+
+func (r *WorkRepository) BatchDelete(ids []uint) (int64, error) {
+	result := r.DB.Where("id IN ?", ids).Delete(&model.Work{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *WorkRepository) FindTrashed(page, pageSize int) ([]model.Work, int64, error) {
+	var works []model.Work
+	var total int64
+
+	query := r.DB.Unscoped().Model(&model.Work{}).
+		Where("work.deleted_at IS NOT NULL").
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		Preload("Tags")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("work.deleted_at DESC").Offset(offset).Limit(pageSize).Find(&works).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return works, total, nil
+}
+
+func (r *WorkRepository) Restore(ids []uint) (int64, error) {
+	result := r.DB.Unscoped().Model(&model.Work{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Update("deleted_at", nil)
+	return result.RowsAffected, result.Error
+}
+
+func (r *WorkRepository) FindTrashedImages() ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Unscoped().Model(&model.WorkImage{}).
+		Joins("JOIN work ON work.id = work_image.work_id").
+		Where("work_image.deleted_at IS NOT NULL AND work.deleted_at IS NULL").
+		Order("work_image.deleted_at DESC").
+		Find(&images).Error
+	return images, err
+}
+
+func (r *WorkRepository) RestoreImages(ids []uint) (int64, error) {
+	result := r.DB.Unscoped().Model(&model.WorkImage{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", ids).
+		Update("deleted_at", nil)
+	return result.RowsAffected, result.Error
+}
+
+func (r *WorkRepository) FindTrashedWorkIDs(deletedBefore *time.Time) ([]uint, error) {
+	var ids []uint
+	query := r.DB.Unscoped().Model(&model.Work{}).Where("deleted_at IS NOT NULL")
+	if deletedBefore != nil {
+		query = query.Where("deleted_at < ?", *deletedBefore)
+	}
+	err := query.Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *WorkRepository) FindTrashedImagesBefore(deletedBefore *time.Time) ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	query := r.DB.Unscoped().Where("deleted_at IS NOT NULL")
+	if deletedBefore != nil {
+		query = query.Where("deleted_at < ?", *deletedBefore)
+	}
+	err := query.Find(&images).Error
+	return images, err
+}
+
+func (r *WorkRepository) FindTrashedImagesByIDs(ids []uint) ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", ids).Find(&images).Error
+	return images, err
+}
+
+func (r *WorkRepository) FindAllImagesByWorkIDs(workIDs []uint) ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	err := r.DB.Unscoped().Where("work_id IN ?", workIDs).Find(&images).Error
+	return images, err
+}
+
+func (r *WorkRepository) PurgeWorks(ids []uint) (int64, error) {
+	var purgedCount int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("work_id IN ?", ids).Delete(&model.WorkTag{}).Error; err != nil {
 			return err
 		}
-		if err := tx.Where("work_id = ?", id).Delete(&model.CollectionWork{}).Error; err != nil {
+		if err := tx.Where("work_id IN ?", ids).Delete(&model.CollectionWork{}).Error; err != nil {
 			return err
 		}
-		if err := tx.Where("work_id = ?", id).Delete(&model.WorkImage{}).Error; err != nil {
+		imageIDs := tx.Unscoped().Model(&model.WorkImage{}).Select("id").Where("work_id IN ?", ids)
+		if err := deleteImageGenerations(tx, imageIDs); err != nil {
 			return err
 		}
-		return tx.Delete(&model.Work{}, id).Error
+		if err := deleteImageEXIF(tx, imageIDs); err != nil {
+			return err
+		}
+		if err := deleteImageColors(tx, imageIDs); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("work_id IN ?", ids).Delete(&model.WorkImage{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&model.Work{})
+		purgedCount = result.RowsAffected
+		return result.Error
 	})
+	return purgedCount, err
 }
 
-func (r *WorkRepository) BatchDelete(ids []uint) (int64, error) {
-	var deletedCount int64
-	err := r.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("work_id IN ?", ids).Delete(&model.WorkTag{}).Error; err != nil {
+func (r *WorkRepository) PurgeImages(ids []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteImageGenerations(tx, ids); err != nil {
 			return err
 		}
-		if err := tx.Where("work_id IN ?", ids).Delete(&model.CollectionWork{}).Error; err != nil {
+		if err := deleteImageEXIF(tx, ids); err != nil {
 			return err
 		}
-		if err := tx.Where("work_id IN ?", ids).Delete(&model.WorkImage{}).Error; err != nil {
+		if err := deleteImageColors(tx, ids); err != nil {
 			return err
 		}
-		result := tx.Where("id IN ?", ids).Delete(&model.Work{})
-		deletedCount = result.RowsAffected
-		return result.Error
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.WorkImage{}).Error
 	})
-	return deletedCount, err
 }
 
 func (r *WorkRepository) BatchUpdatePublicStatus(ids []uint, isPublic bool) (int64, error) {
@@ -222,6 +690,20 @@ func (r *WorkRepository) UpdateImageAIMetadata(workID, imageID uint, metadata st
 	return nil
 }
 
+func (r *WorkRepository) FindImagesForAIMetadataScan(workIDs []uint, onlyMissing bool) ([]model.WorkImage, error) {
+	var images []model.WorkImage
+	query := r.DB.Model(&model.WorkImage{}).
+		Joins("JOIN work ON work.id = work_image.work_id AND work.deleted_at IS NULL")
+	if len(workIDs) > 0 {
+		query = query.Where("work_image.work_id IN ?", workIDs)
+	}
+	if onlyMissing {
+		query = query.Where("COALESCE(work_image.ai_metadata, '') = ''")
+	}
+	err := query.Order("work_image.work_id ASC, work_image.sort_order ASC").Find(&images).Error
+	return images, err
+}
+
 func (r *WorkRepository) FindImagesByWorkID(workID uint) ([]model.WorkImage, error) {
 	var images []model.WorkImage
 	err := r.DB.Where("work_id = ?", workID).Order("sort_order ASC").Find(&images).Error
@@ -257,42 +739,44 @@ func (r *WorkRepository) FindByCollectionID(collectionID uint, params map[string
 	var total int64
 
 	query := r.DB.Model(&model.Work{}).
-		Joins("JOIN collection_works ON collection_works.work_id = works.id").
-		Where("collection_works.collection_id = ?", collectionID).
+		Joins("JOIN collection_work ON collection_work.work_id = work.id").
+		Where("collection_work.collection_id = ?", collectionID).
 		Preload("Images", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
 		Preload("Tags")
 
 	if keyword, ok := params["keyword"].(string); ok && keyword != "" {
-		query = query.Where("works.title LIKE ? OR works.description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+		query = query.Where("work.title LIKE ? OR work.description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	if tagIDs, ok := params["tag_ids"].([]uint); ok && len(tagIDs) > 0 {
-		query = query.Joins("JOIN work_tags ON work_tags.work_id = works.id").
-			Where("work_tags.tag_id IN ?", tagIDs).
-			Group("works.id")
+		query = query.Joins("JOIN work_tag ON work_tag.work_id = work.id").
+			Where("work_tag.tag_id IN ("+tagDescendantsSQL+")", tagIDs).
+			Group("work.id")
 	}
 
 	if ratingMin, ok := params["rating_min"].(int); ok {
-		query = query.Where("works.rating >= ?", ratingMin)
+		query = query.Where("work.rating >= ?", ratingMin)
 	}
 
 	if ratingMax, ok := params["rating_max"].(int); ok {
-		query = query.Where("works.rating <= ?", ratingMax)
+		query = query.Where("work.rating <= ?", ratingMax)
 	}
 
 	if isPublic, ok := params["is_public"].(bool); ok {
-		query = query.Where("works.is_public = ?", isPublic)
+		query = query.Where("work.is_public = ?", isPublic)
 	}
 
+	query = applyWorkAttributeFilters(query, params)
+
 	result := query.Count(&total)
 	if result.Error != nil {
 		return nil, 0, result.Error
 	}
 
 	offset := (page - 1) * pageSize
-	if err := query.Order("collection_works.sort_order ASC").Offset(offset).Limit(pageSize).Find(&works).Error; err != nil {
+	if err := query.Order("collection_work.sort_order ASC").Offset(offset).Limit(pageSize).Find(&works).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -307,13 +791,16 @@ func (r *WorkRepository) Count() (int64, error) {
 
 func (r *WorkRepository) CountImages() (int64, error) {
 	var count int64
-	err := r.DB.Model(&model.WorkImage{}).Count(&count).Error
+	err := r.DB.Model(&model.WorkImage{}).
+		Joins("JOIN work ON work.id = work_image.work_id AND work.deleted_at IS NULL").
+		Count(&count).Error
 	return count, err
 }
 
 func (r *WorkRepository) FindDuplicateImageHashCounts() ([]DuplicateImageHashCount, error) {
 	var rows []DuplicateImageHashCount
 	err := r.DB.Model(&model.WorkImage{}).
+		Joins("JOIN work ON work.id = work_image.work_id AND work.deleted_at IS NULL").
 		Select("image_hash, COUNT(*) AS count").
 		Where("image_hash <> ?", "").
 		Group("image_hash").
@@ -326,7 +813,7 @@ func (r *WorkRepository) FindDuplicateImageHashCounts() ([]DuplicateImageHashCou
 func (r *WorkRepository) FindAllForExport() ([]model.Work, error) {
 	var works []model.Work
 	err := r.DB.Model(&model.Work{}).
-		Order("works.created_at ASC, works.id ASC").
+		Order("work.created_at ASC, work.id ASC").
 		Preload("Images", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
@@ -338,13 +825,13 @@ func (r *WorkRepository) FindAllForExport() ([]model.Work, error) {
 func (r *WorkRepository) IsPublicImagePath(path string, isThumbnail bool) (bool, error) {
 	var count int64
 	query := r.DB.Model(&model.WorkImage{}).
-		Joins("JOIN works ON works.id = work_images.work_id").
-		Where("works.is_public = ?", true)
+		Joins("JOIN work ON work.id = work_image.work_id").
+		Where("work.is_public = ? AND work.deleted_at IS NULL", true)
 
 	if isThumbnail {
-		query = query.Where("work_images.thumbnail_path = ?", path)
+		query = query.Where("(work_image.thumbnail_path = ? OR work_image.animated_thumbnail_path = ?)", path, path)
 	} else {
-		query = query.Where("(work_images.storage_path = ? OR work_images.transcoded_path = ?)", path, path)
+		query = query.Where("(work_image.storage_path = ? OR work_image.transcoded_path = ?)", path, path)
 	}
 
 	err := query.Count(&count).Error
@@ -361,16 +848,119 @@ func (r *WorkRepository) FindDuplicateImagesByHashes(hashes []string, excludeWor
 
 	var images []model.WorkImage
 	query := r.DB.Model(&model.WorkImage{}).
-		Select("id", "work_id", "image_hash", "thumbnail_path").
-		Where("image_hash IN ?", hashes)
+		Select("work_image.id", "work_image.work_id", "work_image.image_hash", "work_image.thumbnail_path").
+		Joins("JOIN work ON work.id = work_image.work_id AND work.deleted_at IS NULL").
+		Where("work_image.image_hash IN ?", hashes)
 
 	if excludeWorkID != nil {
-		query = query.Where("work_id <> ?", *excludeWorkID)
+		query = query.Where("work_image.work_id <> ?", *excludeWorkID)
 	}
 
-	if err := query.Order("id ASC").Find(&images).Error; err != nil {
+	if err := query.Order("work_image.id ASC").Find(&images).Error; err != nil {
 		return nil, err
 	}
 
 	return images, nil
 }
+
+func (r *WorkRepository) FindCollectionIDsByWorkIDs(ids []uint) (map[uint][]uint, error) {
+	result := make(map[uint][]uint, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var rows []model.CollectionWork
+	if err := r.DB.Where("work_id IN ?", ids).
+		Order("work_id ASC, collection_id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.WorkID] = append(result[row.WorkID], row.CollectionID)
+	}
+	return result, nil
+}
+
+func (r *WorkRepository) ApplyBatchOperations(ids []uint, operations []WorkBatchOperation) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, op := range operations {
+			if err := applyWorkBatchOperation(tx, ids, op); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func applyWorkBatchOperation(tx *gorm.DB, ids []uint, op WorkBatchOperation) error {
+	switch op.Type {
+	case WorkBatchAddTags:
+		tagIDs, err := expandImpliedTagIDs(tx, op.TagIDs)
+		if err != nil {
+			return err
+		}
+		for _, tagID := range tagIDs {
+			if err := tx.Exec(`INSERT INTO work_tag (work_id, tag_id)
+				SELECT work.id, ? FROM work
+				WHERE work.id IN ? AND NOT EXISTS (
+					SELECT 1 FROM work_tag wt WHERE wt.work_id = work.id AND wt.tag_id = ?
+				)`, tagID, ids, tagID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case WorkBatchRemoveTags:
+		if len(op.TagIDs) == 0 {
+			return nil
+		}
+		return tx.Where("work_id IN ? AND tag_id IN ?", ids, op.TagIDs).Delete(&model.WorkTag{}).Error
+	case WorkBatchSetRating:
+		return tx.Model(&model.Work{}).Where("id IN ?", ids).Update("rating", op.Rating).Error
+	case WorkBatchSetPublic:
+		return tx.Model(&model.Work{}).Where("id IN ?", ids).Update("is_public", op.IsPublic).Error
+	case WorkBatchPrependTitle:
+		return tx.Model(&model.Work{}).Where("id IN ?", ids).Update("title", gorm.Expr("? || title", op.Text)).Error
+	case WorkBatchAddToCollection:
+		var maxSortOrder int
+		if err := tx.Model(&model.CollectionWork{}).
+			Where("collection_id = ?", op.CollectionID).
+			Select("COALESCE(MAX(sort_order), 0)").
+			Scan(&maxSortOrder).Error; err != nil {
+			return err
+		}
+
+		var existing []uint
+		if err := tx.Model(&model.CollectionWork{}).
+			Where("collection_id = ? AND work_id IN ?", op.CollectionID, ids).
+			Pluck("work_id", &existing).Error; err != nil {
+			return err
+		}
+		skip := make(map[uint]struct{}, len(existing))
+		for _, id := range existing {
+			skip[id] = struct{}{}
+		}
+
+		for _, id := range ids {
+			if _, ok := skip[id]; ok {
+				continue
+			}
+			maxSortOrder++
+			if err := tx.Create(&model.CollectionWork{
+				CollectionID: op.CollectionID,
+				WorkID:       id,
+				SortOrder:    maxSortOrder,
+				AddedAt:      time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case WorkBatchRemoveFromCollection:
+		return tx.Where("collection_id = ? AND work_id IN ?", op.CollectionID, ids).Delete(&model.CollectionWork{}).Error
+	}
+	return nil
+}
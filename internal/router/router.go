@@ -11,7 +11,6 @@ import (
 	"illust-nest/internal/service"
 	"io"
 	"io/fs"
-	"mime"
 	"net/http"
 	"os"
 	"path"
@@ -42,6 +41,10 @@ func Setup() *gin.Engine {
 	publicHandler := setupPublic()
 	tagHandler := setupTag()
 	collectionHandler := setupCollection()
+	trashHandler := setupTrash()
+	batchEditHandler := setupBatchEdit()
+	autoTagHandler := setupAutoTag()
+	aiMetadataHandler := setupAIMetadata()
 
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
@@ -55,6 +58,7 @@ func Setup() *gin.Engine {
 		system.PUT("/settings", middleware.Auth(), systemHandler.UpdateSettings)
 		system.GET("/statistics", middleware.Auth(), systemHandler.GetStatistics)
 		system.GET("/imagemagick/test", middleware.Auth(), systemHandler.TestImageMagick)
+		system.GET("/ffmpeg/test", middleware.Auth(), systemHandler.TestFFmpeg)
 	}
 
 	auth := r.Group("/api/auth")
@@ -77,6 +81,8 @@ func Setup() *gin.Engine {
 		publicWorks := public.Group("/works")
 		{
 			publicWorks.GET("", publicHandler.ListWorks)
+			publicWorks.GET("/random", publicHandler.RandomWorks)
+			publicWorks.GET("/on-this-day", publicHandler.OnThisDayWorks)
 			publicWorks.GET("/:id", publicHandler.GetWork)
 			publicWorks.GET("/:id/images/:imageId/exif", publicHandler.GetImageEXIF)
 		}
@@ -95,20 +101,31 @@ func Setup() *gin.Engine {
 		works := api.Group("/works")
 		{
 			works.GET("", workHandler.List)
+			works.GET("/random", workHandler.Random)
+			works.GET("/on-this-day", workHandler.OnThisDay)
+			works.GET("/timeline", workHandler.Timeline)
+			works.GET("/geo", workHandler.Geo)
 			works.GET("/export/images", workHandler.ExportImages)
 			works.POST("/images/duplicates", workHandler.CheckDuplicateImages)
+			works.GET("/images/ai-metadata/rescan", workHandler.GetAIMetadataRescanStatus)
+			works.POST("/images/ai-metadata/rescan", workHandler.RescanAIMetadata)
 			works.POST("", workHandler.Create)
 			works.GET("/:id/download", workHandler.DownloadImages)
 			works.GET("/:id/images/:imageId/exif", workHandler.GetImageEXIF)
 			works.GET("/:id", workHandler.Get)
+			works.GET("/:id/history", workHandler.History)
+			works.POST("/:id/history/:logId/revert", workHandler.Revert)
 			works.PUT("/:id", workHandler.Update)
 			works.DELETE("/:id", workHandler.Delete)
 			works.DELETE("/batch", workHandler.BatchDelete)
 			works.PUT("/batch/public", workHandler.BatchUpdatePublic)
+			works.POST("/batch/edit", batchEditHandler.Apply)
 			works.POST("/:id/images", workHandler.AddImages)
 			works.DELETE("/:id/images/:imageId", workHandler.DeleteImage)
 			works.PUT("/:id/images/order", workHandler.UpdateImageOrder)
 			works.PUT("/:id/images/:imageId/ai-metadata", workHandler.UpdateImageAIMetadata)
+			works.GET("/:id/images/:imageId/ai-metadata/export", workHandler.ExportImageAIMetadata)
+			works.POST("/:id/images/:imageId/ai-metadata/import", workHandler.ImportImageAIMetadata)
 		}
 
 		tags := api.Group("/tags")
@@ -118,6 +135,40 @@ func Setup() *gin.Engine {
 			tags.PUT("/:id", tagHandler.Update)
 			tags.DELETE("/:id", tagHandler.Delete)
 			tags.POST("/batch", tagHandler.BatchCreate)
+			tags.PUT("/:id/parent", tagHandler.UpdateParent)
+			tags.POST("/:id/aliases", tagHandler.AddAlias)
+			tags.DELETE("/:id/aliases/:aliasId", tagHandler.DeleteAlias)
+			tags.POST("/:id/implications", tagHandler.AddImplication)
+			tags.DELETE("/:id/implications/:impliedId", tagHandler.DeleteImplication)
+			tags.POST("/:id/merge", tagHandler.Merge)
+			tags.GET("/categories", tagHandler.ListCategories)
+			tags.GET("/related", tagHandler.Related)
+			tags.GET("/autocomplete", tagHandler.Autocomplete)
+			tags.PUT("/categories/:name", tagHandler.UpdateCategory)
+		}
+
+		autoTagRules := api.Group("/auto-tag-rules")
+		{
+			autoTagRules.GET("", autoTagHandler.List)
+			autoTagRules.POST("", autoTagHandler.Create)
+			autoTagRules.POST("/preview", autoTagHandler.Preview)
+			autoTagRules.PUT("/:id", autoTagHandler.Update)
+			autoTagRules.DELETE("/:id", autoTagHandler.Delete)
+		}
+
+		aiMetadata := api.Group("/ai-metadata")
+		{
+			aiMetadata.GET("/:kind", aiMetadataHandler.List)
+			aiMetadata.GET("/:kind/count", aiMetadataHandler.Count)
+		}
+
+		trash := api.Group("/trash")
+		{
+			trash.GET("/works", trashHandler.ListWorks)
+			trash.POST("/works/restore", trashHandler.RestoreWorks)
+			trash.GET("/images", trashHandler.ListImages)
+			trash.POST("/images/restore", trashHandler.RestoreImages)
+			trash.DELETE("", trashHandler.Empty)
 		}
 
 		collections := api.Group("/collections")
@@ -179,33 +230,82 @@ func setupWork() *handler.WorkHandler {
 	tagRepo := repository.NewTagRepository(database.DB)
 	settingRepo := repository.NewSettingRepository(database.DB)
 	imageService := service.NewImageService(settingRepo)
-	workService := service.NewWorkService(workRepo, tagRepo, imageService)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(database.DB))
+	autoTagService := service.NewAutoTagService(repository.NewAutoTagRuleRepository(database.DB), tagRepo, workRepo)
+	aiMetadataService := service.NewAIMetadataService(repository.NewAIMetadataRepository(database.DB))
+	imageEXIFService := service.NewImageEXIFService(repository.NewImageEXIFRepository(database.DB))
+	paletteService := service.NewImagePaletteService(repository.NewImagePaletteRepository(database.DB))
+	workService := service.NewWorkService(workRepo, tagRepo, imageService, auditService, autoTagService, aiMetadataService, imageEXIFService, paletteService)
 	return handler.NewWorkHandler(workService, imageService)
 }
 
+func setupBatchEdit() *handler.BatchEditHandler {
+	workRepo := repository.NewWorkRepository(database.DB)
+	tagRepo := repository.NewTagRepository(database.DB)
+	collectionRepo := repository.NewCollectionRepository(database.DB)
+	settingRepo := repository.NewSettingRepository(database.DB)
+	imageService := service.NewImageService(settingRepo)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(database.DB))
+	autoTagService := service.NewAutoTagService(repository.NewAutoTagRuleRepository(database.DB), tagRepo, workRepo)
+	aiMetadataService := service.NewAIMetadataService(repository.NewAIMetadataRepository(database.DB))
+	imageEXIFService := service.NewImageEXIFService(repository.NewImageEXIFRepository(database.DB))
+	paletteService := service.NewImagePaletteService(repository.NewImagePaletteRepository(database.DB))
+	workService := service.NewWorkService(workRepo, tagRepo, imageService, auditService, autoTagService, aiMetadataService, imageEXIFService, paletteService)
+	batchEditService := service.NewBatchEditService(workRepo, tagRepo, collectionRepo, auditService)
+	return handler.NewBatchEditHandler(batchEditService, workService)
+}
+
 func setupPublic() *handler.PublicHandler {
 	workRepo := repository.NewWorkRepository(database.DB)
 	tagRepo := repository.NewTagRepository(database.DB)
 	settingRepo := repository.NewSettingRepository(database.DB)
 	imageService := service.NewImageService(settingRepo)
-	workService := service.NewWorkService(workRepo, tagRepo, imageService)
-	tagService := service.NewTagService(tagRepo)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(database.DB))
+	autoTagService := service.NewAutoTagService(repository.NewAutoTagRuleRepository(database.DB), tagRepo, workRepo)
+	aiMetadataService := service.NewAIMetadataService(repository.NewAIMetadataRepository(database.DB))
+	imageEXIFService := service.NewImageEXIFService(repository.NewImageEXIFRepository(database.DB))
+	paletteService := service.NewImagePaletteService(repository.NewImagePaletteRepository(database.DB))
+	workService := service.NewWorkService(workRepo, tagRepo, imageService, auditService, autoTagService, aiMetadataService, imageEXIFService, paletteService)
+	tagService := service.NewTagService(tagRepo, workRepo, auditService)
 	return handler.NewPublicHandler(workService, tagService)
 }
 
 func setupTag() *handler.TagHandler {
 	tagRepo := repository.NewTagRepository(database.DB)
-	tagService := service.NewTagService(tagRepo)
+	workRepo := repository.NewWorkRepository(database.DB)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(database.DB))
+	tagService := service.NewTagService(tagRepo, workRepo, auditService)
 	return handler.NewTagHandler(tagService)
 }
 
+func setupAutoTag() *handler.AutoTagHandler {
+	workRepo := repository.NewWorkRepository(database.DB)
+	tagRepo := repository.NewTagRepository(database.DB)
+	autoTagService := service.NewAutoTagService(repository.NewAutoTagRuleRepository(database.DB), tagRepo, workRepo)
+	return handler.NewAutoTagHandler(autoTagService)
+}
+
+func setupAIMetadata() *handler.AIMetadataHandler {
+	aiMetadataService := service.NewAIMetadataService(repository.NewAIMetadataRepository(database.DB))
+	return handler.NewAIMetadataHandler(aiMetadataService)
+}
+
 func setupCollection() *handler.CollectionHandler {
 	collectionRepo := repository.NewCollectionRepository(database.DB)
 	workRepo := repository.NewWorkRepository(database.DB)
-	collectionService := service.NewCollectionService(collectionRepo, workRepo)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(database.DB))
+	collectionService := service.NewCollectionService(collectionRepo, workRepo, auditService)
 	return handler.NewCollectionHandler(collectionService)
 }
 
+func setupTrash() *handler.TrashHandler {
+	workRepo := repository.NewWorkRepository(database.DB)
+	settingRepo := repository.NewSettingRepository(database.DB)
+	imageService := service.NewImageService(settingRepo)
+	trashService := service.NewTrashService(workRepo, settingRepo, imageService)
+	return handler.NewTrashHandler(trashService)
+}
+
 func serveOriginalImage(c *gin.Context) {
 	serveImage(c, "uploads/originals", c.Param("filepath"))
 }
@@ -238,14 +338,7 @@ func serveImage(c *gin.Context, logicalPrefix, rawPath string) {
 		return
 	}
 	defer file.Close()
-	if objectInfo.ContentType != "" {
-		c.Header("Content-Type", objectInfo.ContentType)
-	} else if contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(logicalPath))); contentType != "" {
-		c.Header("Content-Type", contentType)
-	}
-	c.Header("Cache-Control", "max-age=31536000")
-	c.Status(http.StatusOK)
-	_, _ = io.Copy(c.Writer, file)
+	handler.ServeStoredObject(c, file, objectInfo, logicalPath, "max-age=31536000")
 }
 
 func serveFrontend(c *gin.Context) {
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"illust-nest/internal/repository"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	aiMetadataDefaultListLimit = 100
+	aiMetadataMaxListLimit     = 1000
+)
+
+type AIMetadataService struct {
+	aiMetadataRepo *repository.AIMetadataRepository
+}
+
+func NewAIMetadataService(aiMetadataRepo *repository.AIMetadataRepository) *AIMetadataService {
+	return &AIMetadataService{aiMetadataRepo: aiMetadataRepo}
+}
+
+func (s *AIMetadataService) ListUsage(kind, keyword string, limit int) ([]AIMetadataUsageItem, error) {
+	if !isAIMetadataKind(kind) {
+		return nil, ErrAIMetadataKindNotFound
+	}
+	if limit <= 0 {
+		limit = aiMetadataDefaultListLimit
+	}
+	if limit > aiMetadataMaxListLimit {
+		limit = aiMetadataMaxListLimit
+	}
+
+	rows, err := s.aiMetadataRepo.ListUsage(kind, strings.TrimSpace(keyword), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]AIMetadataUsageItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, AIMetadataUsageItem{
+			ID:         row.ID,
+			Name:       row.Name,
+			ImageCount: row.ImageCount,
+			WorkCount:  row.WorkCount,
+			MinWeight:  row.MinWeight,
+			MaxWeight:  row.MaxWeight,
+		})
+	}
+	return items, nil
+}
+
+func (s *AIMetadataService) CountUsage(kind, keyword string) (int64, error) {
+	if !isAIMetadataKind(kind) {
+		return 0, ErrAIMetadataKindNotFound
+	}
+	return s.aiMetadataRepo.CountUsage(kind, strings.TrimSpace(keyword))
+}
+
+func (s *AIMetadataService) SyncImage(imageID, workID uint, metadata *AIImageMetadata) {
+	if s == nil {
+		return
+	}
+	if err := s.aiMetadataRepo.SyncImage(imageID, workID, imageGenerationFields(metadata)); err != nil {
+		log.Printf("Failed to index AI metadata for image %d: %v", imageID, err)
+	}
+}
+
+func (s *AIMetadataService) BackfillStructuredMetadata() error {
+	images, err := s.aiMetadataRepo.FindImagesWithoutGeneration()
+	if err != nil {
+		return err
+	}
+	for i := range images {
+		if metadata := parseAIMetadata(images[i].AIMetadata); metadata != nil {
+			s.SyncImage(images[i].ID, images[i].WorkID, metadata)
+		}
+	}
+	return nil
+}
+
+func isAIMetadataKind(kind string) bool {
+	switch kind {
+	case repository.AIMetadataKindCheckpoint, repository.AIMetadataKindLora, repository.AIMetadataKindSampler, repository.AIMetadataKindSeed:
+		return true
+	}
+	return false
+}
+
+func imageGenerationFields(metadata *AIImageMetadata) *repository.ImageGenerationFields {
+	if metadata == nil {
+		return nil
+	}
+
+	fields := &repository.ImageGenerationFields{}
+	if checkpoint := modelBaseName(metadata.Checkpoint); checkpoint != aiMetadataUnknownCheckpoint {
+		fields.Checkpoint = checkpoint
+	}
+
+	loraIndexes := make(map[string]int)
+	addLora := func(raw string, withWeight bool) {
+		name, weight := splitLoraWeight(raw)
+		if !withWeight {
+			weight = nil
+		}
+		name = modelBaseName(name)
+		if name == "" {
+			return
+		}
+		key := strings.ToLower(name)
+		if idx, ok := loraIndexes[key]; ok {
+			if fields.Loras[idx].Weight == nil {
+				fields.Loras[idx].Weight = weight
+			}
+			return
+		}
+		loraIndexes[key] = len(fields.Loras)
+		fields.Loras = append(fields.Loras, repository.ImageLoraFields{Name: name, Weight: weight})
+	}
+
+	for _, match := range promptLoraPattern.FindAllStringSubmatch(metadata.Prompt, -1) {
+		addLora(strings.Trim(match[0], "<>")[len("lora:"):], true)
+	}
+
+	for _, item := range metadata.OtherMetadata {
+		value := ""
+		if len(item.Values) > 0 {
+			value = strings.TrimSpace(item.Values[0])
+		}
+		key := strings.ToLower(strings.TrimSpace(item.Key))
+		switch key {
+		case "sampler", "sampler_name":
+			fields.Sampler = value
+		case "seed", "noise_seed":
+			fields.Seed = value
+		case "steps":
+			fields.Steps, _ = strconv.Atoi(value)
+		case "cfg scale", "cfg", "cfg_scale", "scale":
+			fields.CFGScale, _ = strconv.ParseFloat(value, 64)
+		case "lora", "loras":
+			for _, v := range item.Values {
+				addLora(v, true)
+			}
+		case "lora hashes":
+			for _, v := range item.Values {
+				addLora(v, false)
+			}
+		}
+	}
+
+	return fields
+}
+
+func splitLoraWeight(value string) (string, *float64) {
+	value = strings.TrimSpace(value)
+	idx := strings.LastIndex(value, ":")
+	if idx < 0 {
+		return value, nil
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSpace(value[idx+1:]), 64)
+	if err != nil {
+		return strings.TrimSpace(value[:idx]), nil
+	}
+	return strings.TrimSpace(value[:idx]), &weight
+}
+
+func modelBaseName(value string) string {
+	name := modelHashSuffixPattern.ReplaceAllString(strings.TrimSpace(value), "")
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+	lower := strings.ToLower(name)
+	for _, ext := range modelFileExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return strings.TrimSpace(name[:len(name)-len(ext)])
+		}
+	}
+	return name
+}
@@ -0,0 +1,664 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+const (
+	AIMetadataKeySampler   = "Sampler"
+	AIMetadataKeyScheduler = "Scheduler"
+	AIMetadataKeySteps     = "Steps"
+	AIMetadataKeyCFGScale  = "CFG scale"
+	AIMetadataKeySeed      = "Seed"
+	AIMetadataKeySize      = "Size"
+	AIMetadataKeyDenoise   = "Denoising strength"
+	AIMetadataKeyLora      = "Lora"
+	AIMetadataKeySoftware  = "Software"
+
+	aiMetadataUnknownCheckpoint = "Unknown"
+	aiMetadataMaxTextBytes      = 4 << 20
+	comfyUIMaxLinkDepth         = 16
+)
+
+var (
+	pngSignature         = []byte("\x89PNG\r\n\x1a\n")
+	a1111ParamPattern    = regexp.MustCompile(`\s*([\w][\w \-/]*):\s*("(?:\\.|[^\\"])*"|[^,]*)(?:,|$)`)
+	a1111ParamLinePrefix = regexp.MustCompile(`^Steps:\s*\d+`)
+)
+
+func extractAIMetadata(data []byte) *AIImageMetadata {
+	texts := extractEmbeddedTexts(data)
+	if len(texts) == 0 {
+		return nil
+	}
+
+	if raw := texts["parameters"]; raw != "" {
+		if metadata := parseA1111Parameters(raw); metadata != nil {
+			return metadata
+		}
+	}
+	if raw := texts["prompt"]; raw != "" {
+		if metadata := parseComfyUIPrompt(raw); metadata != nil {
+			return metadata
+		}
+	}
+	if raw := texts["comment"]; raw != "" {
+		if metadata := parseNovelAIComment(raw, texts["source"], texts["software"]); metadata != nil {
+			return metadata
+		}
+		if metadata := parseA1111Parameters(raw); metadata != nil {
+			return metadata
+		}
+	}
+	if raw := texts["usercomment"]; raw != "" {
+		if metadata := parseA1111Parameters(raw); metadata != nil {
+			return metadata
+		}
+	}
+	return nil
+}
+
+func extractEmbeddedTexts(data []byte) map[string]string {
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		return extractPNGTexts(data)
+	case len(data) > 3 && data[0] == 0xFF && data[1] == 0xD8:
+		return extractJPEGTexts(data)
+	case len(data) > 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return extractWebPTexts(data)
+	}
+	return nil
+}
+
+func extractPNGTexts(data []byte) map[string]string {
+	texts := make(map[string]string)
+	offset := len(pngSignature)
+	for offset+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		start := offset + 8
+		end := start + length
+		if end+4 > len(data) {
+			break
+		}
+		chunk := data[start:end]
+
+		switch chunkType {
+		case "tEXt":
+			if key, value, ok := bytes.Cut(chunk, []byte{0}); ok {
+				setEmbeddedText(texts, string(key), latin1ToString(value))
+			}
+		case "zTXt":
+			if key, rest, ok := bytes.Cut(chunk, []byte{0}); ok && len(rest) > 1 && rest[0] == 0 {
+				if value, err := inflateText(rest[1:]); err == nil {
+					setEmbeddedText(texts, string(key), latin1ToString(value))
+				}
+			}
+		case "iTXt":
+			parseITXtChunk(texts, chunk)
+		case "eXIf":
+			mergeEXIFTexts(texts, chunk)
+		case "IEND":
+			return texts
+		}
+		offset = end + 4
+	}
+	return texts
+}
+
+func parseITXtChunk(texts map[string]string, chunk []byte) {
+	key, rest, ok := bytes.Cut(chunk, []byte{0})
+	if !ok || len(rest) < 2 {
+		return
+	}
+	compressed := rest[0] == 1
+	rest = rest[2:]
+	_, rest, ok = bytes.Cut(rest, []byte{0})
+	if !ok {
+		return
+	}
+	_, value, ok := bytes.Cut(rest, []byte{0})
+	if !ok {
+		return
+	}
+	if compressed {
+		inflated, err := inflateText(value)
+		if err != nil {
+			return
+		}
+		value = inflated
+	}
+	setEmbeddedText(texts, string(key), string(value))
+}
+
+func extractJPEGTexts(data []byte) map[string]string {
+	texts := make(map[string]string)
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			break
+		}
+		marker := data[offset+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 || marker == 0xFF {
+			offset++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		start := offset + 4
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		segment := data[start:end]
+
+		switch {
+		case marker == 0xFE:
+			setEmbeddedText(texts, "comment", decodeTextBytes(segment))
+		case marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")):
+			mergeEXIFTexts(texts, segment[6:])
+		}
+		offset = end
+	}
+	return texts
+}
+
+func extractWebPTexts(data []byte) map[string]string {
+	texts := make(map[string]string)
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkType := string(data[offset : offset+4])
+		length := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		start := offset + 8
+		end := start + length
+		if end > len(data) {
+			break
+		}
+		if chunkType == "EXIF" {
+			mergeEXIFTexts(texts, bytes.TrimPrefix(data[start:end], []byte("Exif\x00\x00")))
+		}
+		offset = end + length%2
+	}
+	return texts
+}
+
+func mergeEXIFTexts(texts map[string]string, payload []byte) {
+	meta, err := exif.Decode(bytes.NewReader(payload))
+	if err != nil {
+		return
+	}
+
+	if tag, err := meta.Get(exif.UserComment); err == nil {
+		setEmbeddedText(texts, "usercomment", decodeEXIFUserComment(tag.Val))
+	}
+	for _, field := range []exif.FieldName{exif.ImageDescription, exif.Make, exif.Model} {
+		tag, err := meta.Get(field)
+		if err != nil {
+			continue
+		}
+		value, err := tag.StringVal()
+		if err != nil {
+			continue
+		}
+		key, text, ok := strings.Cut(value, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "prompt", "workflow", "parameters":
+			setEmbeddedText(texts, key, text)
+		}
+	}
+}
+
+func decodeEXIFUserComment(raw []byte) string {
+	if len(raw) < 8 {
+		return decodeTextBytes(raw)
+	}
+	prefix := string(bytes.TrimRight(raw[:8], "\x00 "))
+	body := raw[8:]
+	switch strings.ToUpper(prefix) {
+	case "UNICODE":
+		return decodeUTF16Text(body)
+	case "ASCII", "":
+		return strings.TrimRight(string(body), "\x00")
+	}
+	return decodeTextBytes(raw)
+}
+
+func decodeUTF16Text(body []byte) string {
+	if len(body) < 2 {
+		return ""
+	}
+	bigEndian := true
+	switch {
+	case body[0] == 0xFE && body[1] == 0xFF:
+		body = body[2:]
+	case body[0] == 0xFF && body[1] == 0xFE:
+		bigEndian = false
+		body = body[2:]
+	default:
+		zeroEven, zeroOdd := 0, 0
+		for i := 0; i+1 < len(body) && i < 64; i += 2 {
+			if body[i] == 0 {
+				zeroEven++
+			}
+			if body[i+1] == 0 {
+				zeroOdd++
+			}
+		}
+		bigEndian = zeroEven >= zeroOdd
+	}
+
+	units := make([]uint16, 0, len(body)/2)
+	for i := 0; i+1 < len(body); i += 2 {
+		if bigEndian {
+			units = append(units, binary.BigEndian.Uint16(body[i:i+2]))
+		} else {
+			units = append(units, binary.LittleEndian.Uint16(body[i:i+2]))
+		}
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+}
+
+func decodeTextBytes(raw []byte) string {
+	return strings.TrimRight(strings.ToValidUTF8(string(raw), ""), "\x00")
+}
+
+func latin1ToString(raw []byte) string {
+	if utf8.Valid(raw) {
+		return string(raw)
+	}
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func inflateText(raw []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, aiMetadataMaxTextBytes))
+}
+
+func setEmbeddedText(texts map[string]string, key, value string) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+	if key == "" || value == "" {
+		return
+	}
+	if _, exists := texts[key]; !exists {
+		texts[key] = value
+	}
+}
+
+func parseA1111Parameters(raw string) *AIImageMetadata {
+	return parseA1111Text(raw, true)
+}
+
+func parseA1111Text(raw string, requireParams bool) *AIImageMetadata {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(raw), "\r\n", "\n"), "\n")
+	paramsLine := ""
+	if len(lines) > 0 {
+		last := strings.TrimSpace(lines[len(lines)-1])
+		if a1111ParamLinePrefix.MatchString(last) || (!requireParams && len(lines) > 1 && isA1111ParamLine(last)) {
+			paramsLine = last
+			lines = lines[:len(lines)-1]
+		}
+	}
+	if paramsLine == "" && requireParams {
+		return nil
+	}
+
+	var promptLines, negativeLines []string
+	inNegative := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Negative prompt:") {
+			inNegative = true
+			line = strings.TrimPrefix(line, "Negative prompt:")
+		}
+		if inNegative {
+			negativeLines = append(negativeLines, line)
+		} else {
+			promptLines = append(promptLines, line)
+		}
+	}
+
+	metadata := &AIImageMetadata{
+		Prompt:         strings.TrimSpace(strings.Join(promptLines, "\n")),
+		NegativePrompt: strings.TrimSpace(strings.Join(negativeLines, "\n")),
+	}
+
+	modelHash := ""
+	for _, match := range a1111ParamPattern.FindAllStringSubmatch(paramsLine, -1) {
+		key := strings.TrimSpace(match[1])
+		value := unquoteA1111Value(strings.TrimSpace(match[2]))
+		if key == "" || value == "" {
+			continue
+		}
+		switch key {
+		case "Model":
+			metadata.Checkpoint = value
+		case "Model hash":
+			modelHash = value
+			appendAIMetadataValues(metadata, key, value)
+		case "Lora hashes":
+			appendAIMetadataValues(metadata, key, splitA1111List(value)...)
+		default:
+			appendAIMetadataValues(metadata, key, value)
+		}
+	}
+	if metadata.Checkpoint == "" {
+		metadata.Checkpoint = modelHash
+	}
+
+	for _, match := range promptLoraPattern.FindAllStringSubmatch(metadata.Prompt, -1) {
+		appendAIMetadataValues(metadata, AIMetadataKeyLora, strings.Trim(match[0], "<>")[len("lora:"):])
+	}
+
+	return finalizeExtractedAIMetadata(metadata)
+}
+
+func isA1111ParamLine(line string) bool {
+	if strings.HasPrefix(line, "Negative prompt:") {
+		return false
+	}
+	matches := a1111ParamPattern.FindAllStringIndex(line, -1)
+	if len(matches) == 0 || matches[0][0] != 0 {
+		return false
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i][0] != matches[i-1][1] {
+			return false
+		}
+	}
+	return matches[len(matches)-1][1] == len(line)
+}
+
+func unquoteA1111Value(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+func splitA1111List(value string) []string {
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+type comfyUINode struct {
+	ClassType string                     `json:"class_type"`
+	Inputs    map[string]json.RawMessage `json:"inputs"`
+}
+
+func parseComfyUIPrompt(raw string) *AIImageMetadata {
+	var nodes map[string]comfyUINode
+	if err := json.Unmarshal([]byte(raw), &nodes); err != nil || len(nodes) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return ids[i] < ids[j]
+	})
+
+	metadata := &AIImageMetadata{}
+	samplerFound := false
+	for _, id := range ids {
+		node := nodes[id]
+		switch {
+		case strings.HasPrefix(node.ClassType, "CheckpointLoader"), node.ClassType == "UNETLoader":
+			if metadata.Checkpoint == "" {
+				metadata.Checkpoint = firstComfyUIString(node, "ckpt_name", "unet_name")
+			}
+		case strings.HasPrefix(node.ClassType, "LoraLoader"):
+			name := comfyUIString(node, "lora_name")
+			if name == "" {
+				continue
+			}
+			if strength := comfyUIScalar(node, "strength_model"); strength != "" {
+				name += ":" + strength
+			}
+			appendAIMetadataValues(metadata, AIMetadataKeyLora, name)
+		case strings.HasPrefix(node.ClassType, "KSampler") || node.ClassType == "SamplerCustom":
+			if samplerFound {
+				continue
+			}
+			samplerFound = true
+			metadata.Prompt = resolveComfyUIText(nodes, node.Inputs["positive"], 0)
+			metadata.NegativePrompt = resolveComfyUIText(nodes, node.Inputs["negative"], 0)
+			appendAIMetadataValues(metadata, AIMetadataKeySteps, comfyUIScalar(node, "steps"))
+			appendAIMetadataValues(metadata, AIMetadataKeySampler, comfyUIScalar(node, "sampler_name"))
+			appendAIMetadataValues(metadata, AIMetadataKeyScheduler, comfyUIScalar(node, "scheduler"))
+			appendAIMetadataValues(metadata, AIMetadataKeyCFGScale, comfyUIScalar(node, "cfg"))
+			seed := comfyUIScalar(node, "seed")
+			if seed == "" {
+				seed = comfyUIScalar(node, "noise_seed")
+			}
+			appendAIMetadataValues(metadata, AIMetadataKeySeed, seed)
+			if denoise := comfyUIScalar(node, "denoise"); denoise != "" && denoise != "1" {
+				appendAIMetadataValues(metadata, AIMetadataKeyDenoise, denoise)
+			}
+		case node.ClassType == "EmptyLatentImage":
+			width, height := comfyUIScalar(node, "width"), comfyUIScalar(node, "height")
+			if width != "" && height != "" {
+				appendAIMetadataValues(metadata, AIMetadataKeySize, width+"x"+height)
+			}
+		}
+	}
+
+	if metadata.Prompt == "" {
+		for _, id := range ids {
+			if strings.HasPrefix(nodes[id].ClassType, "CLIPTextEncode") {
+				metadata.Prompt = comfyUINodeText(nodes[id])
+				break
+			}
+		}
+	}
+	appendAIMetadataValues(metadata, AIMetadataKeySoftware, "ComfyUI")
+
+	return finalizeExtractedAIMetadata(metadata)
+}
+
+func resolveComfyUIText(nodes map[string]comfyUINode, link json.RawMessage, depth int) string {
+	if len(link) == 0 || depth > comfyUIMaxLinkDepth {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(link, &text); err == nil {
+		return text
+	}
+
+	var ref []json.RawMessage
+	if err := json.Unmarshal(link, &ref); err != nil || len(ref) == 0 {
+		return ""
+	}
+	var id string
+	if err := json.Unmarshal(ref[0], &id); err != nil {
+		var numeric json.Number
+		if err := json.Unmarshal(ref[0], &numeric); err != nil {
+			return ""
+		}
+		id = numeric.String()
+	}
+
+	node, ok := nodes[id]
+	if !ok {
+		return ""
+	}
+	if text := comfyUINodeText(node); text != "" {
+		return text
+	}
+	for _, key := range []string{"text", "conditioning", "conditioning_1", "conditioning_to", "positive", "string", "value"} {
+		if text := resolveComfyUIText(nodes, node.Inputs[key], depth+1); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+func comfyUINodeText(node comfyUINode) string {
+	if text := comfyUIString(node, "text"); text != "" {
+		return text
+	}
+	textG, textL := comfyUIString(node, "text_g"), comfyUIString(node, "text_l")
+	if textG != "" && textL != "" && textG != textL {
+		return textG + "\n" + textL
+	}
+	if textG != "" {
+		return textG
+	}
+	return textL
+}
+
+func firstComfyUIString(node comfyUINode, keys ...string) string {
+	for _, key := range keys {
+		if value := comfyUIString(node, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func comfyUIString(node comfyUINode, key string) string {
+	var value string
+	if err := json.Unmarshal(node.Inputs[key], &value); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+func comfyUIScalar(node comfyUINode, key string) string {
+	raw, ok := node.Inputs[key]
+	if !ok {
+		return ""
+	}
+	if value := comfyUIString(node, key); value != "" {
+		return value
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err != nil {
+		return ""
+	}
+	return number.String()
+}
+
+type novelAIComment struct {
+	Prompt         string          `json:"prompt"`
+	NegativePrompt string          `json:"uc"`
+	Steps          json.Number     `json:"steps"`
+	Scale          json.Number     `json:"scale"`
+	Seed           json.Number     `json:"seed"`
+	Sampler        string          `json:"sampler"`
+	Width          json.Number     `json:"width"`
+	Height         json.Number     `json:"height"`
+	Strength       json.Number     `json:"strength"`
+	V4Prompt       *novelAIV4Block `json:"v4_prompt"`
+}
+
+type novelAIV4Block struct {
+	Caption struct {
+		BaseCaption string `json:"base_caption"`
+	} `json:"caption"`
+}
+
+func parseNovelAIComment(raw, source, software string) *AIImageMetadata {
+	var comment novelAIComment
+	if err := json.Unmarshal([]byte(raw), &comment); err != nil {
+		return nil
+	}
+
+	prompt := comment.Prompt
+	if prompt == "" && comment.V4Prompt != nil {
+		prompt = comment.V4Prompt.Caption.BaseCaption
+	}
+
+	metadata := &AIImageMetadata{
+		Checkpoint:     source,
+		Prompt:         prompt,
+		NegativePrompt: comment.NegativePrompt,
+	}
+	appendAIMetadataValues(metadata, AIMetadataKeySteps, comment.Steps.String())
+	appendAIMetadataValues(metadata, AIMetadataKeySampler, comment.Sampler)
+	appendAIMetadataValues(metadata, AIMetadataKeyCFGScale, comment.Scale.String())
+	appendAIMetadataValues(metadata, AIMetadataKeySeed, comment.Seed.String())
+	if comment.Width != "" && comment.Height != "" {
+		appendAIMetadataValues(metadata, AIMetadataKeySize, fmt.Sprintf("%sx%s", comment.Width, comment.Height))
+	}
+	if software == "" {
+		software = "NovelAI"
+	}
+	appendAIMetadataValues(metadata, AIMetadataKeySoftware, software)
+
+	return finalizeExtractedAIMetadata(metadata)
+}
+
+func appendAIMetadataValues(metadata *AIImageMetadata, key string, values ...string) {
+	filtered := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			filtered = append(filtered, value)
+		}
+	}
+	if len(filtered) == 0 {
+		return
+	}
+	for i := range metadata.OtherMetadata {
+		if metadata.OtherMetadata[i].Key == key {
+			metadata.OtherMetadata[i].Values = append(metadata.OtherMetadata[i].Values, filtered...)
+			return
+		}
+	}
+	metadata.OtherMetadata = append(metadata.OtherMetadata, AIImageMetadataKeyValue{Key: key, Values: filtered})
+}
+
+func finalizeExtractedAIMetadata(metadata *AIImageMetadata) *AIImageMetadata {
+	metadata.Prompt = strings.TrimSpace(metadata.Prompt)
+	metadata.NegativePrompt = strings.TrimSpace(metadata.NegativePrompt)
+	metadata.Checkpoint = strings.TrimSpace(metadata.Checkpoint)
+	if metadata.Prompt == "" {
+		return nil
+	}
+	if metadata.Checkpoint == "" {
+		metadata.Checkpoint = aiMetadataUnknownCheckpoint
+	}
+	return metadata
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	AIMetadataFormatA1111   = "a1111"
+	AIMetadataFormatCivitai = "civitai"
+
+	aiMetadataMaxImportBytes = 1 << 20
+)
+
+var (
+	a1111KeyPattern     = regexp.MustCompile(`^[\w][\w \-/]*$`)
+	civitaiKeyMapping   = map[string]string{"steps": AIMetadataKeySteps, "sampler": AIMetadataKeySampler, "cfgScale": AIMetadataKeyCFGScale, "seed": AIMetadataKeySeed}
+	civitaiKeyOrder     = []string{"steps", "sampler", "cfgScale", "seed"}
+	civitaiLoraTypes    = map[string]struct{}{"lora": {}, "locon": {}, "lycoris": {}, "dora": {}}
+	civitaiModelTypes   = map[string]struct{}{"model": {}, "checkpoint": {}}
+	civitaiReservedKeys = map[string]struct{}{"prompt": {}, "negativePrompt": {}, "Model": {}, "model": {}, "resources": {}}
+)
+
+type civitaiResource struct {
+	Type   string   `json:"type"`
+	Name   string   `json:"name"`
+	Weight *float64 `json:"weight,omitempty"`
+}
+
+func formatAIMetadata(metadata *AIImageMetadata, format string) ([]byte, string, string, error) {
+	switch format {
+	case "", AIMetadataFormatA1111:
+		return []byte(formatA1111Parameters(metadata)), "text/plain; charset=utf-8", ".txt", nil
+	case AIMetadataFormatCivitai:
+		payload, err := json.MarshalIndent(formatCivitaiGeneration(metadata), "", "  ")
+		if err != nil {
+			return nil, "", "", err
+		}
+		return payload, "application/json; charset=utf-8", ".json", nil
+	}
+	return nil, "", "", ErrAIMetadataFormatUnsupported
+}
+
+func parseAIMetadataImport(format, content string) (*AIImageMetadata, error) {
+	content = strings.TrimSpace(strings.TrimPrefix(content, "\ufeff"))
+	if content == "" {
+		return nil, ErrAIMetadataImportInvalid
+	}
+	if format == "" {
+		format = AIMetadataFormatA1111
+		if strings.HasPrefix(content, "{") {
+			format = AIMetadataFormatCivitai
+		}
+	}
+
+	var metadata *AIImageMetadata
+	switch format {
+	case AIMetadataFormatA1111:
+		metadata = parseA1111Text(content, false)
+	case AIMetadataFormatCivitai:
+		metadata = parseCivitaiGeneration(content)
+	default:
+		return nil, ErrAIMetadataFormatUnsupported
+	}
+	if metadata == nil {
+		return nil, ErrAIMetadataImportInvalid
+	}
+	return metadata, nil
+}
+
+func formatA1111Parameters(metadata *AIImageMetadata) string {
+	var builder strings.Builder
+	builder.WriteString(strings.TrimSpace(metadata.Prompt))
+	if negative := strings.TrimSpace(metadata.NegativePrompt); negative != "" {
+		builder.WriteString("\nNegative prompt: ")
+		builder.WriteString(negative)
+	}
+
+	promptLoras := make(map[string]struct{})
+	for _, match := range promptLoraPattern.FindAllStringSubmatch(metadata.Prompt, -1) {
+		promptLoras[strings.ToLower(strings.Trim(match[0], "<>")[len("lora:"):])] = struct{}{}
+	}
+
+	var steps []string
+	params := make([]string, 0, len(metadata.OtherMetadata)+1)
+	modelHash := ""
+	for _, item := range metadata.OtherMetadata {
+		key := strings.TrimSpace(item.Key)
+		if key == "Model" || !a1111KeyPattern.MatchString(key) {
+			continue
+		}
+		values := make([]string, 0, len(item.Values))
+		for _, value := range item.Values {
+			if value = strings.TrimSpace(value); value == "" {
+				continue
+			}
+			if key == AIMetadataKeyLora {
+				if _, ok := promptLoras[strings.ToLower(value)]; ok {
+					continue
+				}
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		switch key {
+		case AIMetadataKeySteps:
+			for _, value := range values {
+				steps = append(steps, key+": "+quoteA1111Value(value))
+			}
+		case "Lora hashes":
+			params = append(params, key+": "+quoteA1111Value(strings.Join(values, ", ")))
+		default:
+			if key == "Model hash" {
+				modelHash = values[0]
+			}
+			for _, value := range values {
+				params = append(params, key+": "+quoteA1111Value(value))
+			}
+		}
+	}
+	if checkpoint := strings.TrimSpace(metadata.Checkpoint); checkpoint != "" && checkpoint != aiMetadataUnknownCheckpoint && checkpoint != modelHash {
+		params = append(params, "Model: "+quoteA1111Value(checkpoint))
+	}
+
+	if params = append(steps, params...); len(params) > 0 {
+		builder.WriteString("\n")
+		builder.WriteString(strings.Join(params, ", "))
+	}
+	return builder.String()
+}
+
+func quoteA1111Value(value string) string {
+	if strings.ContainsAny(value, ",:\n\"") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func formatCivitaiGeneration(metadata *AIImageMetadata) map[string]interface{} {
+	data := map[string]interface{}{"prompt": strings.TrimSpace(metadata.Prompt)}
+	if negative := strings.TrimSpace(metadata.NegativePrompt); negative != "" {
+		data["negativePrompt"] = negative
+	}
+	if checkpoint := strings.TrimSpace(metadata.Checkpoint); checkpoint != "" && checkpoint != aiMetadataUnknownCheckpoint {
+		data["Model"] = checkpoint
+	}
+
+	reverseMapping := make(map[string]string, len(civitaiKeyMapping))
+	for civitaiKey, key := range civitaiKeyMapping {
+		reverseMapping[key] = civitaiKey
+	}
+
+	resources := make([]civitaiResource, 0)
+	for _, item := range metadata.OtherMetadata {
+		key := strings.TrimSpace(item.Key)
+		if key == "" || len(item.Values) == 0 {
+			continue
+		}
+		if key == AIMetadataKeyLora {
+			for _, value := range item.Values {
+				name, weight := splitLoraWeight(value)
+				if name != "" {
+					resources = append(resources, civitaiResource{Type: "lora", Name: name, Weight: weight})
+				}
+			}
+			continue
+		}
+
+		civitaiKey, mapped := reverseMapping[key]
+		if !mapped {
+			civitaiKey = key
+		}
+		if _, reserved := civitaiReservedKeys[civitaiKey]; reserved {
+			continue
+		}
+		if len(item.Values) > 1 {
+			data[civitaiKey] = item.Values
+			continue
+		}
+		value := strings.TrimSpace(item.Values[0])
+		if _, err := strconv.ParseFloat(value, 64); mapped && err == nil && json.Valid([]byte(value)) {
+			data[civitaiKey] = json.Number(value)
+		} else {
+			data[civitaiKey] = value
+		}
+	}
+	if len(resources) > 0 {
+		data["resources"] = resources
+	}
+	return data
+}
+
+func parseCivitaiGeneration(raw string) *AIImageMetadata {
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil
+	}
+	if _, native := data["checkpoint"]; native {
+		if metadata := parseAIMetadata(raw); metadata != nil {
+			return finalizeExtractedAIMetadata(metadata)
+		}
+		return nil
+	}
+	if _, ok := data["prompt"]; !ok {
+		if meta, ok := data["meta"].(map[string]interface{}); ok {
+			data = meta
+		}
+	}
+
+	metadata := &AIImageMetadata{
+		Prompt:         civitaiString(data["prompt"]),
+		NegativePrompt: civitaiString(data["negativePrompt"]),
+		Checkpoint:     civitaiString(data["Model"]),
+	}
+	if metadata.Checkpoint == "" {
+		metadata.Checkpoint = civitaiString(data["model"])
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if _, mapped := civitaiKeyMapping[key]; mapped {
+			continue
+		}
+		if _, reserved := civitaiReservedKeys[key]; !reserved {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range append(append([]string{}, civitaiKeyOrder...), keys...) {
+		value, ok := data[key]
+		if !ok {
+			continue
+		}
+		name := key
+		if mapped, ok := civitaiKeyMapping[key]; ok {
+			name = mapped
+		}
+		values := civitaiValues(value)
+		if name == "Lora hashes" && len(values) == 1 {
+			values = splitA1111List(values[0])
+		}
+		appendAIMetadataValues(metadata, name, values...)
+	}
+
+	loras := make(map[string]struct{})
+	if resources, ok := data["resources"].([]interface{}); ok {
+		for _, item := range resources {
+			resource, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name := civitaiString(resource["name"])
+			resourceType := strings.ToLower(civitaiString(resource["type"]))
+			if name == "" {
+				continue
+			}
+			if _, ok := civitaiModelTypes[resourceType]; ok && metadata.Checkpoint == "" {
+				metadata.Checkpoint = name
+				continue
+			}
+			if _, ok := civitaiLoraTypes[resourceType]; !ok {
+				continue
+			}
+			if weight := civitaiString(resource["weight"]); weight != "" {
+				name += ":" + weight
+			}
+			loras[strings.ToLower(name)] = struct{}{}
+			appendAIMetadataValues(metadata, AIMetadataKeyLora, name)
+		}
+	}
+	if metadata.Checkpoint == "" {
+		metadata.Checkpoint = civitaiString(data["Model hash"])
+	}
+	for _, match := range promptLoraPattern.FindAllStringSubmatch(metadata.Prompt, -1) {
+		lora := strings.Trim(match[0], "<>")[len("lora:"):]
+		if _, exists := loras[strings.ToLower(lora)]; !exists {
+			loras[strings.ToLower(lora)] = struct{}{}
+			appendAIMetadataValues(metadata, AIMetadataKeyLora, lora)
+		}
+	}
+
+	return finalizeExtractedAIMetadata(metadata)
+}
+
+func civitaiValues(value interface{}) []string {
+	if items, ok := value.([]interface{}); ok {
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			values = append(values, civitaiString(item))
+		}
+		return values
+	}
+	return []string{civitaiString(value)}
+}
+
+func civitaiString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(payload)
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAIMetadataA1111RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *AIImageMetadata
+	}{
+		{
+			name: "full parameters",
+			metadata: &AIImageMetadata{
+				Checkpoint:     "animagine-xl-3.1",
+				Prompt:         "1girl, solo, looking at viewer",
+				NegativePrompt: "lowres, bad anatomy",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySteps, Values: []string{"28"}},
+					{Key: AIMetadataKeySampler, Values: []string{"DPM++ 2M Karras"}},
+					{Key: AIMetadataKeyCFGScale, Values: []string{"7"}},
+					{Key: AIMetadataKeySeed, Values: []string{"123456789"}},
+					{Key: AIMetadataKeySize, Values: []string{"832x1216"}},
+				},
+			},
+		},
+		{
+			name: "multi-line negative prompt",
+			metadata: &AIImageMetadata{
+				Checkpoint:     "anything-v5",
+				Prompt:         "masterpiece, best quality,\nscenery, sunset",
+				NegativePrompt: "lowres, worst quality,\nextra fingers,\ntext, watermark",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySteps, Values: []string{"20"}},
+					{Key: AIMetadataKeySampler, Values: []string{"Euler a"}},
+				},
+			},
+		},
+		{
+			name: "loras in prompt",
+			metadata: &AIImageMetadata{
+				Checkpoint: "ponyDiffusionV6XL",
+				Prompt:     "1girl, <lora:detail_tweaker:0.8>, smile, <lora:flat_color:1>",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySteps, Values: []string{"30"}},
+					{Key: "Lora hashes", Values: []string{"detail_tweaker: 1a2b3c4d5e6f", "flat_color: 0f1e2d3c4b5a"}},
+					{Key: AIMetadataKeyLora, Values: []string{"detail_tweaker:0.8", "flat_color:1"}},
+				},
+			},
+		},
+		{
+			name: "missing steps",
+			metadata: &AIImageMetadata{
+				Checkpoint: "sd_xl_base_1.0",
+				Prompt:     "a cat sitting on a windowsill",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySampler, Values: []string{"DDIM"}},
+					{Key: AIMetadataKeySeed, Values: []string{"42"}},
+				},
+			},
+		},
+		{
+			name: "quoted values",
+			metadata: &AIImageMetadata{
+				Checkpoint: "model, with: punctuation",
+				Prompt:     "landscape",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySteps, Values: []string{"25"}},
+					{Key: "Hires upscaler", Values: []string{`4x "UltraSharp", v2`}},
+				},
+			},
+		},
+		{
+			name: "prompt only",
+			metadata: &AIImageMetadata{
+				Checkpoint: aiMetadataUnknownCheckpoint,
+				Prompt:     "portrait of a knight",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := formatA1111Parameters(tt.metadata)
+			parsed, err := parseAIMetadataImport(AIMetadataFormatA1111, text)
+			if err != nil {
+				t.Fatalf("parseAIMetadataImport(%q) error = %v", text, err)
+			}
+			if !reflect.DeepEqual(parsed, tt.metadata) {
+				t.Fatalf("round trip mismatch\ntext:\n%s\ngot:  %#v\nwant: %#v", text, parsed, tt.metadata)
+			}
+			if again := formatA1111Parameters(parsed); again != text {
+				t.Fatalf("second format differs\nfirst:\n%s\nsecond:\n%s", text, again)
+			}
+		})
+	}
+}
+
+func TestAIMetadataA1111MissingStepsLayout(t *testing.T) {
+	text := formatA1111Parameters(&AIImageMetadata{
+		Checkpoint:     "sd_xl_base_1.0",
+		Prompt:         "a cat",
+		NegativePrompt: "blurry",
+		OtherMetadata: []AIImageMetadataKeyValue{
+			{Key: AIMetadataKeySampler, Values: []string{"DDIM"}},
+		},
+	})
+	want := "a cat\nNegative prompt: blurry\nSampler: DDIM, Model: sd_xl_base_1.0"
+	if text != want {
+		t.Fatalf("formatA1111Parameters() = %q, want %q", text, want)
+	}
+}
+
+func TestAIMetadataCivitaiRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *AIImageMetadata
+	}{
+		{
+			name: "mapped parameters",
+			metadata: &AIImageMetadata{
+				Checkpoint:     "animagine-xl-3.1",
+				Prompt:         "1girl, solo",
+				NegativePrompt: "lowres,\nbad hands",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySteps, Values: []string{"28"}},
+					{Key: AIMetadataKeySampler, Values: []string{"Euler a"}},
+					{Key: AIMetadataKeyCFGScale, Values: []string{"6.5"}},
+					{Key: AIMetadataKeySeed, Values: []string{"987654321"}},
+					{Key: "Clip skip", Values: []string{"2"}},
+				},
+			},
+		},
+		{
+			name: "loras in prompt",
+			metadata: &AIImageMetadata{
+				Checkpoint: "ponyDiffusionV6XL",
+				Prompt:     "1girl, <lora:detail_tweaker:0.8>",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySteps, Values: []string{"30"}},
+					{Key: AIMetadataKeyLora, Values: []string{"detail_tweaker:0.8"}},
+				},
+			},
+		},
+		{
+			name: "missing steps",
+			metadata: &AIImageMetadata{
+				Checkpoint: "sd_xl_base_1.0",
+				Prompt:     "a cat sitting on a windowsill",
+				OtherMetadata: []AIImageMetadataKeyValue{
+					{Key: AIMetadataKeySampler, Values: []string{"DDIM"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := json.Marshal(formatCivitaiGeneration(tt.metadata))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			for _, format := range []string{AIMetadataFormatCivitai, ""} {
+				parsed, err := parseAIMetadataImport(format, string(payload))
+				if err != nil {
+					t.Fatalf("parseAIMetadataImport(%q, %s) error = %v", format, payload, err)
+				}
+				if !reflect.DeepEqual(parsed, tt.metadata) {
+					t.Fatalf("round trip mismatch for format %q\njson: %s\ngot:  %#v\nwant: %#v", format, payload, parsed, tt.metadata)
+				}
+			}
+		})
+	}
+}
+
+func TestParseAIMetadataImportInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		wantErr error
+	}{
+		{name: "empty", content: "  \n", wantErr: ErrAIMetadataImportInvalid},
+		{name: "bom only", content: "\ufeff", wantErr: ErrAIMetadataImportInvalid},
+		{name: "civitai without prompt", format: AIMetadataFormatCivitai, content: `{"steps": 20}`, wantErr: ErrAIMetadataImportInvalid},
+		{name: "malformed json", format: AIMetadataFormatCivitai, content: `{"prompt": `, wantErr: ErrAIMetadataImportInvalid},
+		{name: "unknown format", format: "novelai", content: "prompt", wantErr: ErrAIMetadataFormatUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseAIMetadataImport(tt.format, tt.content); err != tt.wantErr {
+				t.Fatalf("parseAIMetadataImport() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAIMetadataImportStripsBOMAndCRLF(t *testing.T) {
+	content := "\ufeffa cat\r\nNegative prompt: blurry\r\nSteps: 20, Sampler: Euler a, Model: sd15"
+	parsed, err := parseAIMetadataImport("", content)
+	if err != nil {
+		t.Fatalf("parseAIMetadataImport() error = %v", err)
+	}
+	if parsed.Prompt != "a cat" || parsed.NegativePrompt != "blurry" || parsed.Checkpoint != "sd15" {
+		t.Fatalf("parseAIMetadataImport() = %#v", parsed)
+	}
+	if strings.Contains(formatA1111Parameters(parsed), "\r") {
+		t.Fatalf("formatted parameters kept carriage returns")
+	}
+}
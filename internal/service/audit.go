@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/json"
+	"illust-nest/internal/model"
+	"illust-nest/internal/repository"
+	"log"
+	"reflect"
+	"sort"
+)
+
+const (
+	AuditEntityWork            = "work"
+	AuditEntityWorkImages      = "work_images"
+	AuditEntityWorkImage       = "work_image"
+	AuditEntityWorkCollections = "work_collections"
+	AuditEntityCollection      = "collection"
+)
+
+type AuditService struct {
+	auditRepo *repository.AuditLogRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+type workAuditSnapshot struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Rating      int    `json:"rating"`
+	IsPublic    bool   `json:"is_public"`
+	TagIDs      []uint `json:"tag_ids"`
+}
+
+type workImagesAuditSnapshot struct {
+	ImageIDs []uint `json:"image_ids"`
+}
+
+type imageAIMetadataAuditSnapshot struct {
+	AIMetadata *AIImageMetadata `json:"ai_metadata"`
+}
+
+type workCollectionsAuditSnapshot struct {
+	CollectionIDs []uint `json:"collection_ids"`
+}
+
+type collectionAuditSnapshot struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type collectionOrderAuditSnapshot struct {
+	CollectionIDs []uint `json:"collection_ids,omitempty"`
+	WorkIDs       []uint `json:"work_ids,omitempty"`
+}
+
+func newWorkAuditSnapshot(work *model.Work) *workAuditSnapshot {
+	tagIDs := make([]uint, 0, len(work.Tags))
+	for _, tag := range work.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	sort.Slice(tagIDs, func(i, j int) bool { return tagIDs[i] < tagIDs[j] })
+	return &workAuditSnapshot{
+		Title:       work.Title,
+		Description: work.Description,
+		Rating:      work.Rating,
+		IsPublic:    work.IsPublic,
+		TagIDs:      tagIDs,
+	}
+}
+
+func (s *AuditService) Record(actor *Actor, action, entityType string, entityID, workID uint, before, after interface{}) {
+	if s == nil || s.auditRepo == nil {
+		return
+	}
+
+	entry := &model.AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		WorkID:     workID,
+		Before:     marshalAuditSnapshot(before),
+		After:      marshalAuditSnapshot(after),
+	}
+	if actor != nil {
+		entry.UserID = actor.UserID
+		entry.Username = actor.Username
+	}
+	if entry.Before != "" && entry.Before == entry.After {
+		return
+	}
+
+	if err := s.auditRepo.Create(entry); err != nil {
+		log.Printf("Failed to record audit log %s for %s %d: %v", action, entityType, entityID, err)
+	}
+}
+
+func (s *AuditService) GetWorkHistory(workID uint, page, pageSize int) (*AuditLogPagedResult, error) {
+	entries, total, err := s.auditRepo.FindByWorkID(workID, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*AuditLogInfo, 0, len(entries))
+	for i := range entries {
+		items = append(items, auditLogToInfo(&entries[i]))
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	return &AuditLogPagedResult{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *AuditService) FindByID(id uint) (*model.AuditLog, error) {
+	return s.auditRepo.FindByID(id)
+}
+
+func marshalAuditSnapshot(snapshot interface{}) string {
+	if snapshot == nil {
+		return ""
+	}
+	value := reflect.ValueOf(snapshot)
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return ""
+	}
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return ""
+	}
+	return string(payload)
+}
+
+func auditLogToInfo(entry *model.AuditLog) *AuditLogInfo {
+	info := &AuditLogInfo{
+		ID:         entry.ID,
+		UserID:     entry.UserID,
+		Username:   entry.Username,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		WorkID:     entry.WorkID,
+		Changes:    diffAuditSnapshots(entry.Before, entry.After),
+		CreatedAt:  entry.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if entry.Before != "" {
+		info.Before = json.RawMessage(entry.Before)
+	}
+	if entry.After != "" {
+		info.After = json.RawMessage(entry.After)
+	}
+	return info
+}
+
+func diffAuditSnapshots(before, after string) []AuditFieldChange {
+	beforeFields := make(map[string]interface{})
+	afterFields := make(map[string]interface{})
+	if before != "" {
+		_ = json.Unmarshal([]byte(before), &beforeFields)
+	}
+	if after != "" {
+		_ = json.Unmarshal([]byte(after), &afterFields)
+	}
+
+	keys := make([]string, 0, len(beforeFields)+len(afterFields))
+	seen := make(map[string]struct{})
+	for key := range beforeFields {
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	for key := range afterFields {
+		if _, ok := seen[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]AuditFieldChange, 0)
+	for _, key := range keys {
+		if reflect.DeepEqual(beforeFields[key], afterFields[key]) {
+			continue
+		}
+		changes = append(changes, AuditFieldChange{
+			Field:  key,
+			Before: beforeFields[key],
+			After:  afterFields[key],
+		})
+	}
+	return changes
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"errors"
+	"illust-nest/internal/model"
+	"illust-nest/internal/repository"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const AutoTagMatchAIMetadata = "ai_metadata"
+
+var (
+	promptLoraPattern      = regexp.MustCompile(`(?i)<lora:([^:>]+)(?::[^>]*)?>`)
+	promptExtraNetPattern  = regexp.MustCompile(`<[^>]*>`)
+	promptSeparatorPattern = regexp.MustCompile(`[,\n]|\bBREAK\b`)
+	weightSuffixPattern    = regexp.MustCompile(`:\s*-?[0-9]*\.?[0-9]+$`)
+	modelHashSuffixPattern = regexp.MustCompile(`\s*\[[0-9a-fA-F]+\]$`)
+	modelFileExtensions    = []string{".safetensors", ".ckpt", ".pt", ".pth", ".bin"}
+	escapedParenEncoder    = strings.NewReplacer("\\(", "\x00", "\\)", "\x01")
+	escapedParenDecoder    = strings.NewReplacer("\x00", "(", "\x01", ")")
+	tagTextReplacer        = strings.NewReplacer("_", " ", "\\(", "(", "\\)", ")")
+)
+
+type AutoTagService struct {
+	ruleRepo *repository.AutoTagRuleRepository
+	tagRepo  *repository.TagRepository
+	workRepo *repository.WorkRepository
+}
+
+func NewAutoTagService(ruleRepo *repository.AutoTagRuleRepository, tagRepo *repository.TagRepository, workRepo *repository.WorkRepository) *AutoTagService {
+	return &AutoTagService{
+		ruleRepo: ruleRepo,
+		tagRepo:  tagRepo,
+		workRepo: workRepo,
+	}
+}
+
+func (s *AutoTagService) GetRules() ([]*AutoTagRuleInfo, error) {
+	rules, err := s.ruleRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := make([]uint, 0, len(rules))
+	for _, rule := range rules {
+		tagIDs = append(tagIDs, rule.TagID)
+	}
+	tags, err := s.tagRepo.FindByIDs(uniqueIDs(tagIDs))
+	if err != nil {
+		return nil, err
+	}
+	tagsByID := make(map[uint]*model.Tag, len(tags))
+	for i := range tags {
+		tagsByID[tags[i].ID] = &tags[i]
+	}
+
+	items := make([]*AutoTagRuleInfo, 0, len(rules))
+	for i := range rules {
+		items = append(items, autoTagRuleToInfo(&rules[i], tagsByID[rules[i].TagID]))
+	}
+	return items, nil
+}
+
+func (s *AutoTagService) CreateRule(req *AutoTagRuleRequest) (*AutoTagRuleInfo, error) {
+	rule := &model.AutoTagRule{Enabled: true}
+	tag, err := s.applyRuleRequest(rule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return autoTagRuleToInfo(rule, tag), nil
+}
+
+func (s *AutoTagService) UpdateRule(id uint, req *AutoTagRuleRequest) (*AutoTagRuleInfo, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAutoTagRuleNotFound
+		}
+		return nil, err
+	}
+
+	tag, err := s.applyRuleRequest(rule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return autoTagRuleToInfo(rule, tag), nil
+}
+
+func (s *AutoTagService) DeleteRule(id uint) error {
+	deleted, err := s.ruleRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrAutoTagRuleNotFound
+	}
+	return nil
+}
+
+func (s *AutoTagService) applyRuleRequest(rule *model.AutoTagRule, req *AutoTagRuleRequest) (*model.Tag, error) {
+	matchType := strings.ToLower(strings.TrimSpace(req.MatchType))
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern == "" {
+		return nil, &ValidationError{Message: "Rule pattern is required", Code: 1001}
+	}
+
+	switch matchType {
+	case model.AutoTagMatchPromptToken, model.AutoTagMatchCheckpoint, model.AutoTagMatchLora:
+	case model.AutoTagMatchRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, &ValidationError{Message: "Invalid regular expression: " + err.Error(), Code: 1001}
+		}
+	default:
+		return nil, &ValidationError{Message: "Unsupported match type: " + req.MatchType, Code: 1001}
+	}
+
+	tag, err := s.tagRepo.FindByID(req.TagID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTagNotFound) {
+			return nil, &ValidationError{Message: "tag not found", Code: 1002}
+		}
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = pattern
+	}
+
+	rule.Name = name
+	rule.MatchType = matchType
+	rule.Pattern = pattern
+	rule.TagID = tag.ID
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return tag, nil
+}
+
+func (s *AutoTagService) Preview(req *AutoTagPreviewRequest) (*AutoTagPreviewResult, error) {
+	var metadataList []*AIImageMetadata
+	existing := make(map[uint]struct{})
+
+	switch {
+	case req.WorkID != nil:
+		work, err := s.workRepo.FindByID(*req.WorkID, true)
+		if err != nil {
+			return nil, ErrWorkNotFound
+		}
+		for _, img := range work.Images {
+			metadataList = append(metadataList, parseAIMetadata(img.AIMetadata))
+		}
+		for _, tag := range work.Tags {
+			existing[tag.ID] = struct{}{}
+		}
+	case req.AIMetadata != nil:
+		metadataList = append(metadataList, req.AIMetadata)
+	default:
+		return nil, &ValidationError{Message: "work_id or ai_metadata is required", Code: 1001}
+	}
+
+	matches, err := s.Evaluate(metadataList)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		tagIDs = append(tagIDs, match.TagID)
+	}
+	tagIDs = uniqueIDs(tagIDs)
+
+	tags, err := s.tagRepo.FindByIDs(tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AutoTagPreviewResult{
+		Matches:   matches,
+		Tags:      make([]*model.Tag, 0, len(tags)),
+		NewTagIDs: make([]uint, 0, len(tags)),
+	}
+	for i := range tags {
+		result.Tags = append(result.Tags, &tags[i])
+		if _, ok := existing[tags[i].ID]; !ok {
+			result.NewTagIDs = append(result.NewTagIDs, tags[i].ID)
+		}
+	}
+	return result, nil
+}
+
+func (s *AutoTagService) ResolveTagIDs(metadataList []*AIImageMetadata) ([]uint, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	matches, err := s.Evaluate(metadataList)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		tagIDs = append(tagIDs, match.TagID)
+	}
+	return uniqueIDs(tagIDs), nil
+}
+
+func (s *AutoTagService) Evaluate(metadataList []*AIImageMetadata) ([]AutoTagMatch, error) {
+	matches := make([]AutoTagMatch, 0)
+
+	var present []*AIImageMetadata
+	for _, metadata := range metadataList {
+		if metadata != nil {
+			present = append(present, metadata)
+		}
+	}
+	if len(present) == 0 {
+		return matches, nil
+	}
+
+	aiTag, err := s.tagRepo.FindByName("AI")
+	if err != nil && !errors.Is(err, repository.ErrTagNotFound) {
+		return nil, err
+	}
+	if aiTag != nil && aiTag.IsSystem {
+		matches = append(matches, AutoTagMatch{
+			RuleName:  aiTag.Name,
+			MatchType: AutoTagMatchAIMetadata,
+			TagID:     aiTag.ID,
+		})
+	}
+
+	rules, err := s.ruleRepo.FindEnabled()
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return matches, nil
+	}
+
+	patterns := compileAutoTagRulePatterns(rules)
+	for _, metadata := range present {
+		tokens := promptTokens(metadata.Prompt)
+		checkpoint := normalizeModelName(metadata.Checkpoint)
+		loras := loraNames(metadata)
+
+		for i := range rules {
+			matched, ok := matchAutoTagRule(&rules[i], patterns[i], metadata.Prompt, tokens, checkpoint, loras)
+			if !ok {
+				continue
+			}
+			matches = append(matches, AutoTagMatch{
+				RuleID:    rules[i].ID,
+				RuleName:  rules[i].Name,
+				MatchType: rules[i].MatchType,
+				Matched:   matched,
+				TagID:     rules[i].TagID,
+			})
+		}
+	}
+
+	return dedupeAutoTagMatches(matches), nil
+}
+
+func compileAutoTagRulePatterns(rules []model.AutoTagRule) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(rules))
+	for i := range rules {
+		if rules[i].MatchType != model.AutoTagMatchRegex {
+			continue
+		}
+		if re, err := regexp.Compile(rules[i].Pattern); err == nil {
+			patterns[i] = re
+		}
+	}
+	return patterns
+}
+
+func matchAutoTagRule(rule *model.AutoTagRule, re *regexp.Regexp, prompt string, tokens []string, checkpoint string, loras []string) (string, bool) {
+	switch rule.MatchType {
+	case model.AutoTagMatchPromptToken:
+		pattern := normalizeTagText(rule.Pattern)
+		for _, token := range tokens {
+			if token == pattern {
+				return token, true
+			}
+		}
+	case model.AutoTagMatchRegex:
+		if re == nil {
+			return "", false
+		}
+		if matched := re.FindString(prompt); matched != "" {
+			return matched, true
+		}
+	case model.AutoTagMatchCheckpoint:
+		pattern := normalizeModelName(rule.Pattern)
+		if checkpoint != "" && pattern != "" && strings.Contains(checkpoint, pattern) {
+			return checkpoint, true
+		}
+	case model.AutoTagMatchLora:
+		pattern := normalizeModelName(rule.Pattern)
+		for _, lora := range loras {
+			if pattern != "" && strings.Contains(lora, pattern) {
+				return lora, true
+			}
+		}
+	}
+	return "", false
+}
+
+func dedupeAutoTagMatches(matches []AutoTagMatch) []AutoTagMatch {
+	result := make([]AutoTagMatch, 0, len(matches))
+	seen := make(map[uint]struct{}, len(matches))
+	for _, match := range matches {
+		if _, ok := seen[match.RuleID]; ok {
+			continue
+		}
+		seen[match.RuleID] = struct{}{}
+		result = append(result, match)
+	}
+	return result
+}
+
+func promptTokens(prompt string) []string {
+	stripped := promptExtraNetPattern.ReplaceAllString(prompt, ",")
+	tokens := make([]string, 0)
+	for _, part := range promptSeparatorPattern.Split(stripped, -1) {
+		token := normalizePromptToken(part)
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func normalizePromptToken(token string) string {
+	token = escapedParenEncoder.Replace(token)
+	token = strings.Trim(token, "()[]{} \t")
+	token = weightSuffixPattern.ReplaceAllString(token, "")
+	token = strings.Trim(token, "()[]{} \t")
+	return normalizeTagText(escapedParenDecoder.Replace(token))
+}
+
+func normalizeTagText(value string) string {
+	return strings.Join(strings.Fields(tagTextReplacer.Replace(strings.ToLower(value))), " ")
+}
+
+func loraNames(metadata *AIImageMetadata) []string {
+	names := make([]string, 0)
+	seen := make(map[string]struct{})
+	add := func(value string) {
+		name := normalizeModelName(value)
+		if name == "" {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+
+	for _, match := range promptLoraPattern.FindAllStringSubmatch(metadata.Prompt, -1) {
+		add(match[1])
+	}
+	for _, item := range metadata.OtherMetadata {
+		if !strings.Contains(strings.ToLower(item.Key), "lora") {
+			continue
+		}
+		for _, value := range item.Values {
+			for _, part := range strings.Split(value, ",") {
+				add(part)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func normalizeModelName(value string) string {
+	return normalizeTagText(modelBaseName(weightSuffixPattern.ReplaceAllString(strings.TrimSpace(value), "")))
+}
+
+func autoTagRuleToInfo(rule *model.AutoTagRule, tag *model.Tag) *AutoTagRuleInfo {
+	return &AutoTagRuleInfo{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		MatchType: rule.MatchType,
+		Pattern:   rule.Pattern,
+		Enabled:   rule.Enabled,
+		Tag:       tag,
+		CreatedAt: rule.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: rule.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"illust-nest/internal/repository"
+	"reflect"
+	"strings"
+)
+
+const (
+	BatchEditStatusUpdated   = "updated"
+	BatchEditStatusUnchanged = "unchanged"
+	BatchEditStatusNotFound  = "not_found"
+)
+
+type BatchEditService struct {
+	workRepo       *repository.WorkRepository
+	tagRepo        *repository.TagRepository
+	collectionRepo *repository.CollectionRepository
+	auditService   *AuditService
+}
+
+func NewBatchEditService(workRepo *repository.WorkRepository, tagRepo *repository.TagRepository, collectionRepo *repository.CollectionRepository, auditService *AuditService) *BatchEditService {
+	return &BatchEditService{
+		workRepo:       workRepo,
+		tagRepo:        tagRepo,
+		collectionRepo: collectionRepo,
+		auditService:   auditService,
+	}
+}
+
+func (s *BatchEditService) Apply(actor *Actor, req *BatchEditRequest, filter *WorkListParams) (*BatchEditResult, error) {
+	operations, err := s.normalizeOperations(req.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedIDs, err := s.resolveWorkIDs(req, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	works, err := s.workRepo.FindByIDs(requestedIDs)
+	if err != nil {
+		return nil, err
+	}
+	before := make(map[uint]*workAuditSnapshot, len(works))
+	ids := make([]uint, 0, len(works))
+	for i := range works {
+		before[works[i].ID] = newWorkAuditSnapshot(&works[i])
+		ids = append(ids, works[i].ID)
+	}
+	collectionsBefore, err := s.workRepo.FindCollectionIDsByWorkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workRepo.ApplyBatchOperations(ids, operations); err != nil {
+		return nil, err
+	}
+
+	updatedWorks, err := s.workRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	after := make(map[uint]*workAuditSnapshot, len(updatedWorks))
+	for i := range updatedWorks {
+		after[updatedWorks[i].ID] = newWorkAuditSnapshot(&updatedWorks[i])
+	}
+	collectionsAfter, err := s.workRepo.FindCollectionIDsByWorkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchEditResult{
+		Total: len(requestedIDs),
+		Items: make([]BatchEditItemResult, 0, len(requestedIDs)),
+	}
+	for _, id := range requestedIDs {
+		workBefore, ok := before[id]
+		if !ok {
+			result.NotFound++
+			result.Items = append(result.Items, BatchEditItemResult{WorkID: id, Status: BatchEditStatusNotFound})
+			continue
+		}
+
+		changed := false
+		if !reflect.DeepEqual(workBefore, after[id]) {
+			changed = true
+			s.auditService.Record(actor, "work.batch_edit", AuditEntityWork, id, id, workBefore, after[id])
+		}
+		if !reflect.DeepEqual(collectionsBefore[id], collectionsAfter[id]) {
+			changed = true
+			s.auditService.Record(actor, "work.batch_edit", AuditEntityWorkCollections, id, id,
+				&workCollectionsAuditSnapshot{CollectionIDs: nonNilIDs(collectionsBefore[id])},
+				&workCollectionsAuditSnapshot{CollectionIDs: nonNilIDs(collectionsAfter[id])})
+		}
+
+		if changed {
+			result.Updated++
+			result.Items = append(result.Items, BatchEditItemResult{WorkID: id, Status: BatchEditStatusUpdated})
+		} else {
+			result.Unchanged++
+			result.Items = append(result.Items, BatchEditItemResult{WorkID: id, Status: BatchEditStatusUnchanged})
+		}
+	}
+
+	return result, nil
+}
+
+func (s *BatchEditService) resolveWorkIDs(req *BatchEditRequest, filter *WorkListParams) ([]uint, error) {
+	if len(req.WorkIDs) > 0 {
+		return uniqueIDs(req.WorkIDs), nil
+	}
+
+	if !req.UseFilter || filter == nil {
+		return nil, &ValidationError{Message: "work_ids is required unless use_filter is set", Code: 1001}
+	}
+
+	repoParams := workListRepoParams(filter)
+	if !hasWorkFilterConstraints(repoParams) && !req.All {
+		return nil, &ValidationError{Message: "filter matches every work; add a constraint or set all to confirm", Code: 1001}
+	}
+
+	ids, err := s.workRepo.FindIDsByFilters(repoParams)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []uint{}
+	}
+	return ids, nil
+}
+
+func hasWorkFilterConstraints(repoParams map[string]interface{}) bool {
+	for key := range repoParams {
+		if key != "sort_by" && key != "sort_order" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *BatchEditService) normalizeOperations(operations []BatchEditOperation) ([]repository.WorkBatchOperation, error) {
+	result := make([]repository.WorkBatchOperation, 0, len(operations))
+	for _, op := range operations {
+		item := repository.WorkBatchOperation{Type: strings.ToLower(strings.TrimSpace(op.Type))}
+
+		switch item.Type {
+		case repository.WorkBatchAddTags, repository.WorkBatchRemoveTags:
+			if len(op.TagIDs) == 0 {
+				return nil, &ValidationError{Message: item.Type + " requires tag_ids", Code: 1001}
+			}
+			tags, err := s.tagRepo.FindByIDs(op.TagIDs)
+			if err != nil {
+				return nil, err
+			}
+			if item.Type == repository.WorkBatchAddTags && len(tags) != len(uniqueIDs(op.TagIDs)) {
+				return nil, &ValidationError{Message: "tag not found", Code: 1002}
+			}
+			for _, tag := range tags {
+				item.TagIDs = append(item.TagIDs, tag.ID)
+			}
+		case repository.WorkBatchSetRating:
+			if op.Rating == nil || *op.Rating < 0 || *op.Rating > 5 {
+				return nil, &ValidationError{Message: "set_rating requires rating between 0 and 5", Code: 1001}
+			}
+			item.Rating = *op.Rating
+		case repository.WorkBatchSetPublic:
+			if op.IsPublic == nil {
+				return nil, &ValidationError{Message: "set_public requires is_public", Code: 1001}
+			}
+			item.IsPublic = *op.IsPublic
+		case repository.WorkBatchPrependTitle:
+			if op.Text == "" {
+				return nil, &ValidationError{Message: "prepend_title requires text", Code: 1001}
+			}
+			item.Text = op.Text
+		case repository.WorkBatchAddToCollection, repository.WorkBatchRemoveFromCollection:
+			if op.CollectionID == 0 {
+				return nil, &ValidationError{Message: item.Type + " requires collection_id", Code: 1001}
+			}
+			if _, err := s.collectionRepo.FindByID(op.CollectionID); err != nil {
+				return nil, &ValidationError{Message: "collection not found", Code: 1002}
+			}
+			item.CollectionID = op.CollectionID
+		default:
+			return nil, &ValidationError{Message: "unsupported operation: " + op.Type, Code: 1001}
+		}
+
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	result := make([]uint, 0, len(ids))
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+func nonNilIDs(ids []uint) []uint {
+	if ids == nil {
+		return []uint{}
+	}
+	return ids
+}
@@ -9,12 +9,14 @@ import (
 type CollectionService struct {
 	collectionRepo *repository.CollectionRepository
 	workRepo       *repository.WorkRepository
+	auditService   *AuditService
 }
 
-func NewCollectionService(collectionRepo *repository.CollectionRepository, workRepo *repository.WorkRepository) *CollectionService {
+func NewCollectionService(collectionRepo *repository.CollectionRepository, workRepo *repository.WorkRepository, auditService *AuditService) *CollectionService {
 	return &CollectionService{
 		collectionRepo: collectionRepo,
 		workRepo:       workRepo,
+		auditService:   auditService,
 	}
 }
 
@@ -82,6 +84,7 @@ func (s *CollectionService) GetCollectionWorks(id uint, params *WorkListParams)
 	if params.SortOrder != "" {
 		repoParams["sort_order"] = params.SortOrder
 	}
+	applyWorkAttributeParams(repoParams, params)
 
 	works, total, err := s.workRepo.FindByCollectionID(id, repoParams, params.Page, params.PageSize)
 	if err != nil {
@@ -108,7 +111,7 @@ func (s *CollectionService) GetCollectionWorks(id uint, params *WorkListParams)
 	}, nil
 }
 
-func (s *CollectionService) CreateCollection(req *CreateCollectionRequest) (*CollectionInfo, error) {
+func (s *CollectionService) CreateCollection(actor *Actor, req *CreateCollectionRequest) (*CollectionInfo, error) {
 	if req.ParentID != nil {
 		return nil, errors.New("flat collections only: parent_id is not supported")
 	}
@@ -123,10 +126,12 @@ func (s *CollectionService) CreateCollection(req *CreateCollectionRequest) (*Col
 		return nil, err
 	}
 
+	s.auditService.Record(actor, "collection.create", AuditEntityCollection, collection.ID, 0, nil, newCollectionAuditSnapshot(collection))
+
 	return s.GetCollection(collection.ID)
 }
 
-func (s *CollectionService) UpdateCollection(id uint, req *UpdateCollectionRequest) (*CollectionInfo, error) {
+func (s *CollectionService) UpdateCollection(actor *Actor, id uint, req *UpdateCollectionRequest) (*CollectionInfo, error) {
 	collection, err := s.collectionRepo.FindByID(id)
 	if err != nil {
 		return nil, errors.New("collection not found")
@@ -136,6 +141,7 @@ func (s *CollectionService) UpdateCollection(id uint, req *UpdateCollectionReque
 		return nil, errors.New("flat collections only: parent_id is not supported")
 	}
 
+	before := newCollectionAuditSnapshot(collection)
 	if req.Name != "" {
 		collection.Name = req.Name
 	}
@@ -146,31 +152,45 @@ func (s *CollectionService) UpdateCollection(id uint, req *UpdateCollectionReque
 		return nil, err
 	}
 
+	s.auditService.Record(actor, "collection.update", AuditEntityCollection, id, 0, before, newCollectionAuditSnapshot(collection))
+
 	return s.GetCollection(id)
 }
 
-func (s *CollectionService) DeleteCollection(id uint, recursive bool) error {
-	if !recursive {
-		_, err := s.collectionRepo.FindByID(id)
-		if err != nil {
-			return errors.New("collection not found")
-		}
+func (s *CollectionService) DeleteCollection(actor *Actor, id uint, recursive bool) error {
+	collection, err := s.collectionRepo.FindByID(id)
+	if err != nil && !recursive {
+		return errors.New("collection not found")
 	}
 
-	return s.collectionRepo.Delete(id, recursive)
+	workIDs, _ := s.collectionRepo.FindWorkIDs(id)
+	beforeByWork := s.snapshotWorksCollections(workIDs)
+
+	if err := s.collectionRepo.Delete(id, recursive); err != nil {
+		return err
+	}
+
+	if collection != nil {
+		s.auditService.Record(actor, "collection.delete", AuditEntityCollection, id, 0, newCollectionAuditSnapshot(collection), nil)
+	}
+	s.recordWorksCollections(actor, "collection.delete", workIDs, beforeByWork)
+	return nil
 }
 
-func (s *CollectionService) AddWorks(id uint, req *AddWorksRequest) (*WorksResult, error) {
+func (s *CollectionService) AddWorks(actor *Actor, id uint, req *AddWorksRequest) (*WorksResult, error) {
 	_, err := s.collectionRepo.FindByID(id)
 	if err != nil {
 		return nil, errors.New("collection not found")
 	}
 
+	beforeByWork := s.snapshotWorksCollections(req.WorkIDs)
 	addedCount, skippedCount, err := s.collectionRepo.AddWorks(id, req.WorkIDs)
 	if err != nil {
 		return nil, err
 	}
 
+	s.recordWorksCollections(actor, "collection.works.add", req.WorkIDs, beforeByWork)
+
 	if addedCount == 0 && skippedCount > 0 {
 		return nil, errors.New("all works already in collection")
 	}
@@ -189,12 +209,13 @@ func (s *CollectionService) AddWorks(id uint, req *AddWorksRequest) (*WorksResul
 	return &WorksResult{Works: workInfos}, nil
 }
 
-func (s *CollectionService) RemoveWorks(id uint, req *RemoveWorksRequest) (*WorksResult, error) {
+func (s *CollectionService) RemoveWorks(actor *Actor, id uint, req *RemoveWorksRequest) (*WorksResult, error) {
 	_, err := s.collectionRepo.FindByID(id)
 	if err != nil {
 		return nil, errors.New("collection not found")
 	}
 
+	beforeByWork := s.snapshotWorksCollections(req.WorkIDs)
 	removedCount, err := s.collectionRepo.RemoveWorks(id, req.WorkIDs)
 	if err != nil {
 		return nil, err
@@ -204,6 +225,8 @@ func (s *CollectionService) RemoveWorks(id uint, req *RemoveWorksRequest) (*Work
 		return nil, errors.New("no works removed")
 	}
 
+	s.recordWorksCollections(actor, "collection.works.remove", req.WorkIDs, beforeByWork)
+
 	works, _, err := s.workRepo.FindByCollectionID(id, map[string]interface{}{}, 1, 1000)
 	if err != nil {
 		return nil, err
@@ -218,7 +241,7 @@ func (s *CollectionService) RemoveWorks(id uint, req *RemoveWorksRequest) (*Work
 	return &WorksResult{Works: workInfos}, nil
 }
 
-func (s *CollectionService) SyncWorkCollections(workID uint, req *SyncWorkCollectionsRequest) error {
+func (s *CollectionService) SyncWorkCollections(actor *Actor, workID uint, req *SyncWorkCollectionsRequest) error {
 	_, err := s.workRepo.FindByID(workID, false)
 	if err != nil {
 		return errors.New("work not found")
@@ -230,24 +253,93 @@ func (s *CollectionService) SyncWorkCollections(workID uint, req *SyncWorkCollec
 		}
 	}
 
-	return s.collectionRepo.ReplaceWorkCollections(workID, req.CollectionIDs)
+	beforeByWork := s.snapshotWorksCollections([]uint{workID})
+	if err := s.collectionRepo.ReplaceWorkCollections(workID, req.CollectionIDs); err != nil {
+		return err
+	}
+
+	s.recordWorksCollections(actor, "work.collections.sync", []uint{workID}, beforeByWork)
+	return nil
 }
 
-func (s *CollectionService) UpdateSortOrder(parentID *uint, req *UpdateSortOrderRequest) error {
+func (s *CollectionService) UpdateSortOrder(actor *Actor, parentID *uint, req *UpdateSortOrderRequest) error {
 	if parentID != nil {
 		return errors.New("flat collections only: parent_id is not supported")
 	}
 
-	return s.collectionRepo.UpdateSortOrder(parentID, req.CollectionIDs)
+	before := s.snapshotCollectionOrder()
+	if err := s.collectionRepo.UpdateSortOrder(parentID, req.CollectionIDs); err != nil {
+		return err
+	}
+
+	s.auditService.Record(actor, "collection.reorder", AuditEntityCollection, 0, 0, before, s.snapshotCollectionOrder())
+	return nil
 }
 
-func (s *CollectionService) UpdateWorkSortOrder(id uint, req *UpdateWorkSortOrderRequest) error {
+func (s *CollectionService) UpdateWorkSortOrder(actor *Actor, id uint, req *UpdateWorkSortOrderRequest) error {
 	_, err := s.collectionRepo.FindByID(id)
 	if err != nil {
 		return errors.New("collection not found")
 	}
 
-	return s.collectionRepo.UpdateWorkSortOrder(id, req.WorkIDs)
+	before := s.snapshotCollectionWorkOrder(id)
+	if err := s.collectionRepo.UpdateWorkSortOrder(id, req.WorkIDs); err != nil {
+		return err
+	}
+
+	s.auditService.Record(actor, "collection.works.reorder", AuditEntityCollection, id, 0, before, s.snapshotCollectionWorkOrder(id))
+	return nil
+}
+
+func (s *CollectionService) snapshotWorksCollections(workIDs []uint) map[uint]*workCollectionsAuditSnapshot {
+	snapshots := make(map[uint]*workCollectionsAuditSnapshot, len(workIDs))
+	for _, workID := range workIDs {
+		collectionIDs, err := s.collectionRepo.FindCollectionIDsByWorkID(workID)
+		if err != nil {
+			continue
+		}
+		snapshots[workID] = &workCollectionsAuditSnapshot{CollectionIDs: collectionIDs}
+	}
+	return snapshots
+}
+
+func (s *CollectionService) recordWorksCollections(actor *Actor, action string, workIDs []uint, beforeByWork map[uint]*workCollectionsAuditSnapshot) {
+	after := s.snapshotWorksCollections(workIDs)
+	seen := make(map[uint]struct{}, len(workIDs))
+	for _, workID := range workIDs {
+		if _, ok := seen[workID]; ok {
+			continue
+		}
+		seen[workID] = struct{}{}
+		s.auditService.Record(actor, action, AuditEntityWorkCollections, workID, workID, beforeByWork[workID], after[workID])
+	}
+}
+
+func (s *CollectionService) snapshotCollectionOrder() *collectionOrderAuditSnapshot {
+	collections, err := s.collectionRepo.FindAll()
+	if err != nil {
+		return nil
+	}
+	collectionIDs := make([]uint, 0, len(collections))
+	for _, collection := range collections {
+		collectionIDs = append(collectionIDs, collection.ID)
+	}
+	return &collectionOrderAuditSnapshot{CollectionIDs: collectionIDs}
+}
+
+func (s *CollectionService) snapshotCollectionWorkOrder(id uint) *collectionOrderAuditSnapshot {
+	workIDs, err := s.collectionRepo.FindWorkIDs(id)
+	if err != nil {
+		return nil
+	}
+	return &collectionOrderAuditSnapshot{WorkIDs: workIDs}
+}
+
+func newCollectionAuditSnapshot(collection *model.Collection) *collectionAuditSnapshot {
+	return &collectionAuditSnapshot{
+		Name:        collection.Name,
+		Description: collection.Description,
+	}
 }
 
 func (s *CollectionService) collectionToInfo(collection *model.Collection) *CollectionInfo {
@@ -277,14 +369,20 @@ func workToInfo(work *model.Work, fullDetails bool) *WorkInfo {
 
 	if len(work.Images) > 0 {
 		info.CoverImage = &ImageInfo{
-			ID:             work.Images[0].ID,
-			ThumbnailPath:  work.Images[0].ThumbnailPath,
-			OriginalPath:   work.Images[0].StoragePath,
-			TranscodedPath: work.Images[0].TranscodedPath,
-			FileSize:       work.Images[0].FileSize,
-			Width:          work.Images[0].Width,
-			Height:         work.Images[0].Height,
-			SortOrder:      work.Images[0].SortOrder,
+			ID:                    work.Images[0].ID,
+			ThumbnailPath:         work.Images[0].ThumbnailPath,
+			OriginalPath:          work.Images[0].StoragePath,
+			TranscodedPath:        work.Images[0].TranscodedPath,
+			Palette:               parseImagePalette(work.Images[0].Palette),
+			FileSize:              work.Images[0].FileSize,
+			Width:                 work.Images[0].Width,
+			Height:                work.Images[0].Height,
+			FrameCount:            work.Images[0].FrameCount,
+			DurationMs:            work.Images[0].DurationMs,
+			LoopCount:             work.Images[0].LoopCount,
+			AnimatedThumbnailPath: work.Images[0].AnimatedThumbnailPath,
+			MediaType:             work.Images[0].MediaType,
+			SortOrder:             work.Images[0].SortOrder,
 		}
 		info.ImageCount = len(work.Images)
 	}
@@ -293,14 +391,20 @@ func workToInfo(work *model.Work, fullDetails bool) *WorkInfo {
 		var images []ImageInfo
 		for _, img := range work.Images {
 			images = append(images, ImageInfo{
-				ID:             img.ID,
-				ThumbnailPath:  img.ThumbnailPath,
-				OriginalPath:   img.StoragePath,
-				TranscodedPath: img.TranscodedPath,
-				FileSize:       img.FileSize,
-				Width:          img.Width,
-				Height:         img.Height,
-				SortOrder:      img.SortOrder,
+				ID:                    img.ID,
+				ThumbnailPath:         img.ThumbnailPath,
+				OriginalPath:          img.StoragePath,
+				TranscodedPath:        img.TranscodedPath,
+				Palette:               parseImagePalette(img.Palette),
+				FileSize:              img.FileSize,
+				Width:                 img.Width,
+				Height:                img.Height,
+				FrameCount:            img.FrameCount,
+				DurationMs:            img.DurationMs,
+				LoopCount:             img.LoopCount,
+				AnimatedThumbnailPath: img.AnimatedThumbnailPath,
+				MediaType:             img.MediaType,
+				SortOrder:             img.SortOrder,
 			})
 		}
 		info.Images = images
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"illust-nest/internal/config"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConverterConcurrency = 2
+	defaultConverterTimeout     = 120 * time.Second
+	converterStderrMaxBytes     = 4096
+	converterWaitDelay          = 5 * time.Second
+	converterProbeTimeout       = 15 * time.Second
+)
+
+type converterPool struct {
+	slots     chan struct{}
+	timeout   time.Duration
+	mu        sync.Mutex
+	active    int
+	waiting   int
+	completed uint64
+	failed    uint64
+	timedOut  uint64
+}
+
+var (
+	converterPoolOnce   sync.Once
+	sharedConverterPool *converterPool
+)
+
+func getConverterPool() *converterPool {
+	converterPoolOnce.Do(func() {
+		size := config.GlobalConfig.Converters.MaxConcurrency
+		if size <= 0 {
+			size = defaultConverterConcurrency
+		}
+		timeout := time.Duration(config.GlobalConfig.Converters.TimeoutSeconds) * time.Second
+		if timeout <= 0 {
+			timeout = defaultConverterTimeout
+		}
+		sharedConverterPool = &converterPool{
+			slots:   make(chan struct{}, size),
+			timeout: timeout,
+		}
+	})
+	return sharedConverterPool
+}
+
+func (p *converterPool) acquire(ctx context.Context) error {
+	p.mu.Lock()
+	p.waiting++
+	p.mu.Unlock()
+
+	select {
+	case p.slots <- struct{}{}:
+		p.mu.Lock()
+		p.waiting--
+		p.active++
+		p.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.waiting--
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (p *converterPool) release(err *ConverterError) {
+	<-p.slots
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active--
+	switch {
+	case err == nil:
+		p.completed++
+	case err.TimedOut:
+		p.timedOut++
+	default:
+		p.failed++
+	}
+}
+
+func (p *converterPool) Status() ConverterPoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ConverterPoolStatus{
+		MaxConcurrency: cap(p.slots),
+		Active:         p.active,
+		Waiting:        p.waiting,
+		Completed:      p.completed,
+		Failed:         p.failed,
+		TimedOut:       p.timedOut,
+		TimeoutSeconds: int(p.timeout / time.Second),
+	}
+}
+
+func converterTimeout() time.Duration {
+	return getConverterPool().timeout
+}
+
+func runConverter(ctx context.Context, command string, args []string, env []string) ([]byte, error) {
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("command not found: %s", command)
+	}
+
+	pool := getConverterPool()
+	if err := pool.acquire(ctx); err != nil {
+		return nil, &ConverterError{Command: command, ExitCode: -1, Canceled: true, Err: err}
+	}
+
+	out, convErr := execConverter(ctx, pool.timeout, command, args, env)
+	pool.release(convErr)
+	if convErr != nil {
+		return out, convErr
+	}
+	return out, nil
+}
+
+func runConverterProbe(ctx context.Context, command string, args []string, env []string) ([]byte, error) {
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("command not found: %s", command)
+	}
+
+	out, convErr := execConverter(ctx, converterProbeTimeout, command, args, env)
+	if convErr != nil {
+		return out, convErr
+	}
+	return out, nil
+}
+
+func execConverter(ctx context.Context, timeout time.Duration, command string, args []string, env []string) ([]byte, *ConverterError) {
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(runCtx, command, args...)
+	cmd.Env = env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = converterWaitDelay
+	configureConverterProcess(cmd)
+	err := cmd.Run()
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+
+	convErr := &ConverterError{
+		Command:  command,
+		ExitCode: -1,
+		Stderr:   tailString(strings.TrimSpace(stderr.String()), converterStderrMaxBytes),
+		Err:      err,
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		convErr.ExitCode = exitErr.ExitCode()
+	}
+	switch {
+	case ctx.Err() != nil:
+		convErr.Canceled = true
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		convErr.TimedOut = true
+		convErr.Timeout = timeout.String()
+	}
+	return stdout.Bytes(), convErr
+}
+
+func tailString(value string, maxBytes int) string {
+	if len(value) <= maxBytes {
+		return value
+	}
+	return "..." + value[len(value)-maxBytes:]
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRunConverterKillsProcessGroupOnCancel(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := runConverter(ctx, "sh", []string{"-c", "sleep 6; true"}, nil)
+	elapsed := time.Since(started)
+
+	var convErr *ConverterError
+	if !errors.As(err, &convErr) || !convErr.Canceled {
+		t.Fatalf("runConverter() error = %v, want canceled ConverterError", err)
+	}
+	if elapsed > 3*time.Second {
+		t.Fatalf("runConverter() returned after %v, want the child process group killed", elapsed)
+	}
+	if status := getConverterPool().Status(); status.Active != 0 {
+		t.Fatalf("pool active = %d after cancel, want 0", status.Active)
+	}
+}
+
+func TestRunConverterProbeBypassesSaturatedPool(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	pool := getConverterPool()
+	for i := 0; i < cap(pool.slots); i++ {
+		if err := pool.acquire(context.Background()); err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+	}
+	defer func() {
+		for i := 0; i < cap(pool.slots); i++ {
+			pool.release(nil)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	out, err := runConverterProbe(ctx, "sh", []string{"-c", "echo ok"}, nil)
+	if err != nil || string(out) != "ok\n" {
+		t.Fatalf("runConverterProbe() = %q, %v; want ok while the pool is full", out, err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package service
+
+import "os/exec"
+
+func configureConverterProcess(cmd *exec.Cmd) {}
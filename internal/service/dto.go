@@ -1,6 +1,10 @@
 package service
 
-import "illust-nest/internal/model"
+import (
+	"encoding/json"
+	"illust-nest/internal/model"
+	"time"
+)
 
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -31,16 +35,49 @@ type InitRequest struct {
 }
 
 type SystemSettings struct {
-	PublicGalleryEnabled bool   `json:"public_gallery_enabled"`
-	SiteTitle            string `json:"site_title"`
-	ImageMagickEnabled   bool   `json:"imagemagick_enabled"`
-	ImageMagickVersion   string `json:"imagemagick_version"`
+	PublicGalleryEnabled bool           `json:"public_gallery_enabled"`
+	SiteTitle            string         `json:"site_title"`
+	ImageMagickEnabled   bool           `json:"imagemagick_enabled"`
+	ImageMagickVersion   string         `json:"imagemagick_version"`
+	TrashRetentionDays   int            `json:"trash_retention_days"`
+	PublicStripEXIF      bool           `json:"public_strip_sensitive_exif"`
+	AnimatedThumbnails   bool           `json:"animated_thumbnails_enabled"`
+	FFmpegEnabled        bool           `json:"ffmpeg_enabled"`
+	UploadMaxRequestMB   int            `json:"upload_max_request_mb"`
+	UploadMaxFileMB      int            `json:"upload_max_file_mb"`
+	UploadFormatLimitsMB map[string]int `json:"upload_format_limits_mb"`
+	UploadMaxMegapixels  int            `json:"upload_max_megapixels"`
 }
 
 type ImageMagickTestResult struct {
-	Available bool   `json:"available"`
-	Command   string `json:"command"`
-	Message   string `json:"message"`
+	Available bool                `json:"available"`
+	Command   string              `json:"command"`
+	Message   string              `json:"message"`
+	Pool      ConverterPoolStatus `json:"pool"`
+	Formats   []ImageMagickFormat `json:"formats"`
+}
+
+type ImageMagickFormat struct {
+	Name        string `json:"name"`
+	Mode        string `json:"mode"`
+	Description string `json:"description"`
+}
+
+type FFmpegTestResult struct {
+	Available bool                `json:"available"`
+	Command   string              `json:"command"`
+	Message   string              `json:"message"`
+	Pool      ConverterPoolStatus `json:"pool"`
+}
+
+type ConverterPoolStatus struct {
+	MaxConcurrency int    `json:"max_concurrency"`
+	Active         int    `json:"active"`
+	Waiting        int    `json:"waiting"`
+	Completed      uint64 `json:"completed"`
+	Failed         uint64 `json:"failed"`
+	TimedOut       uint64 `json:"timed_out"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
 }
 
 type SystemStatistics struct {
@@ -49,6 +86,24 @@ type SystemStatistics struct {
 	TagCount             int64                 `json:"tag_count"`
 	CollectionCount      int64                 `json:"collection_count"`
 	DuplicateImageGroups []DuplicateImageGroup `json:"duplicate_image_groups"`
+	TopTagPairs          []TagPairStat         `json:"top_tag_pairs"`
+	TagsUsedOnce         []TagStatItem         `json:"tags_used_once"`
+	TagsUsedOnceCount    int64                 `json:"tags_used_once_count"`
+	UnusedTags           []TagStatItem         `json:"unused_tags"`
+	UnusedTagCount       int64                 `json:"unused_tag_count"`
+}
+
+type TagStatItem struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	WorkCount int64  `json:"work_count"`
+}
+
+type TagPairStat struct {
+	TagA  TagStatItem `json:"tag_a"`
+	TagB  TagStatItem `json:"tag_b"`
+	Count int64       `json:"count"`
 }
 
 type DuplicateImageGroup struct {
@@ -64,11 +119,27 @@ type DuplicateImageWorkRef struct {
 }
 
 type CreateTagRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=50"`
+	Name            string `json:"name" binding:"required,min=1,max=50"`
+	Category        string `json:"category" binding:"omitempty,oneof=artist character series meta general"`
+	Transliteration string `json:"transliteration" binding:"omitempty,max=200"`
 }
 
 type UpdateTagRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=50"`
+	Name            string `json:"name" binding:"required,min=1,max=50"`
+	Category        string `json:"category" binding:"omitempty,oneof=artist character series meta general"`
+	Transliteration string `json:"transliteration" binding:"omitempty,max=200"`
+}
+
+type UpdateTagCategoryRequest struct {
+	Color        string `json:"color" binding:"omitempty,max=20"`
+	SortPriority *int   `json:"sort_priority"`
+}
+
+type TagGroup struct {
+	Category     string       `json:"category"`
+	Color        string       `json:"color"`
+	SortPriority int          `json:"sort_priority"`
+	Tags         []*model.Tag `json:"tags"`
 }
 
 type BatchCreateTagsRequest struct {
@@ -80,12 +151,64 @@ type BatchCreateTagsResponse struct {
 	Skipped []string     `json:"skipped"`
 }
 
-type TagInfo struct {
+type UpdateTagParentRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+type CreateTagAliasRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+type CreateTagImplicationRequest struct {
+	ImpliedTagID uint `json:"implied_tag_id" binding:"required"`
+}
+
+type MergeTagsRequest struct {
+	TargetID    uint `json:"target_id" binding:"required"`
+	CreateAlias bool `json:"create_alias"`
+}
+
+type RelatedTagInfo struct {
+	ID        uint    `json:"id"`
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	CoCount   int64   `json:"co_count"`
+	WorkCount int64   `json:"work_count"`
+	Score     float64 `json:"score"`
+}
+
+type RelatedTagsResult struct {
+	Method           string           `json:"method"`
+	ContextWorkCount int64            `json:"context_work_count"`
+	TotalWorkCount   int64            `json:"total_work_count"`
+	Items            []RelatedTagInfo `json:"items"`
+}
+
+type TagSuggestion struct {
 	ID        uint   `json:"id"`
 	Name      string `json:"name"`
-	IsSystem  bool   `json:"is_system"`
-	CreatedAt string `json:"created_at"`
-	WorkCount int    `json:"work_count,omitempty"`
+	Category  string `json:"category"`
+	WorkCount int64  `json:"work_count"`
+	Matched   string `json:"matched"`
+	MatchType string `json:"match_type"`
+}
+
+type TagAliasInfo struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type TagInfo struct {
+	ID              uint           `json:"id"`
+	Name            string         `json:"name"`
+	IsSystem        bool           `json:"is_system"`
+	Category        string         `json:"category"`
+	Transliteration string         `json:"transliteration,omitempty"`
+	ParentID        *uint          `json:"parent_id,omitempty"`
+	Aliases         []TagAliasInfo `json:"aliases,omitempty"`
+	ImpliedTagIDs   []uint         `json:"implied_tag_ids,omitempty"`
+	CreatedAt       string         `json:"created_at"`
+	WorkCount       int            `json:"work_count,omitempty"`
 }
 
 type CreateWorkRequest struct {
@@ -105,15 +228,81 @@ type UpdateWorkRequest struct {
 }
 
 type WorkListParams struct {
-	Page      int
-	PageSize  int
-	Keyword   string
-	TagIDs    []uint
-	RatingMin int
-	RatingMax int
-	IsPublic  *bool
-	SortBy    string
-	SortOrder string
+	Page            int
+	PageSize        int
+	Keyword         string
+	TagIDs          []uint
+	RatingMin       int
+	RatingMax       int
+	IsPublic        *bool
+	SortBy          string
+	SortOrder       string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	WidthMin        int
+	WidthMax        int
+	HeightMin       int
+	HeightMax       int
+	AspectRatio     string
+	Formats         []string
+	ImageCountMin   int
+	ImageCountMax   int
+	HasAIMetadata   *bool
+	AICheckpointID  uint
+	AISamplerID     uint
+	AILoraID        uint
+	AILoraWeightMin *float64
+	AILoraWeightMax *float64
+	AISeed          string
+	Untagged        bool
+	NotInCollection bool
+	EXIFCamera      string
+	EXIFLens        string
+	ISOMin          int
+	ISOMax          int
+	FocalLengthMin  *float64
+	FocalLengthMax  *float64
+	TakenFrom       *time.Time
+	TakenTo         *time.Time
+	HasGPS          *bool
+	GPSBounds       *GeoBounds
+	Color           string
+	ColorTolerance  *float64
+	Animated        *bool
+	MediaType       string
+}
+
+type GeoBounds struct {
+	MinLat float64
+	MaxLat float64
+	MinLng float64
+	MaxLng float64
+}
+
+type WorkTimelineBucket struct {
+	Year  int   `json:"year"`
+	Month int   `json:"month"`
+	Count int64 `json:"count"`
+}
+
+type WorkTimelineResult struct {
+	Items   []WorkTimelineBucket `json:"items"`
+	Undated int64                `json:"undated"`
+}
+
+type WorkGeoPoint struct {
+	WorkID        uint    `json:"work_id"`
+	ImageID       uint    `json:"image_id"`
+	Title         string  `json:"title"`
+	ThumbnailPath string  `json:"thumbnail_path"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	TakenAt       string  `json:"taken_at,omitempty"`
+}
+
+type WorkGeoResult struct {
+	Items     []WorkGeoPoint `json:"items"`
+	Truncated bool           `json:"truncated"`
 }
 
 type WorkPagedResult struct {
@@ -124,6 +313,16 @@ type WorkPagedResult struct {
 	TotalPages int         `json:"total_pages"`
 }
 
+type RandomWorksResult struct {
+	Seed  string      `json:"seed"`
+	Items []*WorkInfo `json:"items"`
+}
+
+type OnThisDayResult struct {
+	Date  string      `json:"date"`
+	Items []*WorkInfo `json:"items"`
+}
+
 type WorkInfo struct {
 	ID          uint         `json:"id"`
 	Title       string       `json:"title"`
@@ -132,23 +331,36 @@ type WorkInfo struct {
 	IsPublic    bool         `json:"is_public"`
 	CreatedAt   string       `json:"created_at"`
 	UpdatedAt   string       `json:"updated_at"`
+	DeletedAt   string       `json:"deleted_at,omitempty"`
 	CoverImage  *ImageInfo   `json:"cover_image,omitempty"`
 	Images      []ImageInfo  `json:"images,omitempty"`
 	ImageCount  int          `json:"image_count,omitempty"`
 	Tags        []*model.Tag `json:"tags,omitempty"`
+	TagGroups   []TagGroup   `json:"tag_groups,omitempty"`
 }
 
 type ImageInfo struct {
-	ID             uint             `json:"id"`
-	ThumbnailPath  string           `json:"thumbnail_path"`
-	OriginalPath   string           `json:"original_path,omitempty"`
-	TranscodedPath string           `json:"transcoded_path,omitempty"`
-	ImageHash      string           `json:"image_hash,omitempty"`
-	AIMetadata     *AIImageMetadata `json:"ai_metadata,omitempty"`
-	FileSize       int64            `json:"file_size,omitempty"`
-	Width          int              `json:"width"`
-	Height         int              `json:"height"`
-	SortOrder      int              `json:"sort_order"`
+	ID                    uint                `json:"id"`
+	ThumbnailPath         string              `json:"thumbnail_path"`
+	OriginalPath          string              `json:"original_path,omitempty"`
+	TranscodedPath        string              `json:"transcoded_path,omitempty"`
+	ImageHash             string              `json:"image_hash,omitempty"`
+	AIMetadata            *AIImageMetadata    `json:"ai_metadata,omitempty"`
+	Palette               []ImagePaletteColor `json:"palette,omitempty"`
+	FileSize              int64               `json:"file_size,omitempty"`
+	Width                 int                 `json:"width"`
+	Height                int                 `json:"height"`
+	FrameCount            int                 `json:"frame_count,omitempty"`
+	DurationMs            int                 `json:"duration_ms,omitempty"`
+	LoopCount             int                 `json:"loop_count"`
+	AnimatedThumbnailPath string              `json:"animated_thumbnail_path,omitempty"`
+	MediaType             string              `json:"media_type,omitempty"`
+	SortOrder             int                 `json:"sort_order"`
+}
+
+type ImagePaletteColor struct {
+	Hex    string  `json:"hex"`
+	Weight float64 `json:"weight"`
 }
 
 type AIImageMetadata struct {
@@ -163,6 +375,44 @@ type AIImageMetadataKeyValue struct {
 	Values []string `json:"values,omitempty"`
 }
 
+type AutoTagRuleRequest struct {
+	Name      string `json:"name" binding:"max=100"`
+	MatchType string `json:"match_type" binding:"required"`
+	Pattern   string `json:"pattern" binding:"required,max=500"`
+	TagID     uint   `json:"tag_id" binding:"required"`
+	Enabled   *bool  `json:"enabled"`
+}
+
+type AutoTagRuleInfo struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	MatchType string     `json:"match_type"`
+	Pattern   string     `json:"pattern"`
+	Enabled   bool       `json:"enabled"`
+	Tag       *model.Tag `json:"tag,omitempty"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+type AutoTagPreviewRequest struct {
+	WorkID     *uint            `json:"work_id"`
+	AIMetadata *AIImageMetadata `json:"ai_metadata"`
+}
+
+type AutoTagMatch struct {
+	RuleID    uint   `json:"rule_id"`
+	RuleName  string `json:"rule_name"`
+	MatchType string `json:"match_type"`
+	Matched   string `json:"matched"`
+	TagID     uint   `json:"tag_id"`
+}
+
+type AutoTagPreviewResult struct {
+	Matches   []AutoTagMatch `json:"matches"`
+	Tags      []*model.Tag   `json:"tags"`
+	NewTagIDs []uint         `json:"new_tag_ids"`
+}
+
 type CreateCollectionRequest struct {
 	Name        string `json:"name" binding:"required,min=1,max=100"`
 	Description string `json:"description"`
@@ -210,28 +460,123 @@ type WorksResult struct {
 }
 
 type UploadedImage struct {
-	StoragePath      string           `json:"storage_path"`
-	ThumbnailPath    string           `json:"thumbnail_path"`
-	TranscodedPath   string           `json:"transcoded_path,omitempty"`
-	ImageHash        string           `json:"image_hash,omitempty"`
-	AIMetadata       *AIImageMetadata `json:"ai_metadata,omitempty"`
-	FileSize         int64            `json:"file_size"`
-	Width            int              `json:"width"`
-	Height           int              `json:"height"`
-	OriginalFilename string           `json:"original_filename"`
+	StoragePath           string               `json:"storage_path"`
+	ThumbnailPath         string               `json:"thumbnail_path"`
+	TranscodedPath        string               `json:"transcoded_path,omitempty"`
+	ImageHash             string               `json:"image_hash,omitempty"`
+	AIMetadata            *AIImageMetadata     `json:"ai_metadata,omitempty"`
+	FileSize              int64                `json:"file_size"`
+	Width                 int                  `json:"width"`
+	Height                int                  `json:"height"`
+	OriginalFilename      string               `json:"original_filename"`
+	FrameCount            int                  `json:"frame_count"`
+	DurationMs            int                  `json:"duration_ms,omitempty"`
+	LoopCount             int                  `json:"loop_count"`
+	AnimatedThumbnailPath string               `json:"animated_thumbnail_path,omitempty"`
+	MediaType             string               `json:"media_type"`
+	EXIF                  *model.WorkImageEXIF `json:"-"`
+	Palette               []ImagePaletteColor  `json:"palette,omitempty"`
 }
 
 type ImageUploadResponse struct {
 	Images []*UploadedImage `json:"images"`
 }
 
+type TrashedImageInfo struct {
+	ImageInfo
+	WorkID    uint   `json:"work_id"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+type TrashIDsRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1"`
+}
+
+type EmptyTrashRequest struct {
+	IDs      []uint `json:"ids"`
+	ImageIDs []uint `json:"image_ids"`
+}
+
+type RestoreTrashResponse struct {
+	RestoredCount int64 `json:"restored_count"`
+}
+
+type EmptyTrashResponse struct {
+	PurgedWorks  int64 `json:"purged_works"`
+	PurgedImages int64 `json:"purged_images"`
+}
+
+type BatchEditOperation struct {
+	Type         string `json:"type" binding:"required"`
+	TagIDs       []uint `json:"tag_ids"`
+	Rating       *int   `json:"rating"`
+	IsPublic     *bool  `json:"is_public"`
+	Text         string `json:"text"`
+	CollectionID uint   `json:"collection_id"`
+}
+
+type BatchEditRequest struct {
+	WorkIDs    []uint               `json:"work_ids"`
+	UseFilter  bool                 `json:"use_filter"`
+	All        bool                 `json:"all"`
+	Operations []BatchEditOperation `json:"operations" binding:"required,min=1,dive"`
+}
+
+type BatchEditItemResult struct {
+	WorkID uint   `json:"work_id"`
+	Status string `json:"status"`
+}
+
+type BatchEditResult struct {
+	Total     int                   `json:"total"`
+	Updated   int                   `json:"updated"`
+	Unchanged int                   `json:"unchanged"`
+	NotFound  int                   `json:"not_found"`
+	Items     []BatchEditItemResult `json:"items"`
+}
+
+type Actor struct {
+	UserID   uint
+	Username string
+}
+
+type AuditFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditLogInfo struct {
+	ID         uint               `json:"id"`
+	UserID     uint               `json:"user_id"`
+	Username   string             `json:"username"`
+	Action     string             `json:"action"`
+	EntityType string             `json:"entity_type"`
+	EntityID   uint               `json:"entity_id"`
+	WorkID     uint               `json:"work_id"`
+	Before     json.RawMessage    `json:"before,omitempty"`
+	After      json.RawMessage    `json:"after,omitempty"`
+	Changes    []AuditFieldChange `json:"changes"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+type AuditLogPagedResult struct {
+	Items      []*AuditLogInfo `json:"items"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	TotalPages int             `json:"total_pages"`
+}
+
 type ExportImageRecord struct {
-	Path        string `json:"path"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Tags        string `json:"tags"`
-	Rating      int    `json:"rating"`
-	CreatedAt   string `json:"created_at"`
+	Path        string           `json:"path"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Tags        string           `json:"tags"`
+	TagNames    []string         `json:"tag_names"`
+	Rating      int              `json:"rating"`
+	CreatedAt   string           `json:"created_at"`
+	AIMetadata  *AIImageMetadata `json:"ai_metadata,omitempty"`
 }
 
 type DuplicateImageCheckRequest struct {
@@ -249,16 +594,66 @@ type DuplicateImageCheckResponse struct {
 	Duplicates []DuplicateImageInfo `json:"duplicates"`
 }
 
+type AIMetadataUsageItem struct {
+	ID         uint     `json:"id,omitempty"`
+	Name       string   `json:"name"`
+	ImageCount int64    `json:"image_count"`
+	WorkCount  int64    `json:"work_count"`
+	MinWeight  *float64 `json:"min_weight,omitempty"`
+	MaxWeight  *float64 `json:"max_weight,omitempty"`
+}
+
+type RescanAIMetadataRequest struct {
+	WorkIDs   []uint `json:"work_ids"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+type RescanAIMetadataStatus struct {
+	Running    bool       `json:"running"`
+	Total      int        `json:"total"`
+	Scanned    int        `json:"scanned"`
+	Updated    int        `json:"updated"`
+	Skipped    int        `json:"skipped"`
+	Failed     int        `json:"failed"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+type AIMetadataExport struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+type ImportAIMetadataRequest struct {
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
 type ImageEXIFField struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
+type ImageEXIFSummary struct {
+	CameraMake   string   `json:"camera_make,omitempty"`
+	CameraModel  string   `json:"camera_model,omitempty"`
+	LensModel    string   `json:"lens_model,omitempty"`
+	FocalLength  *float64 `json:"focal_length,omitempty"`
+	FNumber      *float64 `json:"f_number,omitempty"`
+	ExposureTime string   `json:"exposure_time,omitempty"`
+	ISO          *int     `json:"iso,omitempty"`
+	TakenAt      string   `json:"taken_at,omitempty"`
+	GPSLatitude  *float64 `json:"gps_latitude,omitempty"`
+	GPSLongitude *float64 `json:"gps_longitude,omitempty"`
+}
+
 type ImageEXIFInfo struct {
-	WorkID   uint             `json:"work_id"`
-	ImageID  uint             `json:"image_id"`
-	HasEXIF  bool             `json:"has_exif"`
-	Fields   []ImageEXIFField `json:"fields"`
-	Format   string           `json:"format"`
-	Filename string           `json:"filename"`
+	WorkID   uint              `json:"work_id"`
+	ImageID  uint              `json:"image_id"`
+	HasEXIF  bool              `json:"has_exif"`
+	Fields   []ImageEXIFField  `json:"fields"`
+	Summary  *ImageEXIFSummary `json:"summary,omitempty"`
+	Format   string            `json:"format"`
+	Filename string            `json:"filename"`
 }
@@ -1,6 +1,9 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 type ValidationError struct {
 	Message string `json:"message"`
@@ -14,12 +17,80 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
+const (
+	ErrCodeUploadRequestTooLarge = 1101
+	ErrCodeUploadFileTooLarge    = 1102
+	ErrCodeUploadPixelsExceeded  = 1103
+)
+
+type UploadLimitError struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	Filename string `json:"filename,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Limit    int64  `json:"limit"`
+	Actual   int64  `json:"actual,omitempty"`
+}
+
+func (e *UploadLimitError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Message
+}
+
+type ConverterError struct {
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+	Stderr   string `json:"stderr,omitempty"`
+	TimedOut bool   `json:"timed_out"`
+	Timeout  string `json:"timeout,omitempty"`
+	Canceled bool   `json:"canceled"`
+	Err      error  `json:"-"`
+}
+
+func (e *ConverterError) Error() string {
+	if e == nil {
+		return ""
+	}
+	switch {
+	case e.TimedOut:
+		return fmt.Sprintf("%s timed out after %s", e.Command, e.Timeout)
+	case e.Canceled:
+		return fmt.Sprintf("%s was canceled: %v", e.Command, e.Err)
+	case e.Stderr != "":
+		return fmt.Sprintf("failed to run %s: %s", e.Command, e.Stderr)
+	}
+	return fmt.Sprintf("failed to run %s: %v", e.Command, e.Err)
+}
+
+func (e *ConverterError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
 var (
-	ErrWorkNotFound              = errors.New("work not found")
-	ErrImageNotFound             = errors.New("image not found")
-	ErrAtLeastOneImageRequired   = errors.New("at least one image is required")
-	ErrWorkMustHaveAtLeastOne    = errors.New("work must have at least one image")
-	ErrCannotDeleteLastImage     = errors.New("cannot delete the last image")
-	ErrAIMetadataRequiredFields  = errors.New("AI metadata checkpoint and prompt are required")
-	ErrEXIFUnsupportedSourceType = errors.New("EXIF only supports JPG/TIFF source images")
+	ErrWorkNotFound                = errors.New("work not found")
+	ErrImageNotFound               = errors.New("image not found")
+	ErrAtLeastOneImageRequired     = errors.New("at least one image is required")
+	ErrWorkMustHaveAtLeastOne      = errors.New("work must have at least one image")
+	ErrCannotDeleteLastImage       = errors.New("cannot delete the last image")
+	ErrAIMetadataRequiredFields    = errors.New("AI metadata checkpoint and prompt are required")
+	ErrEXIFUnsupportedSourceType   = errors.New("EXIF only supports JPG/TIFF/PNG/WebP/HEIC/HEIF/AVIF source images")
+	ErrAuditLogNotFound            = errors.New("history entry not found")
+	ErrAuditLogNotRevertible       = errors.New("history entry cannot be reverted")
+	ErrAutoTagRuleNotFound         = errors.New("auto tag rule not found")
+	ErrAIMetadataKindNotFound      = errors.New("AI metadata kind not found")
+	ErrAIMetadataNotFound          = errors.New("image has no AI metadata")
+	ErrAIMetadataFormatUnsupported = errors.New("unsupported AI metadata format")
+	ErrEmbedMetadataMalformed      = errors.New("malformed image data")
+	ErrEmbedMetadataTooLarge       = errors.New("embedded metadata exceeds format limits")
+	ErrAIMetadataImportInvalid     = errors.New("no AI metadata found in imported content")
+	ErrAIMetadataRescanRunning     = errors.New("AI metadata rescan is already running")
+	ErrInvalidGeoBounds            = errors.New("invalid geographic bounds")
+	ErrInvalidUgoiraArchive        = errors.New("invalid ugoira archive")
+	ErrImageMalformed              = errors.New("malformed or unsupported image data")
+	ErrVideoMalformed              = errors.New("file is not a supported MP4, MOV or WebM video")
 )
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+var (
+	exifHeader      = []byte("Exif\x00\x00")
+	tiffLittleMagic = []byte("II*\x00")
+	tiffBigMagic    = []byte("MM\x00*")
+)
+
+type isobmffBox struct {
+	boxType string
+	body    []byte
+}
+
+func extractEXIFPayload(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}), isTIFFPayload(data):
+		return data
+	case bytes.HasPrefix(data, pngSignature):
+		return extractPNGEXIF(data)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return extractWebPEXIF(data)
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return extractISOBMFFEXIF(data)
+	}
+	return nil
+}
+
+func isTIFFPayload(data []byte) bool {
+	return bytes.HasPrefix(data, tiffLittleMagic) || bytes.HasPrefix(data, tiffBigMagic)
+}
+
+func normalizeEXIFPayload(payload []byte) []byte {
+	payload = bytes.TrimPrefix(payload, exifHeader)
+	if !isTIFFPayload(payload) {
+		return nil
+	}
+	return payload
+}
+
+func extractPNGEXIF(data []byte) []byte {
+	offset := len(pngSignature)
+	for offset+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		end := offset + 12 + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		switch chunkType {
+		case "eXIf":
+			return normalizeEXIFPayload(data[offset+8 : offset+8+length])
+		case "IEND":
+			return nil
+		}
+		offset = end
+	}
+	return nil
+}
+
+func extractWebPEXIF(data []byte) []byte {
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkType := string(data[offset : offset+4])
+		length := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		end := offset + 8 + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		if chunkType == "EXIF" {
+			return normalizeEXIFPayload(data[offset+8 : end])
+		}
+		offset = end + length%2
+	}
+	return nil
+}
+
+func extractISOBMFFEXIF(data []byte) []byte {
+	extents := locateISOBMFFEXIF(data)
+	if extents == nil {
+		return nil
+	}
+	return isobmffEXIFPayload(bytes.Join(extents, nil))
+}
+
+func locateISOBMFFEXIF(data []byte) [][]byte {
+	meta := findISOBMFFBox(readISOBMFFBoxes(data), "meta")
+	if meta == nil || len(meta.body) < 4 {
+		return nil
+	}
+	children := readISOBMFFBoxes(meta.body[4:])
+
+	itemID, ok := findISOBMFFExifItemID(findISOBMFFBox(children, "iinf"))
+	if !ok {
+		return nil
+	}
+	var idat []byte
+	if box := findISOBMFFBox(children, "idat"); box != nil {
+		idat = box.body
+	}
+	return locateISOBMFFItem(data, idat, findISOBMFFBox(children, "iloc"), itemID)
+}
+
+func isobmffEXIFPayload(payload []byte) []byte {
+	if len(payload) < 4 {
+		return nil
+	}
+	headerOffset := int(binary.BigEndian.Uint32(payload[0:4]))
+	if headerOffset >= 0 && 4+headerOffset <= len(payload) {
+		if exifPayload := normalizeEXIFPayload(payload[4+headerOffset:]); exifPayload != nil {
+			return exifPayload
+		}
+	}
+	for _, magic := range [][]byte{tiffBigMagic, tiffLittleMagic} {
+		if idx := bytes.Index(payload, magic); idx >= 0 {
+			return payload[idx:]
+		}
+	}
+	return nil
+}
+
+func readISOBMFFBoxes(data []byte) []isobmffBox {
+	boxes := make([]isobmffBox, 0)
+	offset := 0
+	for offset+8 <= len(data) {
+		size := uint64(binary.BigEndian.Uint32(data[offset : offset+4]))
+		boxType := string(data[offset+4 : offset+8])
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data) - offset)
+		case 1:
+			if offset+16 > len(data) {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(data[offset+8 : offset+16])
+			headerSize = 16
+		}
+		if size < headerSize || size > uint64(len(data)-offset) {
+			return boxes
+		}
+		boxes = append(boxes, isobmffBox{boxType: boxType, body: data[offset+int(headerSize) : offset+int(size)]})
+		offset += int(size)
+	}
+	return boxes
+}
+
+func findISOBMFFBox(boxes []isobmffBox, boxType string) *isobmffBox {
+	for i := range boxes {
+		if boxes[i].boxType == boxType {
+			return &boxes[i]
+		}
+	}
+	return nil
+}
+
+func findISOBMFFBoxBody(boxes []isobmffBox, boxType string) []byte {
+	if box := findISOBMFFBox(boxes, boxType); box != nil {
+		return box.body
+	}
+	return nil
+}
+
+func findISOBMFFExifItemID(iinf *isobmffBox) (uint32, bool) {
+	if iinf == nil || len(iinf.body) < 6 {
+		return 0, false
+	}
+	offset := 6
+	if iinf.body[0] != 0 {
+		offset = 8
+	}
+	if offset > len(iinf.body) {
+		return 0, false
+	}
+
+	for _, infe := range readISOBMFFBoxes(iinf.body[offset:]) {
+		if infe.boxType != "infe" || len(infe.body) < 4 {
+			continue
+		}
+		version := infe.body[0]
+		body := infe.body[4:]
+		var itemID uint32
+		switch {
+		case version == 2 && len(body) >= 8:
+			itemID = uint32(binary.BigEndian.Uint16(body[0:2]))
+			body = body[4:]
+		case version >= 3 && len(body) >= 10:
+			itemID = binary.BigEndian.Uint32(body[0:4])
+			body = body[6:]
+		default:
+			continue
+		}
+		if string(body[0:4]) == "Exif" {
+			return itemID, true
+		}
+	}
+	return 0, false
+}
+
+func locateISOBMFFItem(data, idat []byte, iloc *isobmffBox, itemID uint32) [][]byte {
+	if iloc == nil || len(iloc.body) < 8 {
+		return nil
+	}
+	reader := &isobmffReader{data: iloc.body}
+	version := reader.uint(1)
+	reader.skip(3)
+	sizes := reader.uint(2)
+	offsetSize := int(sizes >> 12 & 0xF)
+	lengthSize := int(sizes >> 8 & 0xF)
+	baseOffsetSize := int(sizes >> 4 & 0xF)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0xF)
+	}
+
+	var itemCount uint64
+	if version < 2 {
+		itemCount = reader.uint(2)
+	} else {
+		itemCount = reader.uint(4)
+	}
+	for i := uint64(0); i < itemCount && !reader.failed; i++ {
+		var id uint64
+		if version < 2 {
+			id = reader.uint(2)
+		} else {
+			id = reader.uint(4)
+		}
+		constructionMethod := uint64(0)
+		if version == 1 || version == 2 {
+			constructionMethod = reader.uint(2) & 0xF
+		}
+		reader.skip(2)
+		baseOffset := reader.uint(baseOffsetSize)
+		extentCount := reader.uint(2)
+
+		var extents [][]byte
+		for j := uint64(0); j < extentCount && !reader.failed; j++ {
+			reader.skip(indexSize)
+			extentOffset := reader.uint(offsetSize)
+			extentLength := reader.uint(lengthSize)
+			if reader.failed {
+				return nil
+			}
+			if id != uint64(itemID) {
+				continue
+			}
+
+			source := data
+			if constructionMethod == 1 {
+				source = idat
+			} else if constructionMethod != 0 {
+				return nil
+			}
+			start := baseOffset + extentOffset
+			if start > uint64(len(source)) {
+				return nil
+			}
+			end := uint64(len(source))
+			if extentLength > 0 {
+				end = start + extentLength
+			}
+			if end > uint64(len(source)) || end < start {
+				return nil
+			}
+			extents = append(extents, source[start:end])
+		}
+		if id == uint64(itemID) {
+			return extents
+		}
+	}
+	return nil
+}
+
+type isobmffReader struct {
+	data   []byte
+	offset int
+	failed bool
+}
+
+func (r *isobmffReader) skip(n int) {
+	if r.failed || n < 0 || r.offset+n > len(r.data) {
+		r.failed = true
+		return
+	}
+	r.offset += n
+}
+
+func (r *isobmffReader) uint(n int) uint64 {
+	if r.failed || r.offset+n > len(r.data) {
+		r.failed = true
+		return 0
+	}
+	var value uint64
+	for _, b := range r.data[r.offset : r.offset+n] {
+		value = value<<8 | uint64(b)
+	}
+	r.offset += n
+	return value
+}
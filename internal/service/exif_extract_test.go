@@ -0,0 +1,267 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readTestdata(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read testdata %s: %v", name, err)
+	}
+	return data
+}
+
+func TestExtractEXIFPayloadFixtures(t *testing.T) {
+	tests := []struct {
+		file  string
+		magic []byte
+	}{
+		{file: "png_exif.png", magic: tiffBigMagic},
+		{file: "webp_exif.webp", magic: tiffLittleMagic},
+		{file: "heic_exif.heic", magic: tiffBigMagic},
+		{file: "avif_exif.avif", magic: tiffBigMagic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data := readTestdata(t, tt.file)
+			payload := extractEXIFPayload(data)
+			if !bytes.HasPrefix(payload, tt.magic) {
+				t.Fatalf("extractEXIFPayload() = %q, want TIFF payload starting with %q", payload, tt.magic)
+			}
+
+			record := extractImageEXIF(data)
+			if !record.HasEXIF || record.CameraMake != "IllustCam" || record.CameraModel != "Nest-1" || record.Orientation != 1 {
+				t.Fatalf("extractImageEXIF() = %+v, want IllustCam/Nest-1 with orientation 1", record)
+			}
+		})
+	}
+}
+
+func TestExtractEXIFPayloadFixturesDecode(t *testing.T) {
+	for _, file := range []string{"png_exif.png", "webp_exif.webp"} {
+		if _, _, err := decodeImageConfigSafely(readTestdata(t, file)); err != nil {
+			t.Fatalf("decode %s: %v", file, err)
+		}
+	}
+	for _, file := range []string{"heic_exif.heic", "avif_exif.avif"} {
+		width, height, ok := readHEIFDimensions(readTestdata(t, file))
+		if !ok || width != 640 || height != 480 {
+			t.Fatalf("readHEIFDimensions(%s) = %d, %d, %v; want 640, 480, true", file, width, height, ok)
+		}
+	}
+}
+
+func TestExtractEXIFPayloadInvalid(t *testing.T) {
+	png := readTestdata(t, "png_exif.png")
+	webp := readTestdata(t, "webp_exif.webp")
+	heic := readTestdata(t, "heic_exif.heic")
+
+	corruptPNGLength := append([]byte{}, png...)
+	binary.BigEndian.PutUint32(corruptPNGLength[33:37], 0xFFFFFFF0)
+	corruptWebPLength := append([]byte{}, webp...)
+	binary.LittleEndian.PutUint32(corruptWebPLength[16:20], 0x7FFFFFFF)
+	heicWithoutExifItem := bytes.Replace(heic, []byte("Exif\x00"), []byte("mime\x00"), 1)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "garbage", data: []byte("not an image at all")},
+		{name: "png signature only", data: png[:8]},
+		{name: "png truncated inside exif chunk", data: png[:60]},
+		{name: "png with oversized chunk length", data: corruptPNGLength},
+		{name: "webp header only", data: webp[:12]},
+		{name: "webp truncated inside exif chunk", data: webp[:len(webp)-10]},
+		{name: "webp with oversized chunk length", data: corruptWebPLength},
+		{name: "heif ftyp only", data: heic[:24]},
+		{name: "heif truncated meta", data: heic[:100]},
+		{name: "heif without exif item", data: heicWithoutExifItem},
+		{name: "heif truncated mdat", data: heic[:len(heic)-20]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if payload := extractEXIFPayload(tt.data); payload != nil {
+				t.Fatalf("extractEXIFPayload() = %q, want nil", payload)
+			}
+		})
+	}
+}
+
+func TestExtractEXIFPayloadPassthrough(t *testing.T) {
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	for _, data := range [][]byte{tiff, jpeg} {
+		if payload := extractEXIFPayload(data); !bytes.Equal(payload, data) {
+			t.Fatalf("extractEXIFPayload(%q) = %q, want input", data, payload)
+		}
+	}
+}
+
+type ilocItem struct {
+	id                 uint32
+	constructionMethod uint16
+	baseOffset         uint64
+	extents            [][2]uint64
+}
+
+func buildILOC(version byte, offsetSize, lengthSize, baseOffsetSize, indexSize int, items []ilocItem) *isobmffBox {
+	var body bytes.Buffer
+	putUint := func(value uint64, size int) {
+		for i := size - 1; i >= 0; i-- {
+			body.WriteByte(byte(value >> (8 * i)))
+		}
+	}
+
+	body.Write([]byte{version, 0, 0, 0})
+	putUint(uint64(offsetSize<<12|lengthSize<<8|baseOffsetSize<<4|indexSize), 2)
+	if version < 2 {
+		putUint(uint64(len(items)), 2)
+	} else {
+		putUint(uint64(len(items)), 4)
+	}
+	for _, item := range items {
+		if version < 2 {
+			putUint(uint64(item.id), 2)
+		} else {
+			putUint(uint64(item.id), 4)
+		}
+		if version == 1 || version == 2 {
+			putUint(uint64(item.constructionMethod), 2)
+		}
+		putUint(0, 2)
+		putUint(item.baseOffset, baseOffsetSize)
+		putUint(uint64(len(item.extents)), 2)
+		for _, extent := range item.extents {
+			if version == 1 || version == 2 {
+				putUint(0, indexSize)
+			}
+			putUint(extent[0], offsetSize)
+			putUint(extent[1], lengthSize)
+		}
+	}
+	return &isobmffBox{boxType: "iloc", body: body.Bytes()}
+}
+
+func TestLocateISOBMFFItem(t *testing.T) {
+	data := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	idat := []byte("IDAT-PAYLOAD")
+
+	truncated := buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 2, extents: [][2]uint64{{4, 6}}}})
+	truncated.body = truncated.body[:len(truncated.body)-3]
+
+	tests := []struct {
+		name   string
+		iloc   *isobmffBox
+		itemID uint32
+		want   [][]byte
+	}{
+		{
+			name:   "version 0 single extent",
+			iloc:   buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 1, extents: [][2]uint64{{0, 4}}}, {id: 2, extents: [][2]uint64{{10, 6}}}}),
+			itemID: 2,
+			want:   [][]byte{[]byte("abcdef")},
+		},
+		{
+			name:   "base offset and multiple extents",
+			iloc:   buildILOC(0, 2, 2, 4, 0, []ilocItem{{id: 7, baseOffset: 10, extents: [][2]uint64{{0, 3}, {23, 3}}}}),
+			itemID: 7,
+			want:   [][]byte{[]byte("abc"), []byte("xyz")},
+		},
+		{
+			name:   "zero length extends to end",
+			iloc:   buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 3, extents: [][2]uint64{{32, 0}}}}),
+			itemID: 3,
+			want:   [][]byte{[]byte("wxyz")},
+		},
+		{
+			name:   "version 1 idat construction",
+			iloc:   buildILOC(1, 4, 4, 0, 0, []ilocItem{{id: 5, constructionMethod: 1, extents: [][2]uint64{{5, 7}}}}),
+			itemID: 5,
+			want:   [][]byte{[]byte("PAYLOAD")},
+		},
+		{
+			name:   "version 1 with extent index",
+			iloc:   buildILOC(1, 4, 4, 0, 2, []ilocItem{{id: 5, extents: [][2]uint64{{0, 2}}}}),
+			itemID: 5,
+			want:   [][]byte{[]byte("01")},
+		},
+		{
+			name:   "version 2 wide item ids",
+			iloc:   buildILOC(2, 8, 8, 0, 0, []ilocItem{{id: 70000, extents: [][2]uint64{{1, 2}}}}),
+			itemID: 70000,
+			want:   [][]byte{[]byte("12")},
+		},
+		{
+			name:   "item construction method unsupported",
+			iloc:   buildILOC(1, 4, 4, 0, 0, []ilocItem{{id: 5, constructionMethod: 2, extents: [][2]uint64{{0, 2}}}}),
+			itemID: 5,
+		},
+		{
+			name:   "item missing",
+			iloc:   buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 1, extents: [][2]uint64{{0, 4}}}}),
+			itemID: 9,
+		},
+		{
+			name:   "extent past end",
+			iloc:   buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 1, extents: [][2]uint64{{30, 10}}}}),
+			itemID: 1,
+		},
+		{
+			name:   "extent offset past end",
+			iloc:   buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 1, extents: [][2]uint64{{100, 1}}}}),
+			itemID: 1,
+		},
+		{
+			name:   "extent length overflow",
+			iloc:   buildILOC(0, 8, 8, 0, 0, []ilocItem{{id: 1, extents: [][2]uint64{{4, ^uint64(0)}}}}),
+			itemID: 1,
+		},
+		{
+			name:   "idat extent past end",
+			iloc:   buildILOC(1, 4, 4, 0, 0, []ilocItem{{id: 5, constructionMethod: 1, extents: [][2]uint64{{8, 8}}}}),
+			itemID: 5,
+		},
+		{
+			name:   "truncated iloc",
+			iloc:   truncated,
+			itemID: 2,
+		},
+		{
+			name:   "short iloc",
+			iloc:   &isobmffBox{boxType: "iloc", body: []byte{0, 0, 0, 0, 0x44}},
+			itemID: 1,
+		},
+		{
+			name:   "item count beyond body",
+			iloc:   &isobmffBox{boxType: "iloc", body: []byte{0, 0, 0, 0, 0x44, 0x00, 0xFF, 0xFF}},
+			itemID: 1,
+		},
+		{
+			name:   "missing iloc",
+			itemID: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := locateISOBMFFItem(data, idat, tt.iloc, tt.itemID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("locateISOBMFFItem() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tt.want[i]) {
+					t.Fatalf("locateISOBMFFItem() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
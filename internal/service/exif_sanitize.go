@@ -0,0 +1,308 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+)
+
+const (
+	exifTagExifIFD      = 0x8769
+	exifTagGPSIFD       = 0x8825
+	tiffMaxIFDs         = 64
+	metadataSniffBytes  = 64
+	pngRawProfilePrefix = "raw profile type "
+)
+
+var (
+	exifSensitiveTags = map[uint16]struct{}{
+		0x927C: {},
+		0xA420: {},
+		0xA430: {},
+		0xA431: {},
+		0xA435: {},
+		0xC62F: {},
+	}
+	tiffTypeSizes = map[uint16]uint64{
+		1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8, 13: 4,
+	}
+	xmpSensitiveProperties = []string{
+		"exif:GPSVersionID", "exif:GPSLatitude", "exif:GPSLongitude", "exif:GPSAltitudeRef", "exif:GPSAltitude",
+		"exif:GPSTimeStamp", "exif:GPSSatellites", "exif:GPSStatus", "exif:GPSMeasureMode", "exif:GPSDOP",
+		"exif:GPSSpeedRef", "exif:GPSSpeed", "exif:GPSTrackRef", "exif:GPSTrack", "exif:GPSImgDirectionRef",
+		"exif:GPSImgDirection", "exif:GPSMapDatum", "exif:GPSDestLatitude", "exif:GPSDestLongitude",
+		"exif:GPSDestBearingRef", "exif:GPSDestBearing", "exif:GPSDestDistanceRef", "exif:GPSDestDistance",
+		"exif:GPSProcessingMethod", "exif:GPSAreaInformation", "exif:GPSDifferential", "exif:GPSHPositioningError",
+		"exif:ImageUniqueID", "exifEX:ImageUniqueID", "exifEX:CameraOwnerName", "exifEX:BodySerialNumber",
+		"exifEX:LensSerialNumber", "aux:SerialNumber", "aux:LensSerialNumber", "aux:OwnerName",
+	}
+	xmpSensitivePatterns = buildXMPPropertyPatterns(xmpSensitiveProperties)
+	pngRawProfileTypes   = map[string]struct{}{"exif": {}, "xmp": {}, "app1": {}, "8bim": {}}
+	heifBrands           = map[string]struct{}{
+		"heic": {}, "heix": {}, "heim": {}, "heis": {}, "hevc": {}, "hevx": {}, "hevm": {}, "hevs": {},
+		"mif1": {}, "msf1": {}, "avif": {}, "avis": {},
+	}
+)
+
+func hasStrippableMetadata(header []byte) bool {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8}):
+		return true
+	case bytes.HasPrefix(header, pngSignature):
+		return true
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return true
+	case isTIFFPayload(header):
+		return true
+	default:
+		return isHEIFHeader(header)
+	}
+}
+
+func isHEIFHeader(header []byte) bool {
+	if len(header) < 12 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+	end := int(binary.BigEndian.Uint32(header[0:4]))
+	if end > len(header) || end < 12 {
+		end = len(header)
+	}
+	for pos := 8; pos+4 <= end; pos += 4 {
+		if pos == 12 {
+			continue
+		}
+		if _, ok := heifBrands[string(header[pos:pos+4])]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizeImageMetadata(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	var err error
+	switch {
+	case bytes.HasPrefix(out, []byte{0xFF, 0xD8}):
+		err = sanitizeJPEGMetadata(out)
+	case bytes.HasPrefix(out, pngSignature):
+		out, err = sanitizePNGMetadata(out)
+	case len(out) >= 12 && string(out[0:4]) == "RIFF" && string(out[8:12]) == "WEBP":
+		err = sanitizeWebPMetadata(out)
+	case isTIFFPayload(out):
+		scrubEXIFTIFF(out)
+	case isHEIFHeader(out):
+		sanitizeISOBMFFMetadata(out)
+	default:
+		return data
+	}
+	if err != nil {
+		scrubMetadataAnywhere(out)
+	}
+	return out
+}
+
+func scrubMetadataAnywhere(data []byte) {
+	for _, magic := range [][]byte{tiffLittleMagic, tiffBigMagic} {
+		for pos := 0; pos < len(data); {
+			index := bytes.Index(data[pos:], magic)
+			if index < 0 {
+				break
+			}
+			scrubEXIFTIFF(data[pos+index:])
+			pos += index + len(magic)
+		}
+	}
+	scrubXMPPacket(data)
+}
+
+func sanitizeJPEGMetadata(data []byte) error {
+	segments, _, err := splitJPEGSegments(data)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if segment.marker != 0xE1 || len(segment.data) < 4 {
+			continue
+		}
+		body := segment.data[4:]
+		switch {
+		case bytes.HasPrefix(body, exifHeader):
+			scrubEXIFTIFF(body[len(exifHeader):])
+		case bytes.HasPrefix(body, []byte(xmpJPEGHeader)):
+			scrubXMPPacket(body[len(xmpJPEGHeader):])
+		}
+	}
+	return nil
+}
+
+func sanitizePNGMetadata(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	pos := len(pngSignature)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return append(out, data[pos:]...), ErrEmbedMetadataMalformed
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		body := data[pos+8 : end-4]
+		switch chunkType {
+		case "eXIf":
+			scrubEXIFTIFF(normalizeEXIFPayload(body))
+		case "iTXt":
+			keyword, rest, found := bytes.Cut(body, []byte{0})
+			if found && string(keyword) == xmpPNGKeyword && len(rest) >= 2 && rest[0] == 0 {
+				scrubXMPPacket(rest[2:])
+			}
+		}
+		if isPNGRawProfileChunk(chunkType, body) {
+			pos = end
+			continue
+		}
+		if chunkType == "eXIf" || chunkType == "iTXt" {
+			binary.BigEndian.PutUint32(data[end-4:end], crc32.ChecksumIEEE(data[pos+4:end-4]))
+		}
+		out = append(out, data[pos:end]...)
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return append(out, data[pos:]...), nil
+}
+
+func isPNGRawProfileChunk(chunkType string, body []byte) bool {
+	if chunkType != "tEXt" && chunkType != "zTXt" && chunkType != "iTXt" {
+		return false
+	}
+	keyword, _, found := bytes.Cut(body, []byte{0})
+	name, ok := strings.CutPrefix(strings.ToLower(string(keyword)), pngRawProfilePrefix)
+	if !found || !ok {
+		return false
+	}
+	_, raw := pngRawProfileTypes[name]
+	return raw
+}
+
+func sanitizeWebPMetadata(data []byte) error {
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkType := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size
+		if size < 0 || end > len(data) {
+			return ErrEmbedMetadataMalformed
+		}
+		switch chunkType {
+		case "EXIF":
+			scrubEXIFTIFF(normalizeEXIFPayload(data[pos+8 : end]))
+		case "XMP ":
+			scrubXMPPacket(data[pos+8 : end])
+		}
+		pos = end + size%2
+	}
+	return nil
+}
+
+func sanitizeISOBMFFMetadata(data []byte) {
+	extents := locateISOBMFFEXIF(data)
+	if extents == nil {
+		return
+	}
+	payload := bytes.Join(extents, nil)
+	scrubEXIFTIFF(isobmffEXIFPayload(payload))
+	offset := 0
+	for _, extent := range extents {
+		offset += copy(extent, payload[offset:])
+	}
+}
+
+func scrubXMPPacket(packet []byte) {
+	for _, pattern := range xmpSensitivePatterns {
+		for _, loc := range pattern.FindAllIndex(packet, -1) {
+			for i := loc[0]; i < loc[1]; i++ {
+				packet[i] = ' '
+			}
+		}
+	}
+}
+
+type tiffScrubber struct {
+	data    []byte
+	order   binary.ByteOrder
+	visited map[int]struct{}
+}
+
+func scrubEXIFTIFF(payload []byte) {
+	if !isTIFFPayload(payload) || len(payload) < 8 {
+		return
+	}
+	scrubber := &tiffScrubber{data: payload, order: binary.LittleEndian, visited: make(map[int]struct{})}
+	if payload[0] == 'M' {
+		scrubber.order = binary.BigEndian
+	}
+	offset := int(scrubber.order.Uint32(payload[4:8]))
+	for i := 0; i < tiffMaxIFDs && offset > 0; i++ {
+		offset = scrubber.scrubIFD(offset, false)
+	}
+}
+
+func (t *tiffScrubber) scrubIFD(offset int, wipe bool) int {
+	if offset < 8 || offset+2 > len(t.data) {
+		return 0
+	}
+	if _, seen := t.visited[offset]; seen {
+		return 0
+	}
+	t.visited[offset] = struct{}{}
+
+	count := int(t.order.Uint16(t.data[offset : offset+2]))
+	entriesEnd := offset + 2 + count*12
+	if entriesEnd+4 > len(t.data) {
+		return 0
+	}
+	next := int(t.order.Uint32(t.data[entriesEnd : entriesEnd+4]))
+	for i := 0; i < count; i++ {
+		entry := t.data[offset+2+i*12 : offset+14+i*12]
+		tag := t.order.Uint16(entry[0:2])
+		value := t.entryValue(entry)
+		switch {
+		case wipe:
+			clear(value)
+			clear(entry)
+		case tag == exifTagExifIFD:
+			t.scrubIFD(int(t.order.Uint32(entry[8:12])), false)
+		case tag == exifTagGPSIFD:
+			t.scrubIFD(int(t.order.Uint32(entry[8:12])), true)
+		case tag == tiffTagXMLPacket:
+			scrubXMPPacket(value)
+		default:
+			if _, sensitive := exifSensitiveTags[tag]; sensitive {
+				clear(value)
+			}
+		}
+	}
+	if wipe {
+		clear(t.data[offset : entriesEnd+4])
+		return 0
+	}
+	return next
+}
+
+func (t *tiffScrubber) entryValue(entry []byte) []byte {
+	size, ok := tiffTypeSizes[t.order.Uint16(entry[2:4])]
+	if !ok {
+		return nil
+	}
+	total := size * uint64(t.order.Uint32(entry[4:8]))
+	if total <= 4 {
+		return entry[8 : 8+total]
+	}
+	start := uint64(t.order.Uint32(entry[8:12]))
+	if start+total > uint64(len(t.data)) {
+		return nil
+	}
+	return t.data[start : start+total]
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+const (
+	testBodySerial = "BODY-SN-4821"
+	testLensSerial = "LENS-SN-7730"
+	testXMPGPS     = "35,41.1234N"
+	testXMPSerial  = "AUX-SN-0042"
+)
+
+var testSensitiveXMP = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?><x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:exif="http://ns.adobe.com/exif/1.0/" xmlns:aux="http://ns.adobe.com/exif/1.0/aux/" aux:SerialNumber="` + testXMPSerial + `"><dc:format>image/test</dc:format><exif:GPSLatitude>` + testXMPGPS + `</exif:GPSLatitude><exif:GPSLongitude>139,41.5678E</exif:GPSLongitude></rdf:Description></rdf:RDF></x:xmpmeta><?xpacket end="w"?>`
+
+type testTIFFEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+	ifd   []testTIFFEntry
+}
+
+type testByteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+type testTIFFWriter struct {
+	order testByteOrder
+	data  []byte
+}
+
+func (w *testTIFFWriter) align() {
+	if len(w.data)%2 == 1 {
+		w.data = append(w.data, 0)
+	}
+}
+
+func (w *testTIFFWriter) writeIFD(entries []testTIFFEntry) uint32 {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+	w.align()
+	offset := len(w.data)
+	w.data = append(w.data, make([]byte, 2+len(entries)*12+4)...)
+	w.order.PutUint16(w.data[offset:], uint16(len(entries)))
+	for i, entry := range entries {
+		value := entry.value
+		if entry.ifd != nil {
+			value = w.order.AppendUint32(nil, w.writeIFD(entry.ifd))
+		}
+		slot := offset + 2 + i*12
+		w.order.PutUint16(w.data[slot:], entry.tag)
+		w.order.PutUint16(w.data[slot+2:], entry.typ)
+		w.order.PutUint32(w.data[slot+4:], entry.count)
+		if len(value) <= 4 {
+			copy(w.data[slot+8:slot+12], value)
+			continue
+		}
+		w.align()
+		w.order.PutUint32(w.data[slot+8:], uint32(len(w.data)))
+		w.data = append(w.data, value...)
+	}
+	return uint32(offset)
+}
+
+func testASCII(tag uint16, text string) testTIFFEntry {
+	return testTIFFEntry{tag: tag, typ: 2, count: uint32(len(text) + 1), value: append([]byte(text), 0)}
+}
+
+func testRationals(order testByteOrder, tag uint16, values ...uint32) testTIFFEntry {
+	value := make([]byte, 0, len(values)*8)
+	for _, v := range values {
+		value = order.AppendUint32(value, v)
+		value = order.AppendUint32(value, 1)
+	}
+	return testTIFFEntry{tag: tag, typ: 5, count: uint32(len(values)), value: value}
+}
+
+func testShort(order testByteOrder, tag uint16, value uint16) testTIFFEntry {
+	return testTIFFEntry{tag: tag, typ: 3, count: 1, value: order.AppendUint16(nil, value)}
+}
+
+func testLong(order testByteOrder, tag uint16, value uint32) testTIFFEntry {
+	return testTIFFEntry{tag: tag, typ: 4, count: 1, value: order.AppendUint32(nil, value)}
+}
+
+func buildSensitiveTIFF(order testByteOrder, withImage bool) []byte {
+	w := &testTIFFWriter{order: order}
+	if order == testByteOrder(binary.LittleEndian) {
+		w.data = append(w.data, tiffLittleMagic...)
+	} else {
+		w.data = append(w.data, tiffBigMagic...)
+	}
+	w.data = append(w.data, 0, 0, 0, 0)
+
+	ifd0 := []testTIFFEntry{
+		testASCII(0x010F, "IllustCam"),
+		testASCII(0x0110, "Nest-1"),
+		{tag: exifTagExifIFD, typ: 4, count: 1, ifd: []testTIFFEntry{
+			testASCII(0xA431, testBodySerial),
+			testASCII(0xA435, testLensSerial),
+		}},
+		{tag: exifTagGPSIFD, typ: 4, count: 1, ifd: []testTIFFEntry{
+			testASCII(0x0001, "N"),
+			testRationals(order, 0x0002, 35, 41, 7),
+			testASCII(0x0003, "E"),
+			testRationals(order, 0x0004, 139, 41, 30),
+		}},
+		testXMPTIFFEntry(),
+	}
+	if withImage {
+		pixels := len(w.data)
+		w.data = append(w.data, 0x10, 0x20, 0x30, 0x40)
+		ifd0 = append(ifd0,
+			testShort(order, 256, 2),
+			testShort(order, 257, 2),
+			testShort(order, 258, 8),
+			testShort(order, 259, 1),
+			testShort(order, 262, 1),
+			testLong(order, 273, uint32(pixels)),
+			testShort(order, 277, 1),
+			testShort(order, 278, 2),
+			testLong(order, 279, 4),
+		)
+	}
+	ifdOffset := w.writeIFD(ifd0)
+	order.PutUint32(w.data[4:8], ifdOffset)
+	return w.data
+}
+
+func testXMPTIFFEntry() testTIFFEntry {
+	return testTIFFEntry{tag: tiffTagXMLPacket, typ: 1, count: uint32(len(testSensitiveXMP)), value: []byte(testSensitiveXMP)}
+}
+
+func assertSensitiveMetadataRemoved(t *testing.T, original, sanitized []byte) {
+	t.Helper()
+	before := extractImageEXIF(original)
+	if before.GPSLatitude == nil || before.CameraMake != "IllustCam" {
+		t.Fatalf("fixture EXIF = %+v, want GPS and camera make", before)
+	}
+	for _, secret := range []string{testBodySerial, testLensSerial, testXMPGPS, testXMPSerial} {
+		if !bytes.Contains(original, []byte(secret)) {
+			t.Fatalf("fixture is missing %q", secret)
+		}
+		if bytes.Contains(sanitized, []byte(secret)) {
+			t.Fatalf("sanitized output still contains %q", secret)
+		}
+	}
+	after := extractImageEXIF(sanitized)
+	if after.GPSLatitude != nil || after.GPSLongitude != nil {
+		t.Fatalf("sanitized EXIF still has GPS: %+v", after)
+	}
+	if after.CameraMake != "IllustCam" || after.CameraModel != "Nest-1" {
+		t.Fatalf("sanitized EXIF = %+v, want camera make and model kept", after)
+	}
+	if !bytes.Contains(sanitized, []byte("<dc:format>image/test</dc:format>")) {
+		t.Fatalf("sanitized output dropped non-sensitive XMP")
+	}
+}
+
+func buildSensitiveJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	app1 := func(payload []byte) []byte {
+		segment := []byte{0xFF, 0xE1, 0, 0}
+		binary.BigEndian.PutUint16(segment[2:4], uint16(len(payload)+2))
+		return append(segment, payload...)
+	}
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1(append(append([]byte{}, exifHeader...), buildSensitiveTIFF(binary.BigEndian, false)...))...)
+	out = append(out, app1(append([]byte(xmpJPEGHeader), testSensitiveXMP...))...)
+	return append(out, data[2:]...)
+}
+
+func buildSensitivePNG(t *testing.T) []byte {
+	t.Helper()
+	data := encodeTestPNG(t, 3, 2)
+	ihdrEnd := len(pngSignature) + 12 + int(binary.BigEndian.Uint32(data[8:12]))
+
+	rawEXIF := append(append([]byte{}, exifHeader...), buildSensitiveTIFF(binary.LittleEndian, false)...)
+	rawText := func(name string, payload []byte) []byte {
+		return []byte("\n" + name + "\n" + strings.Repeat(" ", 4) + "0\n" + hex.EncodeToString(payload) + "\n")
+	}
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, _ = zw.Write(rawText("xmp", []byte(testSensitiveXMP)))
+	zw.Close()
+
+	chunks := [][]byte{
+		buildPNGChunk("eXIf", buildSensitiveTIFF(binary.BigEndian, false)),
+		buildPNGChunk("iTXt", buildITXtData(xmpPNGKeyword, testSensitiveXMP)),
+		buildPNGChunk("tEXt", append([]byte("Raw profile type exif\x00"), rawText("exif", rawEXIF)...)),
+		buildPNGChunk("zTXt", append([]byte("Raw profile type xmp\x00\x00"), compressed.Bytes()...)),
+		buildPNGChunk("tEXt", []byte("Comment\x00kept")),
+	}
+	out := append([]byte{}, data[:ihdrEnd]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return append(out, data[ihdrEnd:]...)
+}
+
+func buildSensitiveWebP(t *testing.T) []byte {
+	t.Helper()
+	image := webpChunks(t, readTestdata(t, "webp_exif.webp"))["VP8L"][0]
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	appendChunk := func(chunkType string, body []byte) {
+		out = append(out, chunkType...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+		out = append(out, body...)
+		if len(body)%2 == 1 {
+			out = append(out, 0)
+		}
+	}
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagXMP | 0x08
+	appendChunk("VP8X", vp8x)
+	appendChunk("VP8L", image)
+	appendChunk("EXIF", buildSensitiveTIFF(binary.LittleEndian, false))
+	appendChunk("XMP ", []byte(testSensitiveXMP))
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out
+}
+
+func testISOBMFFBox(boxType string, body []byte) []byte {
+	box := binary.BigEndian.AppendUint32(nil, uint32(len(body)+8))
+	box = append(box, boxType...)
+	return append(box, body...)
+}
+
+func buildSensitiveHEIF() []byte {
+	payload := append([]byte("\x00\x00\x00\x06Exif\x00\x00"), buildSensitiveTIFF(binary.BigEndian, false)...)
+	ftyp := testISOBMFFBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic"))
+	buildMeta := func(payloadOffset uint64) []byte {
+		infe := testISOBMFFBox("infe", []byte("\x02\x00\x00\x00\x00\x01\x00\x00Exif\x00"))
+		iinf := testISOBMFFBox("iinf", append([]byte("\x00\x00\x00\x00\x00\x01"), infe...))
+		iloc := buildILOC(0, 4, 4, 0, 0, []ilocItem{{id: 1, extents: [][2]uint64{{payloadOffset, uint64(len(payload))}}}})
+		ispe := testISOBMFFBox("ispe", []byte("\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x03"))
+		iprp := testISOBMFFBox("iprp", testISOBMFFBox("ipco", ispe))
+		body := []byte{0, 0, 0, 0}
+		body = append(body, testISOBMFFBox("hdlr", []byte("\x00\x00\x00\x00\x00\x00\x00\x00pict\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"))...)
+		body = append(body, iinf...)
+		body = append(body, testISOBMFFBox("iloc", iloc.body)...)
+		body = append(body, iprp...)
+		return testISOBMFFBox("meta", body)
+	}
+	meta := buildMeta(0)
+	meta = buildMeta(uint64(len(ftyp) + len(meta) + 8))
+	out := append(append([]byte{}, ftyp...), meta...)
+	return append(out, testISOBMFFBox("mdat", payload)...)
+}
+
+func TestSanitizeImageMetadataRemovesSensitiveFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		parses func(data []byte) error
+	}{
+		{name: "jpeg", data: buildSensitiveJPEG(t), parses: func(data []byte) error {
+			_, err := jpeg.Decode(bytes.NewReader(data))
+			return err
+		}},
+		{name: "png", data: buildSensitivePNG(t), parses: func(data []byte) error {
+			_, err := png.Decode(bytes.NewReader(data))
+			return err
+		}},
+		{name: "webp", data: buildSensitiveWebP(t), parses: func(data []byte) error {
+			_, err := webp.Decode(bytes.NewReader(data))
+			return err
+		}},
+		{name: "tiff little endian", data: buildSensitiveTIFF(binary.LittleEndian, true), parses: func(data []byte) error {
+			_, err := tiff.Decode(bytes.NewReader(data))
+			return err
+		}},
+		{name: "tiff big endian", data: buildSensitiveTIFF(binary.BigEndian, true), parses: func(data []byte) error {
+			_, err := tiff.Decode(bytes.NewReader(data))
+			return err
+		}},
+		{name: "heif", data: buildSensitiveHEIF(), parses: func(data []byte) error {
+			if _, _, ok := readHEIFDimensions(data); !ok {
+				return ErrImageMalformed
+			}
+			return nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.parses(tt.data); err != nil {
+				t.Fatalf("fixture does not parse: %v", err)
+			}
+			if !hasStrippableMetadata(tt.data[:metadataSniffBytes]) {
+				t.Fatalf("hasStrippableMetadata() = false, want true")
+			}
+			sanitized := sanitizeImageMetadata(tt.data)
+			assertSensitiveMetadataRemoved(t, tt.data, sanitized)
+			if err := tt.parses(sanitized); err != nil {
+				t.Fatalf("sanitized output does not parse: %v", err)
+			}
+			if again := sanitizeImageMetadata(sanitized); !bytes.Equal(again, sanitized) {
+				t.Fatalf("sanitizing twice changed the output")
+			}
+		})
+	}
+}
+
+func TestSanitizePNGMetadataDropsRawProfiles(t *testing.T) {
+	sanitized := sanitizeImageMetadata(buildSensitivePNG(t))
+	texts := extractPNGTexts(sanitized)
+	for _, key := range []string{"raw profile type exif", "raw profile type xmp"} {
+		if _, ok := texts[key]; ok {
+			t.Fatalf("sanitized PNG still has %q chunk", key)
+		}
+	}
+	if texts["comment"] != "kept" {
+		t.Fatalf("sanitized PNG texts = %v, want comment kept", texts)
+	}
+}
+
+func TestSanitizeImageMetadataMalformedJPEG(t *testing.T) {
+	data := buildSensitiveJPEG(t)
+	sos := bytes.Index(data, []byte{0xFF, 0xDB})
+	broken := append([]byte{}, data[:sos]...)
+	broken = append(broken, 0xFF, 0xE2, 0xFF, 0xF0)
+	broken = append(broken, data[sos:]...)
+	if _, _, err := splitJPEGSegments(broken); err == nil {
+		t.Fatalf("fixture is not malformed")
+	}
+
+	sanitized := sanitizeImageMetadata(broken)
+	if len(sanitized) != len(broken) {
+		t.Fatalf("sanitized length = %d, want %d", len(sanitized), len(broken))
+	}
+	for _, secret := range []string{testBodySerial, testLensSerial, testXMPGPS, testXMPSerial} {
+		if bytes.Contains(sanitized, []byte(secret)) {
+			t.Fatalf("sanitized malformed JPEG still contains %q", secret)
+		}
+	}
+}
+
+func TestHasStrippableMetadata(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{name: "heic", header: readTestdata(t, "heic_exif.heic")[:metadataSniffBytes], want: true},
+		{name: "avif", header: readTestdata(t, "avif_exif.avif")[:metadataSniffBytes], want: true},
+		{name: "mp4", header: testISOBMFFBox("ftyp", []byte("isom\x00\x00\x02\x00isomiso2avc1mp41")), want: false},
+		{name: "quicktime", header: testISOBMFFBox("ftyp", []byte("qt  \x00\x00\x02\x00qt  ")), want: false},
+		{name: "psd", header: testPSDHeader(4, 3), want: false},
+		{name: "webm", header: []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81}, want: false},
+		{name: "gif", header: []byte("GIF89a\x01\x00\x01\x00"), want: false},
+		{name: "empty", header: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasStrippableMetadata(tt.header); got != tt.want {
+				t.Fatalf("hasStrippableMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ffmpegCommand  = "ffmpeg"
+	ffprobeCommand = "ffprobe"
+)
+
+type ffprobeResult struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+func testFFmpegCommand(ctx context.Context) (string, error) {
+	out, err := runConverterProbe(ctx, ffmpegCommand, []string{"-version"}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return ffmpegCommand, nil
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+func runFFmpeg(ctx context.Context, args ...string) error {
+	fullArgs := append([]string{"-hide_banner", "-loglevel", "error", "-y"}, args...)
+	_, err := runConverter(ctx, ffmpegCommand, fullArgs, nil)
+	return err
+}
+
+func probeVideoWithFFprobe(ctx context.Context, data []byte, ext string) (videoInfo, error) {
+	tempDir, err := os.MkdirTemp("", "illust-nest-ffprobe-*")
+	if err != nil {
+		return videoInfo{}, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputPath := filepath.Join(tempDir, "input"+ext)
+	if err := os.WriteFile(inputPath, data, 0600); err != nil {
+		return videoInfo{}, err
+	}
+	out, err := runConverter(ctx, ffprobeCommand, []string{
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=codec_type,width,height:format=duration",
+		"-of", "json",
+		inputPath,
+	}, nil)
+	if err != nil {
+		return videoInfo{}, err
+	}
+
+	var result ffprobeResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return videoInfo{}, err
+	}
+	for _, stream := range result.Streams {
+		if stream.CodecType != "video" || stream.Width <= 0 || stream.Height <= 0 {
+			continue
+		}
+		info := videoInfo{Width: stream.Width, Height: stream.Height}
+		if seconds, err := strconv.ParseFloat(result.Format.Duration, 64); err == nil && seconds > 0 {
+			info.DurationMs = int(seconds * 1000)
+		}
+		return info, nil
+	}
+	return videoInfo{}, errors.New("ffprobe: video stream not found")
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/webp"
+)
+
+const (
+	xmpPNGKeyword      = "XML:com.adobe.xmp"
+	xmpJPEGHeader      = "http://ns.adobe.com/xap/1.0/\x00"
+	xmpJPEGMaxSegment  = 65533
+	tiffTagXMLPacket   = 700
+	webpFlagXMP        = 0x04
+	xmpPacketHeader    = "<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n"
+	xmpPacketTrailer   = "\n<?xpacket end=\"w\"?>"
+	xmpRDFNamespace    = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmpDescriptionAttr = ` xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:Iptc4xmpExt="http://iptc.org/std/Iptc4xmpExt/2008-02-29/"`
+)
+
+var (
+	xmpManagedProperties = []string{"dc:title", "dc:description", "dc:subject", "xmp:Rating", "Iptc4xmpExt:AIPromptInformation", "Iptc4xmpExt:AISystemUsed"}
+	xmpManagedPatterns   = buildXMPPropertyPatterns(xmpManagedProperties)
+	xmpRDFOpenPattern    = regexp.MustCompile(`<rdf:RDF\b[^>]*>`)
+	xmpEmptyDescPattern  = regexp.MustCompile(`<rdf:Description(?:\s+(?:rdf:about|xmlns(?::[\w.-]+)?)="[^"]*")*\s*(?:/>|>\s*</rdf:Description>)`)
+)
+
+type EmbeddedFileMetadata struct {
+	Title       string
+	Description string
+	Tags        []string
+	Rating      int
+	AIMetadata  *AIImageMetadata
+}
+
+func NewWorkEmbeddedFileMetadata(work *WorkInfo, image *ImageInfo) *EmbeddedFileMetadata {
+	tags := make([]string, 0, len(work.Tags))
+	for _, tag := range work.Tags {
+		if tag != nil && strings.TrimSpace(tag.Name) != "" {
+			tags = append(tags, tag.Name)
+		}
+	}
+	metadata := &EmbeddedFileMetadata{
+		Title:       work.Title,
+		Description: work.Description,
+		Tags:        tags,
+		Rating:      work.Rating,
+	}
+	if image != nil {
+		metadata.AIMetadata = image.AIMetadata
+	}
+	return metadata
+}
+
+func (r *ExportImageRecord) EmbeddedMetadata() *EmbeddedFileMetadata {
+	return &EmbeddedFileMetadata{
+		Title:       r.Title,
+		Description: r.Description,
+		Tags:        r.TagNames,
+		Rating:      r.Rating,
+		AIMetadata:  r.AIMetadata,
+	}
+}
+
+func EmbedFileMetadata(data []byte, metadata *EmbeddedFileMetadata) []byte {
+	if metadata == nil {
+		return data
+	}
+
+	var (
+		result []byte
+		err    error
+	)
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		result, err = embedPNGMetadata(data, metadata)
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		result, err = embedJPEGMetadata(data, metadata)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		result, err = embedWebPMetadata(data, metadata)
+	case bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")):
+		result, err = embedTIFFMetadata(data, metadata)
+	default:
+		return data
+	}
+	if err != nil {
+		log.Printf("Failed to embed metadata into download: %v", err)
+		return data
+	}
+	return result
+}
+
+func CanEmbedFileMetadata(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".webp", ".tif", ".tiff":
+		return true
+	default:
+		return false
+	}
+}
+
+func buildXMPPacket(existing []byte, metadata *EmbeddedFileMetadata) []byte {
+	var description bytes.Buffer
+	description.WriteString(`<rdf:Description rdf:about=""` + xmpDescriptionAttr + `>`)
+	if title := strings.TrimSpace(metadata.Title); title != "" {
+		description.WriteString(`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">`)
+		writeXMLText(&description, title)
+		description.WriteString(`</rdf:li></rdf:Alt></dc:title>`)
+	}
+	if text := strings.TrimSpace(metadata.Description); text != "" {
+		description.WriteString(`<dc:description><rdf:Alt><rdf:li xml:lang="x-default">`)
+		writeXMLText(&description, text)
+		description.WriteString(`</rdf:li></rdf:Alt></dc:description>`)
+	}
+	if len(metadata.Tags) > 0 {
+		description.WriteString(`<dc:subject><rdf:Bag>`)
+		for _, tag := range metadata.Tags {
+			description.WriteString(`<rdf:li>`)
+			writeXMLText(&description, tag)
+			description.WriteString(`</rdf:li>`)
+		}
+		description.WriteString(`</rdf:Bag></dc:subject>`)
+	}
+	if metadata.Rating > 0 {
+		description.WriteString(`<xmp:Rating>` + strconv.Itoa(metadata.Rating) + `</xmp:Rating>`)
+	}
+	if ai := metadata.AIMetadata; ai != nil {
+		if prompt := strings.TrimSpace(ai.Prompt); prompt != "" {
+			description.WriteString(`<Iptc4xmpExt:AIPromptInformation>`)
+			writeXMLText(&description, prompt)
+			description.WriteString(`</Iptc4xmpExt:AIPromptInformation>`)
+		}
+		if checkpoint := strings.TrimSpace(ai.Checkpoint); checkpoint != "" && checkpoint != aiMetadataUnknownCheckpoint {
+			description.WriteString(`<Iptc4xmpExt:AISystemUsed>`)
+			writeXMLText(&description, checkpoint)
+			description.WriteString(`</Iptc4xmpExt:AISystemUsed>`)
+		}
+	}
+	description.WriteString(`</rdf:Description>`)
+
+	if len(existing) > 0 {
+		packet := string(existing)
+		for _, pattern := range xmpManagedPatterns {
+			packet = pattern.ReplaceAllString(packet, "")
+		}
+		packet = xmpEmptyDescPattern.ReplaceAllString(packet, "")
+		if loc := xmpRDFOpenPattern.FindStringIndex(packet); loc != nil {
+			return []byte(packet[:loc[1]] + description.String() + packet[loc[1]:])
+		}
+	}
+
+	return []byte(xmpPacketHeader +
+		`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="` + xmpRDFNamespace + `">` +
+		description.String() +
+		`</rdf:RDF></x:xmpmeta>` + xmpPacketTrailer)
+}
+
+func buildXMPPropertyPatterns(names []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(names)*2)
+	for _, name := range names {
+		quoted := regexp.QuoteMeta(name)
+		patterns = append(patterns,
+			regexp.MustCompile(`(?s)<`+quoted+`\b[^>]*?(?:/>|>.*?</`+quoted+`>)`),
+			regexp.MustCompile(`\s`+quoted+`="[^"]*"`),
+		)
+	}
+	return patterns
+}
+
+func writeXMLText(buffer *bytes.Buffer, text string) {
+	_ = xml.EscapeText(buffer, []byte(text))
+}
+
+func embedPNGMetadata(data []byte, metadata *EmbeddedFileMetadata) ([]byte, error) {
+	texts := extractPNGTexts(data)
+	chunks := make([][]byte, 0, 4)
+	managed := make(map[string]struct{}, 4)
+	addText := func(keyword, text string) {
+		chunks = append(chunks, buildPNGChunk("iTXt", buildITXtData(keyword, text)))
+		managed[strings.ToLower(keyword)] = struct{}{}
+	}
+	if title := strings.TrimSpace(metadata.Title); title != "" {
+		addText("Title", title)
+	}
+	if description := strings.TrimSpace(metadata.Description); description != "" {
+		addText("Description", description)
+	}
+	if _, exists := texts["parameters"]; !exists && metadata.AIMetadata != nil && strings.TrimSpace(metadata.AIMetadata.Prompt) != "" {
+		addText("parameters", formatA1111Parameters(metadata.AIMetadata))
+	}
+	addText(xmpPNGKeyword, string(buildXMPPacket([]byte(texts[strings.ToLower(xmpPNGKeyword)]), metadata)))
+
+	out := make([]byte, 0, len(data)+4096)
+	out = append(out, pngSignature...)
+	pos := len(pngSignature)
+	inserted := false
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrEmbedMetadataMalformed
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if chunkType == "IDAT" && !inserted {
+			for _, chunk := range chunks {
+				out = append(out, chunk...)
+			}
+			inserted = true
+		}
+		if !isManagedPNGTextChunk(chunkType, data[pos+8:pos+8+length], managed) {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	if !inserted {
+		return nil, ErrEmbedMetadataMalformed
+	}
+	return out, nil
+}
+
+func isManagedPNGTextChunk(chunkType string, body []byte, managed map[string]struct{}) bool {
+	if chunkType != "tEXt" && chunkType != "zTXt" && chunkType != "iTXt" {
+		return false
+	}
+	keyword, _, found := bytes.Cut(body, []byte{0})
+	if !found {
+		return false
+	}
+	_, ok := managed[strings.ToLower(string(keyword))]
+	return ok
+}
+
+func buildITXtData(keyword, text string) []byte {
+	body := make([]byte, 0, len(keyword)+len(text)+5)
+	body = append(body, keyword...)
+	body = append(body, 0, 0, 0, 0, 0)
+	return append(body, text...)
+}
+
+func buildPNGChunk(chunkType string, body []byte) []byte {
+	chunk := make([]byte, 8, len(body)+12)
+	binary.BigEndian.PutUint32(chunk[0:4], uint32(len(body)))
+	copy(chunk[4:8], chunkType)
+	chunk = append(chunk, body...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+type jpegSegment struct {
+	marker byte
+	data   []byte
+}
+
+func embedJPEGMetadata(data []byte, metadata *EmbeddedFileMetadata) ([]byte, error) {
+	segments, rest, err := splitJPEGSegments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []byte
+	kept := make([]jpegSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.data[4:], []byte(xmpJPEGHeader)) {
+			if existing == nil {
+				existing = segment.data[4+len(xmpJPEGHeader):]
+			}
+			continue
+		}
+		kept = append(kept, segment)
+	}
+
+	payload := append([]byte(xmpJPEGHeader), buildXMPPacket(existing, metadata)...)
+	if len(payload)+2 > xmpJPEGMaxSegment {
+		return nil, ErrEmbedMetadataTooLarge
+	}
+	xmpSegment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(xmpSegment[2:4], uint16(len(payload)+2))
+	xmpSegment = append(xmpSegment, payload...)
+
+	out := make([]byte, 0, len(data)+len(xmpSegment))
+	out = append(out, 0xFF, 0xD8)
+	inserted := false
+	for _, segment := range kept {
+		isLeading := segment.marker == 0xE0 || (segment.marker == 0xE1 && bytes.HasPrefix(segment.data[4:], []byte("Exif\x00\x00")))
+		if !inserted && !isLeading {
+			out = append(out, xmpSegment...)
+			inserted = true
+		}
+		out = append(out, segment.data...)
+	}
+	if !inserted {
+		out = append(out, xmpSegment...)
+	}
+	return append(out, rest...), nil
+}
+
+func splitJPEGSegments(data []byte) ([]jpegSegment, []byte, error) {
+	segments := make([]jpegSegment, 0)
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, nil, ErrEmbedMetadataMalformed
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			segments = append(segments, jpegSegment{marker: marker, data: data[pos : pos+2]})
+			pos += 2
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, nil, ErrEmbedMetadataMalformed
+		}
+		segments = append(segments, jpegSegment{marker: marker, data: data[pos:end]})
+		pos = end
+	}
+	return segments, data[pos:], nil
+}
+
+func embedWebPMetadata(data []byte, metadata *EmbeddedFileMetadata) ([]byte, error) {
+	var (
+		existing []byte
+		vp8x     []byte
+	)
+	chunks := make([][]byte, 0)
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkType := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size
+		if size < 0 || end > len(data) {
+			return nil, ErrEmbedMetadataMalformed
+		}
+		body := data[pos+8 : end]
+		chunk := data[pos:end]
+		if size%2 == 1 {
+			chunk = append(append([]byte{}, chunk...), 0)
+			end++
+		}
+		switch chunkType {
+		case "XMP ":
+			existing = body
+		case "VP8X":
+			vp8x = chunk
+		default:
+			chunks = append(chunks, chunk)
+		}
+		pos = end
+	}
+
+	if vp8x == nil {
+		config, err := webp.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		vp8x = make([]byte, 18)
+		copy(vp8x[0:4], "VP8X")
+		binary.LittleEndian.PutUint32(vp8x[4:8], 10)
+		putUint24LE(vp8x[12:15], uint32(config.Width-1))
+		putUint24LE(vp8x[15:18], uint32(config.Height-1))
+	}
+	if len(vp8x) < 9 {
+		return nil, ErrEmbedMetadataMalformed
+	}
+	vp8x = append([]byte{}, vp8x...)
+	vp8x[8] |= webpFlagXMP
+
+	packet := buildXMPPacket(existing, metadata)
+	xmpChunk := make([]byte, 8, len(packet)+9)
+	copy(xmpChunk[0:4], "XMP ")
+	binary.LittleEndian.PutUint32(xmpChunk[4:8], uint32(len(packet)))
+	xmpChunk = append(xmpChunk, packet...)
+	if len(packet)%2 == 1 {
+		xmpChunk = append(xmpChunk, 0)
+	}
+
+	out := make([]byte, 12, len(data)+len(xmpChunk)+len(vp8x))
+	copy(out, data[:12])
+	out = append(out, vp8x...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	out = append(out, xmpChunk...)
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+func putUint24LE(dst []byte, value uint32) {
+	dst[0] = byte(value)
+	dst[1] = byte(value >> 8)
+	dst[2] = byte(value >> 16)
+}
+
+type tiffEntry struct {
+	tag  uint16
+	data []byte
+}
+
+func embedTIFFMetadata(data []byte, metadata *EmbeddedFileMetadata) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, ErrEmbedMetadataMalformed
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+	ifdOffset := int(order.Uint32(data[4:8]))
+	if ifdOffset < 8 || ifdOffset+2 > len(data) {
+		return nil, ErrEmbedMetadataMalformed
+	}
+	count := int(order.Uint16(data[ifdOffset : ifdOffset+2]))
+	entriesEnd := ifdOffset + 2 + count*12
+	if entriesEnd+4 > len(data) {
+		return nil, ErrEmbedMetadataMalformed
+	}
+
+	var existing []byte
+	entries := make([]tiffEntry, 0, count+1)
+	for i := 0; i < count; i++ {
+		entry := data[ifdOffset+2+i*12 : ifdOffset+14+i*12]
+		tag := order.Uint16(entry[0:2])
+		if tag == tiffTagXMLPacket {
+			size := int(order.Uint32(entry[4:8]))
+			if size <= 4 {
+				existing = entry[8 : 8+size]
+			} else if offset := int(order.Uint32(entry[8:12])); offset >= 0 && offset+size <= len(data) {
+				existing = data[offset : offset+size]
+			}
+			continue
+		}
+		entries = append(entries, tiffEntry{tag: tag, data: entry})
+	}
+
+	packet := buildXMPPacket(existing, metadata)
+	out := append(make([]byte, 0, len(data)+len(packet)+len(entries)*12+32), data...)
+	if len(out)%2 == 1 {
+		out = append(out, 0)
+	}
+	packetOffset := len(out)
+	out = append(out, packet...)
+	if len(out)%2 == 1 {
+		out = append(out, 0)
+	}
+
+	xmpEntry := make([]byte, 12)
+	order.PutUint16(xmpEntry[0:2], tiffTagXMLPacket)
+	order.PutUint16(xmpEntry[2:4], 1)
+	order.PutUint32(xmpEntry[4:8], uint32(len(packet)))
+	order.PutUint32(xmpEntry[8:12], uint32(packetOffset))
+	entries = append(entries, tiffEntry{tag: tiffTagXMLPacket, data: xmpEntry})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	newIFDOffset := len(out)
+	if len(entries) > 0xFFFF || uint64(newIFDOffset)+uint64(len(entries))*12+6 > 1<<32-1 {
+		return nil, ErrEmbedMetadataMalformed
+	}
+	out = append(out, 0, 0)
+	order.PutUint16(out[newIFDOffset:], uint16(len(entries)))
+	for _, entry := range entries {
+		out = append(out, entry.data...)
+	}
+	out = append(out, data[entriesEnd:entriesEnd+4]...)
+	order.PutUint32(out[4:8], uint32(newIFDOffset))
+	return out, nil
+}
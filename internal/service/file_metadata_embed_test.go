@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEmbedTIFFMetadataRejectsTruncatedHeaders(t *testing.T) {
+	metadata := &EmbeddedFileMetadata{Title: "title"}
+	for _, header := range [][]byte{[]byte("II*\x00"), []byte("MM\x00*")} {
+		full := append(append([]byte{}, header...), 8, 0, 0, 0)
+		for size := len(header); size < len(full); size++ {
+			data := full[:size]
+			if _, err := embedTIFFMetadata(data, metadata); err != ErrEmbedMetadataMalformed {
+				t.Fatalf("embedTIFFMetadata(%q) error = %v, want %v", data, err, ErrEmbedMetadataMalformed)
+			}
+			if got := EmbedFileMetadata(data, metadata); !bytes.Equal(got, data) {
+				t.Fatalf("EmbedFileMetadata(%q) = %q, want input unchanged", data, got)
+			}
+		}
+	}
+}
+
+func TestEmbedTIFFMetadataRejectsBadIFD(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "offset inside header", data: []byte("II*\x00\x04\x00\x00\x00\x00\x00")},
+		{name: "offset past end", data: []byte("II*\x00\xff\x00\x00\x00\x00\x00")},
+		{name: "entries past end", data: []byte("II*\x00\x08\x00\x00\x00\x05\x00\x00\x00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := embedTIFFMetadata(tt.data, &EmbeddedFileMetadata{Title: "title"}); err != ErrEmbedMetadataMalformed {
+				t.Fatalf("embedTIFFMetadata() error = %v, want %v", err, ErrEmbedMetadataMalformed)
+			}
+		})
+	}
+}
+
+func TestEmbedTIFFMetadataAddsXMPEntry(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		data := make([]byte, 8+2+12+4)
+		if order == binary.LittleEndian {
+			copy(data, "II*\x00")
+		} else {
+			copy(data, "MM\x00*")
+		}
+		order.PutUint32(data[4:8], 8)
+		order.PutUint16(data[8:10], 1)
+		order.PutUint16(data[10:12], 256)
+		order.PutUint16(data[12:14], 3)
+		order.PutUint32(data[14:18], 1)
+		order.PutUint32(data[18:22], 64)
+
+		out, err := embedTIFFMetadata(data, &EmbeddedFileMetadata{Title: "Sunset"})
+		if err != nil {
+			t.Fatalf("embedTIFFMetadata() error = %v", err)
+		}
+		ifdOffset := int(order.Uint32(out[4:8]))
+		count := int(order.Uint16(out[ifdOffset : ifdOffset+2]))
+		if count != 2 {
+			t.Fatalf("IFD entry count = %d, want 2", count)
+		}
+		entry := out[ifdOffset+14 : ifdOffset+26]
+		if tag := order.Uint16(entry[0:2]); tag != tiffTagXMLPacket {
+			t.Fatalf("second IFD tag = %d, want %d", tag, tiffTagXMLPacket)
+		}
+		size := int(order.Uint32(entry[4:8]))
+		offset := int(order.Uint32(entry[8:12]))
+		if !bytes.Contains(out[offset:offset+size], []byte("Sunset")) {
+			t.Fatalf("XMP packet does not contain the title")
+		}
+	}
+}
+
+const testExistingXMPPacket = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?><x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/" photoshop:Credit="Studio Nest" xmp:Rating="1"><dc:title><rdf:Alt><rdf:li xml:lang="x-default">Old Title</rdf:li></rdf:Alt></dc:title></rdf:Description></rdf:RDF></x:xmpmeta><?xpacket end="w"?>`
+
+func testEmbeddedMetadata() *EmbeddedFileMetadata {
+	return &EmbeddedFileMetadata{
+		Title:       "Sunset <Harbor>",
+		Description: "Evening & tide",
+		Tags:        []string{"sea", "sky"},
+		Rating:      4,
+		AIMetadata: &AIImageMetadata{
+			Checkpoint:    "sd_xl_base_1.0",
+			Prompt:        "a quiet harbor",
+			OtherMetadata: []AIImageMetadataKeyValue{{Key: AIMetadataKeySteps, Values: []string{"20"}}},
+		},
+	}
+}
+
+func assertEmbeddedXMP(t *testing.T, packet []byte, merged bool) {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("XMP packet is not well-formed: %v\n%s", err, packet)
+		}
+	}
+	text := string(packet)
+	for _, want := range []string{"Sunset &lt;Harbor&gt;", "Evening &amp; tide", "<rdf:li>sea</rdf:li>", "<rdf:li>sky</rdf:li>", "<xmp:Rating>4</xmp:Rating>", "a quiet harbor", "sd_xl_base_1.0"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("XMP packet missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Count(text, "<dc:title>") != 1 || strings.Contains(text, "xmp:Rating=") {
+		t.Fatalf("XMP packet kept stale managed properties:\n%s", text)
+	}
+	if merged && (!strings.Contains(text, `photoshop:Credit="Studio Nest"`) || strings.Contains(text, "Old Title")) {
+		t.Fatalf("XMP packet did not merge into the existing packet:\n%s", text)
+	}
+}
+
+func pngXMPPackets(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	packets := make([][]byte, 0, 1)
+	for pos := len(pngSignature); pos+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		body := data[pos+8 : pos+8+length]
+		if string(data[pos+4:pos+8]) == "iTXt" && bytes.HasPrefix(body, []byte(xmpPNGKeyword+"\x00")) {
+			packets = append(packets, body[len(xmpPNGKeyword)+5:])
+		}
+		pos += 12 + length
+	}
+	return packets
+}
+
+func jpegXMPPackets(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	segments, _, err := splitJPEGSegments(data)
+	if err != nil {
+		t.Fatalf("splitJPEGSegments() error = %v", err)
+	}
+	packets := make([][]byte, 0, 1)
+	for _, segment := range segments {
+		if segment.marker == 0xE1 && bytes.HasPrefix(segment.data[4:], []byte(xmpJPEGHeader)) {
+			packets = append(packets, segment.data[4+len(xmpJPEGHeader):])
+		}
+	}
+	return packets
+}
+
+func webpChunks(t *testing.T, data []byte) map[string][][]byte {
+	t.Helper()
+	if got := int(binary.LittleEndian.Uint32(data[4:8])); got != len(data)-8 {
+		t.Fatalf("RIFF size = %d, want %d", got, len(data)-8)
+	}
+	chunks := make(map[string][][]byte)
+	for pos := 12; pos+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if pos+8+size > len(data) {
+			t.Fatalf("WebP chunk at %d overruns the file", pos)
+		}
+		chunkType := string(data[pos : pos+4])
+		chunks[chunkType] = append(chunks[chunkType], data[pos+8:pos+8+size])
+		pos += 8 + size + size%2
+	}
+	return chunks
+}
+
+func buildTestJPEG(t *testing.T, xmpPacket string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	data := buf.Bytes()
+	if xmpPacket == "" {
+		return data
+	}
+	payload := append([]byte(xmpJPEGHeader), xmpPacket...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:4], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func buildTestPNG(t *testing.T, xmpPacket string) []byte {
+	t.Helper()
+	data := encodeTestPNG(t, 3, 2)
+	if xmpPacket == "" {
+		return data
+	}
+	chunk := buildPNGChunk("iTXt", buildITXtData(xmpPNGKeyword, xmpPacket))
+	ihdrEnd := len(pngSignature) + 12 + int(binary.BigEndian.Uint32(data[8:12]))
+	return append(append(append([]byte{}, data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+func buildTestWebP(t *testing.T, xmpPacket string) []byte {
+	t.Helper()
+	fixture := readTestdata(t, "webp_exif.webp")
+	image := webpChunks(t, fixture)["VP8L"][0]
+	out := []byte("RIFF\x00\x00\x00\x00WEBP")
+	appendChunk := func(chunkType string, body []byte) {
+		out = append(out, chunkType...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+		out = append(out, body...)
+		if len(body)%2 == 1 {
+			out = append(out, 0)
+		}
+	}
+	if xmpPacket != "" {
+		vp8x := make([]byte, 10)
+		vp8x[0] = webpFlagXMP
+		appendChunk("VP8X", vp8x)
+	}
+	appendChunk("VP8L", image)
+	if xmpPacket != "" {
+		appendChunk("XMP ", []byte(xmpPacket))
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out
+}
+
+func TestEmbedPNGMetadataRoundTrip(t *testing.T) {
+	for _, existing := range []string{"", testExistingXMPPacket} {
+		out := EmbedFileMetadata(buildTestPNG(t, existing), testEmbeddedMetadata())
+		if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+			t.Fatalf("png.Decode() error = %v", err)
+		}
+		packets := pngXMPPackets(t, out)
+		if len(packets) != 1 {
+			t.Fatalf("PNG has %d XMP chunks, want 1", len(packets))
+		}
+		assertEmbeddedXMP(t, packets[0], existing != "")
+
+		texts := extractPNGTexts(out)
+		if texts["title"] != "Sunset <Harbor>" || texts["description"] != "Evening & tide" {
+			t.Fatalf("PNG text chunks = %v, want title and description", texts)
+		}
+		if metadata := extractAIMetadata(out); metadata == nil || metadata.Prompt != "a quiet harbor" {
+			t.Fatalf("extractAIMetadata() = %#v, want embedded parameters", metadata)
+		}
+	}
+}
+
+func TestEmbedJPEGMetadataRoundTrip(t *testing.T) {
+	for _, existing := range []string{"", testExistingXMPPacket} {
+		out := EmbedFileMetadata(buildTestJPEG(t, existing), testEmbeddedMetadata())
+		if _, err := jpeg.Decode(bytes.NewReader(out)); err != nil {
+			t.Fatalf("jpeg.Decode() error = %v", err)
+		}
+		packets := jpegXMPPackets(t, out)
+		if len(packets) != 1 {
+			t.Fatalf("JPEG has %d XMP segments, want 1", len(packets))
+		}
+		assertEmbeddedXMP(t, packets[0], existing != "")
+	}
+}
+
+func TestEmbedWebPMetadataRoundTrip(t *testing.T) {
+	for _, existing := range []string{"", testExistingXMPPacket} {
+		out := EmbedFileMetadata(buildTestWebP(t, existing), testEmbeddedMetadata())
+		config, err := webp.DecodeConfig(bytes.NewReader(out))
+		if err != nil || config.Width != 1 || config.Height != 1 {
+			t.Fatalf("webp.DecodeConfig() = %+v, %v; want 1x1", config, err)
+		}
+		chunks := webpChunks(t, out)
+		if len(chunks["VP8X"]) != 1 || chunks["VP8X"][0][0]&webpFlagXMP == 0 {
+			t.Fatalf("WebP VP8X chunk = %v, want XMP flag set", chunks["VP8X"])
+		}
+		if len(chunks["XMP "]) != 1 {
+			t.Fatalf("WebP has %d XMP chunks, want 1", len(chunks["XMP "]))
+		}
+		assertEmbeddedXMP(t, chunks["XMP "][0], existing != "")
+	}
+}
+
+func TestEmbedFileMetadataReembedKeepsSinglePacket(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		packets func(t *testing.T, data []byte) [][]byte
+	}{
+		{name: "png", data: buildTestPNG(t, testExistingXMPPacket), packets: pngXMPPackets},
+		{name: "jpeg", data: buildTestJPEG(t, testExistingXMPPacket), packets: jpegXMPPackets},
+		{name: "webp", data: buildTestWebP(t, testExistingXMPPacket), packets: func(t *testing.T, data []byte) [][]byte {
+			return webpChunks(t, data)["XMP "]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			once := EmbedFileMetadata(tt.data, testEmbeddedMetadata())
+			twice := EmbedFileMetadata(once, testEmbeddedMetadata())
+			packets := tt.packets(t, twice)
+			if len(packets) != 1 {
+				t.Fatalf("re-embedded file has %d XMP packets, want 1", len(packets))
+			}
+			assertEmbeddedXMP(t, packets[0], true)
+		})
+	}
+}
+
+func TestCanEmbedFileMetadata(t *testing.T) {
+	for path, want := range map[string]bool{
+		"uploads/a.PNG":  true,
+		"uploads/a.jpeg": true,
+		"uploads/a.webp": true,
+		"uploads/a.tif":  true,
+		"uploads/a.psd":  false,
+		"uploads/a.mp4":  false,
+		"uploads/a.gif":  false,
+		"uploads/a":      false,
+	} {
+		if got := CanEmbedFileMetadata(path); got != want {
+			t.Fatalf("CanEmbedFileMetadata(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
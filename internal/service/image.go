@@ -6,12 +6,14 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"illust-nest/internal/model"
 	"illust-nest/internal/repository"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
+	"log"
 	"mime"
 	"mime/multipart"
 	"os"
@@ -26,32 +28,35 @@ import (
 )
 
 const (
-	MaxUploadFileSizeMB    int64 = 50
-	MaxUploadFileSizeBytes       = MaxUploadFileSizeMB * 1024 * 1024
-	thumbnailMaxWidth            = 400
-	thumbnailQuality             = 85
+	thumbnailMaxWidth = 400
+	thumbnailQuality  = 85
 )
 
 var allowedUploadFormats = map[string]struct{}{
-	"image/jpeg":              {},
-	"image/png":               {},
-	"image/gif":               {},
-	"image/webp":              {},
-	"image/bmp":               {},
-	"image/x-ms-bmp":          {},
-	"image/tiff":              {},
-	"image/psd":               {},
-	"image/x-psd":             {},
-	"image/photoshop":         {},
-	"image/x-photoshop":       {},
-	"application/photoshop":   {},
-	"application/x-photoshop": {},
-	"application/psd":         {},
-	"application/postscript":  {},
-	"application/illustrator": {},
-	"image/heic":              {},
-	"image/heif":              {},
-	"image/avif":              {},
+	"image/jpeg":                   {},
+	"image/png":                    {},
+	"image/gif":                    {},
+	"image/webp":                   {},
+	"image/bmp":                    {},
+	"image/x-ms-bmp":               {},
+	"image/tiff":                   {},
+	"image/psd":                    {},
+	"image/x-psd":                  {},
+	"image/photoshop":              {},
+	"image/x-photoshop":            {},
+	"application/photoshop":        {},
+	"application/x-photoshop":      {},
+	"application/psd":              {},
+	"application/postscript":       {},
+	"application/illustrator":      {},
+	"image/heic":                   {},
+	"image/heif":                   {},
+	"image/avif":                   {},
+	"video/mp4":                    {},
+	"video/webm":                   {},
+	"video/quicktime":              {},
+	"application/zip":              {},
+	"application/x-zip-compressed": {},
 }
 
 const logicalUploadPrefix = "uploads/"
@@ -64,11 +69,19 @@ func NewImageService(settingRepo *repository.SettingRepository) *ImageService {
 	return &ImageService{settingRepo: settingRepo}
 }
 
-func (s *ImageService) UploadImages(files []*multipart.FileHeader) ([]*UploadedImage, error) {
-	var uploadedImages []*UploadedImage
+func (s *ImageService) UploadImages(ctx context.Context, files []*multipart.FileHeader, limits *UploadLimits) ([]*UploadedImage, error) {
+	if limits == nil {
+		limits = s.GetUploadLimits()
+	}
+	for _, file := range files {
+		if err := limits.CheckFile(file); err != nil {
+			return nil, err
+		}
+	}
 
+	var uploadedImages []*UploadedImage
 	for _, file := range files {
-		uploadedImage, err := s.processImage(file)
+		uploadedImage, err := s.processImage(ctx, file, limits)
 		if err != nil {
 			return nil, err
 		}
@@ -78,9 +91,14 @@ func (s *ImageService) UploadImages(files []*multipart.FileHeader) ([]*UploadedI
 	return uploadedImages, nil
 }
 
-func (s *ImageService) processImage(file *multipart.FileHeader) (*UploadedImage, error) {
-	if shouldUseImageMagickForUpload(file) {
-		return s.processImageWithImageMagick(file)
+func (s *ImageService) processImage(ctx context.Context, file *multipart.FileHeader, limits *UploadLimits) (*UploadedImage, error) {
+	switch {
+	case isVideoUpload(file):
+		return s.processVideo(ctx, file, limits)
+	case isUgoiraUpload(file):
+		return s.processUgoira(ctx, file, limits)
+	case shouldUseImageMagickForUpload(file):
+		return s.processImageWithImageMagick(ctx, file, limits)
 	}
 
 	storage, err := GetStorageProvider()
@@ -99,11 +117,21 @@ func (s *ImageService) processImage(file *multipart.FileHeader) (*UploadedImage,
 		return nil, err
 	}
 
-	img, format, err := image.Decode(bytes.NewReader(originalBytes))
-	if err != nil {
+	if err := limits.CheckImageData(file.Filename, originalBytes); err != nil {
 		return nil, err
 	}
 
+	animation := detectImageAnimation(originalBytes)
+	img, format, err := decodeImageSafely(originalBytes)
+	if err != nil {
+		if animation.Animated() {
+			return s.processImageWithImageMagick(ctx, file, limits)
+		}
+		return nil, fmt.Errorf("%w: %s: %v", ErrImageMalformed, file.Filename, err)
+	}
+	exifRecord := extractImageEXIF(originalBytes)
+	img = applyEXIFOrientation(img, exifRecord.Orientation)
+
 	width := img.Bounds().Dx()
 	height := img.Bounds().Dy()
 
@@ -128,18 +156,7 @@ func (s *ImageService) processImage(file *multipart.FileHeader) (*UploadedImage,
 		return nil, err
 	}
 
-	thumbnailImg := imaging.Resize(img, thumbnailMaxWidth, 0, imaging.Lanczos)
-	var thumbnailBuffer bytes.Buffer
-	if err := imaging.Encode(&thumbnailBuffer, thumbnailImg, imaging.JPEG, imaging.JPEGQuality(thumbnailQuality)); err != nil {
-		return nil, err
-	}
-	if err := storage.Put(
-		context.Background(),
-		thumbnailLogicalPath,
-		bytes.NewReader(thumbnailBuffer.Bytes()),
-		int64(thumbnailBuffer.Len()),
-		"image/jpeg",
-	); err != nil {
+	if err := s.putThumbnail(thumbnailLogicalPath, img); err != nil {
 		return nil, err
 	}
 
@@ -160,17 +177,65 @@ func (s *ImageService) processImage(file *multipart.FileHeader) (*UploadedImage,
 	}
 
 	return &UploadedImage{
-		StoragePath:      originalLogicalPath,
-		ThumbnailPath:    thumbnailLogicalPath,
-		TranscodedPath:   transcodedLogicalPath,
-		FileSize:         file.Size,
-		Width:            width,
-		Height:           height,
-		OriginalFilename: file.Filename,
+		StoragePath:           originalLogicalPath,
+		ThumbnailPath:         thumbnailLogicalPath,
+		TranscodedPath:        transcodedLogicalPath,
+		FileSize:              file.Size,
+		AIMetadata:            extractAIMetadata(originalBytes),
+		EXIF:                  exifRecord,
+		Palette:               extractImagePalette(img),
+		Width:                 width,
+		Height:                height,
+		OriginalFilename:      file.Filename,
+		MediaType:             model.MediaTypeImage,
+		FrameCount:            animation.FrameCount,
+		DurationMs:            animation.DurationMs,
+		LoopCount:             animation.LoopCount,
+		AnimatedThumbnailPath: s.storeAnimatedThumbnail(ctx, originalBytes, ext, uuid, animation),
 	}, nil
 }
 
-func (s *ImageService) processImageWithImageMagick(file *multipart.FileHeader) (*UploadedImage, error) {
+func (s *ImageService) putThumbnail(logicalPath string, img image.Image) error {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return err
+	}
+
+	thumbnailImg := imaging.Resize(img, thumbnailMaxWidth, 0, imaging.Lanczos)
+	var thumbnailBuffer bytes.Buffer
+	if err := imaging.Encode(&thumbnailBuffer, thumbnailImg, imaging.JPEG, imaging.JPEGQuality(thumbnailQuality)); err != nil {
+		return err
+	}
+	return storage.Put(
+		context.Background(),
+		logicalPath,
+		bytes.NewReader(thumbnailBuffer.Bytes()),
+		int64(thumbnailBuffer.Len()),
+		"image/jpeg",
+	)
+}
+
+func applyEXIFOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+	return img
+}
+
+func (s *ImageService) processImageWithImageMagick(ctx context.Context, file *multipart.FileHeader, limits *UploadLimits) (*UploadedImage, error) {
 	cfg, err := s.getImageMagickSettings()
 	if err != nil {
 		return nil, err
@@ -193,6 +258,9 @@ func (s *ImageService) processImageWithImageMagick(file *multipart.FileHeader) (
 	if err != nil {
 		return nil, err
 	}
+	if err := limits.CheckImageData(file.Filename, originalBytes); err != nil {
+		return nil, err
+	}
 
 	uuid := generateUUID()
 	ext := strings.ToLower(filepath.Ext(file.Filename))
@@ -228,10 +296,10 @@ func (s *ImageService) processImageWithImageMagick(file *multipart.FileHeader) (
 	}
 
 	input := tempInputPath + "[0]"
-	if err := runImageMagick(cfg.Version, input, "-auto-orient", "-flatten", "-quality", "92", tempTranscodedPath); err != nil {
+	if err := runImageMagick(ctx, cfg, input, "-auto-orient", "-flatten", "-quality", "92", tempTranscodedPath); err != nil {
 		return nil, fmt.Errorf("ImageMagick transcoding failed: %w", err)
 	}
-	if err := runImageMagick(cfg.Version, input, "-auto-orient", "-flatten", "-thumbnail", "400x", "-quality", "85", tempThumbPath); err != nil {
+	if err := runImageMagick(ctx, cfg, input, "-auto-orient", "-flatten", "-thumbnail", "400x", "-quality", "85", tempThumbPath); err != nil {
 		return nil, fmt.Errorf("ImageMagick thumbnail generation failed: %w", err)
 	}
 
@@ -269,14 +337,23 @@ func (s *ImageService) processImageWithImageMagick(file *multipart.FileHeader) (
 	width := transcodedImg.Bounds().Dx()
 	height := transcodedImg.Bounds().Dy()
 
+	animation := detectImageAnimation(originalBytes)
 	return &UploadedImage{
-		StoragePath:      originalLogicalPath,
-		ThumbnailPath:    thumbnailLogicalPath,
-		TranscodedPath:   transcodedLogicalPath,
-		FileSize:         file.Size,
-		Width:            width,
-		Height:           height,
-		OriginalFilename: file.Filename,
+		StoragePath:           originalLogicalPath,
+		ThumbnailPath:         thumbnailLogicalPath,
+		TranscodedPath:        transcodedLogicalPath,
+		FileSize:              file.Size,
+		AIMetadata:            extractAIMetadata(originalBytes),
+		EXIF:                  extractImageEXIF(originalBytes),
+		Palette:               extractImagePalette(transcodedImg),
+		Width:                 width,
+		Height:                height,
+		OriginalFilename:      file.Filename,
+		MediaType:             model.MediaTypeImage,
+		FrameCount:            animation.FrameCount,
+		DurationMs:            animation.DurationMs,
+		LoopCount:             animation.LoopCount,
+		AnimatedThumbnailPath: s.storeAnimatedThumbnail(ctx, originalBytes, ext, uuid, animation),
 	}, nil
 }
 
@@ -287,7 +364,7 @@ func (s *ImageService) getStoragePath(subDir, uuid, ext string) string {
 	return fmt.Sprintf("%s%s/%s/%s/%s%s", logicalUploadPrefix, subDir, year, month, uuid, ext)
 }
 
-func (s *ImageService) DeleteImage(storagePath, thumbnailPath, transcodedPath string) error {
+func (s *ImageService) DeleteImage(storagePath, thumbnailPath, transcodedPath, animatedThumbnailPath string) error {
 	storage, err := GetStorageProvider()
 	if err != nil {
 		return err
@@ -299,15 +376,121 @@ func (s *ImageService) DeleteImage(storagePath, thumbnailPath, transcodedPath st
 	if err := storage.Delete(context.Background(), thumbnailPath); err != nil {
 		return err
 	}
+	if err := storage.Delete(context.Background(), sanitizedImagePath(storagePath)); err != nil {
+		return err
+	}
+	if err := storage.Delete(context.Background(), cleanImageMarkerPath(storagePath)); err != nil {
+		return err
+	}
 	if strings.TrimSpace(transcodedPath) != "" {
 		if err := storage.Delete(context.Background(), transcodedPath); err != nil {
 			return err
 		}
+		if err := storage.Delete(context.Background(), sanitizedImagePath(transcodedPath)); err != nil {
+			return err
+		}
+		if err := storage.Delete(context.Background(), cleanImageMarkerPath(transcodedPath)); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(animatedThumbnailPath) != "" {
+		if err := storage.Delete(context.Background(), animatedThumbnailPath); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+func sanitizedImagePath(logicalPath string) string {
+	return logicalUploadPrefix + "sanitized/" + strings.TrimPrefix(logicalPath, logicalUploadPrefix)
+}
+
+func cleanImageMarkerPath(logicalPath string) string {
+	return sanitizedImagePath(logicalPath) + ".clean"
+}
+
+func (s *ImageService) PublicEXIFStripEnabled() bool {
+	if s.settingRepo == nil {
+		return true
+	}
+	setting, err := s.settingRepo.Get("public_strip_sensitive_exif")
+	if err != nil {
+		return true
+	}
+	return setting.Value == "true"
+}
+
+func (s *ImageService) OpenImage(logicalPath string) (io.ReadCloser, ObjectInfo, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	file, info, err := storage.Get(context.Background(), logicalPath)
+	if err != nil {
+		return nil, ObjectInfo{}, ErrImageNotFound
+	}
+	return file, info, nil
+}
+
+type sniffedImageReader struct {
+	io.Reader
+	io.Closer
+}
+
+func (s *ImageService) OpenSanitizedImage(logicalPath string) (io.ReadCloser, ObjectInfo, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	sanitizedPath := sanitizedImagePath(logicalPath)
+	if _, err := storage.Stat(context.Background(), sanitizedPath); err == nil {
+		return s.OpenImage(sanitizedPath)
+	}
+
+	file, info, err := s.OpenImage(logicalPath)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	if _, err := storage.Stat(context.Background(), cleanImageMarkerPath(logicalPath)); err == nil {
+		return file, info, nil
+	}
+
+	header := make([]byte, metadataSniffBytes)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		return nil, ObjectInfo{}, err
+	}
+	header = header[:n]
+	if !hasStrippableMetadata(header) {
+		return &sniffedImageReader{Reader: io.MultiReader(bytes.NewReader(header), file), Closer: file}, info, nil
+	}
+
+	original, err := io.ReadAll(io.MultiReader(bytes.NewReader(header), file))
+	file.Close()
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+	sanitized := sanitizeImageMetadata(original)
+	if bytes.Equal(sanitized, original) {
+		if err := storage.Put(context.Background(), cleanImageMarkerPath(logicalPath), bytes.NewReader(nil), 0, "application/octet-stream"); err != nil {
+			log.Printf("Failed to mark image %s as free of sensitive metadata: %v", logicalPath, err)
+		}
+		return io.NopCloser(bytes.NewReader(original)), info, nil
+	}
+
+	info.Size = int64(len(sanitized))
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = contentTypeFromFilename(logicalPath)
+	}
+	if err := storage.Put(context.Background(), sanitizedPath, bytes.NewReader(sanitized), info.Size, contentType); err != nil {
+		log.Printf("Failed to store sanitized image %s: %v", sanitizedPath, err)
+	}
+	return io.NopCloser(bytes.NewReader(sanitized)), info, nil
+}
+
 func shouldTranscodeOriginal(format, ext, contentType string) bool {
 	cleanFormat := strings.ToLower(strings.TrimSpace(format))
 	if cleanFormat == "bmp" || cleanFormat == "tiff" {
@@ -325,14 +508,16 @@ func shouldTranscodeOriginal(format, ext, contentType string) bool {
 }
 
 type imageMagickSettings struct {
-	Enabled bool
-	Version string
+	Enabled   bool
+	Version   string
+	MaxPixels int64
 }
 
 func (s *ImageService) getImageMagickSettings() (*imageMagickSettings, error) {
 	settings := &imageMagickSettings{
-		Enabled: false,
-		Version: ImageMagickVersionV7,
+		Enabled:   false,
+		Version:   ImageMagickVersionV7,
+		MaxPixels: s.GetUploadLimits().MaxPixels,
 	}
 	if s.settingRepo == nil {
 		return settings, nil
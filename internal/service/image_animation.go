@@ -0,0 +1,430 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"illust-nest/internal/repository"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	gifMinFrameDelay     = 2
+	gifDefaultFrameDelay = 10
+	apngDefaultDelayDen  = 100
+)
+
+type imageAnimation struct {
+	FrameCount int
+	DurationMs int
+	LoopCount  int
+}
+
+func (a imageAnimation) Animated() bool {
+	return a.FrameCount > 1
+}
+
+type ImageAnimationService struct {
+	animationRepo *repository.ImageAnimationRepository
+}
+
+func NewImageAnimationService(animationRepo *repository.ImageAnimationRepository) *ImageAnimationService {
+	return &ImageAnimationService{animationRepo: animationRepo}
+}
+
+func (s *ImageAnimationService) BackfillImageAnimation() error {
+	if _, err := GetStorageProvider(); err != nil {
+		return err
+	}
+	images, err := s.animationRepo.FindImagesWithoutFrameCount()
+	if err != nil {
+		return err
+	}
+	for i := range images {
+		animation := imageAnimation{FrameCount: 1}
+		if isAnimatableSourceExt(filepath.Ext(images[i].StoragePath)) {
+			animation, err = readStoredImageAnimation(images[i].StoragePath)
+			if err != nil {
+				log.Printf("Failed to read image %d for animation backfill, recording it as static: %v", images[i].ID, err)
+				animation = imageAnimation{FrameCount: 1}
+			}
+		}
+		if err := s.animationRepo.Save(images[i].ID, animation.FrameCount, animation.DurationMs, animation.LoopCount); err != nil {
+			log.Printf("Failed to save animation info for image %d: %v", images[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func readStoredImageAnimation(storagePath string) (imageAnimation, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return imageAnimation{}, err
+	}
+
+	file, _, err := storage.Get(context.Background(), storagePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return imageAnimation{}, ErrImageNotFound
+		}
+		return imageAnimation{}, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return imageAnimation{}, err
+	}
+	return detectImageAnimation(data), nil
+}
+
+func isAnimatableSourceExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".gif", ".png", ".apng", ".webp":
+		return true
+	}
+	return false
+}
+
+func detectImageAnimation(data []byte) imageAnimation {
+	var animation imageAnimation
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		animation = detectGIFAnimation(data)
+	case bytes.HasPrefix(data, pngSignature):
+		animation = detectAPNGAnimation(data)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		animation = detectWebPAnimation(data)
+	}
+	if animation.FrameCount < 1 {
+		return imageAnimation{FrameCount: 1}
+	}
+	if animation.FrameCount == 1 {
+		animation.DurationMs = 0
+		animation.LoopCount = 0
+	}
+	return animation
+}
+
+func detectGIFAnimation(data []byte) imageAnimation {
+	if len(data) < 13 {
+		return imageAnimation{}
+	}
+	animation := imageAnimation{LoopCount: 1}
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << ((flags & 0x07) + 1)
+	}
+
+	pendingDelay := 0
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21:
+			if pos+1 >= len(data) {
+				return animation
+			}
+			label := data[pos+1]
+			pos += 2
+			first := true
+			for pos < len(data) {
+				size := int(data[pos])
+				pos++
+				if size == 0 {
+					break
+				}
+				if pos+size > len(data) {
+					return animation
+				}
+				block := data[pos : pos+size]
+				switch {
+				case label == 0xF9 && first && size >= 4:
+					pendingDelay = int(binary.LittleEndian.Uint16(block[1:3]))
+				case label == 0xFF && first && size == 11:
+					identifier := string(block)
+					if identifier != "NETSCAPE2.0" && identifier != "ANIMEXTS1.0" {
+						label = 0
+					}
+				case label == 0xFF && !first && size >= 3 && block[0] == 1:
+					if loops := int(binary.LittleEndian.Uint16(block[1:3])); loops == 0 {
+						animation.LoopCount = 0
+					} else {
+						animation.LoopCount = loops + 1
+					}
+				}
+				first = false
+				pos += size
+			}
+		case 0x2C:
+			if pos+10 > len(data) {
+				return animation
+			}
+			flags := data[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << ((flags & 0x07) + 1)
+			}
+			pos++
+			for pos < len(data) {
+				size := int(data[pos])
+				pos += 1 + size
+				if size == 0 {
+					break
+				}
+			}
+			if pendingDelay < gifMinFrameDelay {
+				pendingDelay = gifDefaultFrameDelay
+			}
+			animation.FrameCount++
+			animation.DurationMs += pendingDelay * 10
+			pendingDelay = 0
+		default:
+			return animation
+		}
+	}
+	return animation
+}
+
+func detectAPNGAnimation(data []byte) imageAnimation {
+	animation := imageAnimation{FrameCount: 1}
+	hasAnimationControl := false
+	frameControls := 0
+	pos := len(pngSignature)
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		chunkType := string(data[pos+4 : pos+8])
+		start := pos + 8
+		end := start + length
+		if length < 0 || end+4 > len(data) {
+			break
+		}
+		payload := data[start:end]
+		switch chunkType {
+		case "acTL":
+			if len(payload) >= 8 {
+				hasAnimationControl = true
+				animation.FrameCount = int(binary.BigEndian.Uint32(payload[0:4]))
+				animation.LoopCount = int(binary.BigEndian.Uint32(payload[4:8]))
+			}
+		case "fcTL":
+			if hasAnimationControl && len(payload) >= 26 {
+				frameControls++
+				numerator := int(binary.BigEndian.Uint16(payload[20:22]))
+				denominator := int(binary.BigEndian.Uint16(payload[22:24]))
+				if denominator == 0 {
+					denominator = apngDefaultDelayDen
+				}
+				animation.DurationMs += numerator * 1000 / denominator
+			}
+		case "IEND":
+			pos = len(data)
+			continue
+		}
+		pos = end + 4
+	}
+	if !hasAnimationControl {
+		return imageAnimation{FrameCount: 1}
+	}
+	if frameControls > 0 && frameControls < animation.FrameCount {
+		animation.FrameCount = frameControls
+	}
+	return animation
+}
+
+func detectWebPAnimation(data []byte) imageAnimation {
+	animation := imageAnimation{}
+	animated := false
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkType := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		start := pos + 8
+		end := start + length
+		if length < 0 || end > len(data) {
+			break
+		}
+		payload := data[start:end]
+		switch chunkType {
+		case "VP8X":
+			if len(payload) >= 1 {
+				animated = payload[0]&0x02 != 0
+			}
+		case "ANIM":
+			if len(payload) >= 6 {
+				animation.LoopCount = int(binary.LittleEndian.Uint16(payload[4:6]))
+			}
+		case "ANMF":
+			if len(payload) >= 15 {
+				animation.FrameCount++
+				animation.DurationMs += int(payload[12]) | int(payload[13])<<8 | int(payload[14])<<16
+			}
+		}
+		pos = end + length%2
+	}
+	if !animated {
+		return imageAnimation{FrameCount: 1}
+	}
+	return animation
+}
+
+func (s *ImageService) AnimatedThumbnailsEnabled() bool {
+	if s.settingRepo == nil {
+		return false
+	}
+	setting, err := s.settingRepo.Get("animated_thumbnails_enabled")
+	if err != nil {
+		return false
+	}
+	return setting.Value == "true"
+}
+
+func (s *ImageService) storeAnimatedThumbnail(ctx context.Context, originalBytes []byte, ext, uuid string, animation imageAnimation) string {
+	if !animation.Animated() || !s.AnimatedThumbnailsEnabled() {
+		return ""
+	}
+	if !animationWithinDecodeBudget(originalBytes, animation.FrameCount) {
+		log.Printf("Skipping animated thumbnail: %d frames exceed the decode budget", animation.FrameCount)
+		return ""
+	}
+
+	thumbnailBytes, err := s.renderAnimatedThumbnail(ctx, originalBytes, ext)
+	if err != nil {
+		log.Printf("Failed to generate animated thumbnail: %v", err)
+		return ""
+	}
+	if len(thumbnailBytes) == 0 {
+		return ""
+	}
+	return s.putAnimatedThumbnail(thumbnailBytes, uuid)
+}
+
+func (s *ImageService) putAnimatedThumbnail(thumbnailBytes []byte, uuid string) string {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		log.Printf("Failed to store animated thumbnail: %v", err)
+		return ""
+	}
+	logicalPath := s.getStoragePath("thumbnails", uuid+"-animated", ".gif")
+	if err := storage.Put(
+		context.Background(),
+		logicalPath,
+		bytes.NewReader(thumbnailBytes),
+		int64(len(thumbnailBytes)),
+		"image/gif",
+	); err != nil {
+		log.Printf("Failed to store animated thumbnail: %v", err)
+		return ""
+	}
+	return logicalPath
+}
+
+func (s *ImageService) renderAnimatedThumbnail(ctx context.Context, originalBytes []byte, ext string) ([]byte, error) {
+	if bytes.HasPrefix(originalBytes, []byte("GIF8")) {
+		return renderGIFThumbnail(originalBytes)
+	}
+
+	cfg, err := s.getImageMagickSettings()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "illust-nest-animated-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	tempInputPath := filepath.Join(tempDir, "input"+ext)
+	tempOutputPath := filepath.Join(tempDir, "animated.gif")
+	if err := os.WriteFile(tempInputPath, originalBytes, 0644); err != nil {
+		return nil, err
+	}
+
+	input := tempInputPath
+	if bytes.HasPrefix(originalBytes, pngSignature) {
+		input = "apng:" + tempInputPath
+	}
+	if err := runImageMagick(ctx, cfg, input, "-coalesce", "-resize", fmt.Sprintf("%dx", thumbnailMaxWidth), "-layers", "Optimize", tempOutputPath); err != nil {
+		return nil, fmt.Errorf("ImageMagick animated thumbnail generation failed: %w", err)
+	}
+	return os.ReadFile(tempOutputPath)
+}
+
+func renderGIFThumbnail(data []byte) ([]byte, error) {
+	source, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(source.Image) == 0 {
+		return nil, errors.New("gif has no frames")
+	}
+
+	width, height := source.Config.Width, source.Config.Height
+	if width <= 0 || height <= 0 {
+		bounds := source.Image[0].Bounds()
+		width, height = bounds.Max.X, bounds.Max.Y
+	}
+	targetWidth := width
+	if targetWidth > thumbnailMaxWidth {
+		targetWidth = thumbnailMaxWidth
+	}
+	targetHeight := height * targetWidth / width
+	if targetHeight < 1 {
+		targetHeight = 1
+	}
+
+	output := &gif.GIF{
+		LoopCount: source.LoopCount,
+		Config:    image.Config{Width: targetWidth, Height: targetHeight},
+	}
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i, frame := range source.Image {
+		disposal := byte(0)
+		if i < len(source.Disposal) {
+			disposal = source.Disposal[i]
+		}
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = imaging.Clone(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		resized := imaging.Resize(canvas, targetWidth, targetHeight, imaging.Linear)
+		paletted := image.NewPaletted(resized.Bounds(), frame.Palette)
+		draw.FloydSteinberg.Draw(paletted, resized.Bounds(), resized, image.Point{})
+
+		delay := 0
+		if i < len(source.Delay) {
+			delay = source.Delay[i]
+		}
+		output.Image = append(output.Image, paletted)
+		output.Delay = append(output.Delay, delay)
+		output.Disposal = append(output.Disposal, gif.DisposalNone)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := gif.EncodeAll(&buffer, output); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
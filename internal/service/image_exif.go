@@ -0,0 +1,257 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"illust-nest/internal/model"
+	"illust-nest/internal/repository"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+	"gorm.io/gorm"
+)
+
+type ImageEXIFService struct {
+	exifRepo *repository.ImageEXIFRepository
+}
+
+func NewImageEXIFService(exifRepo *repository.ImageEXIFRepository) *ImageEXIFService {
+	return &ImageEXIFService{exifRepo: exifRepo}
+}
+
+func (s *ImageEXIFService) SyncImage(imageID, workID uint, record *model.WorkImageEXIF) {
+	if s == nil || record == nil {
+		return
+	}
+	stored := *record
+	stored.ImageID = imageID
+	stored.WorkID = workID
+	if err := s.exifRepo.Save(&stored); err != nil {
+		log.Printf("Failed to index EXIF for image %d: %v", imageID, err)
+	}
+}
+
+func (s *ImageEXIFService) Load(image *model.WorkImage) (*model.WorkImageEXIF, error) {
+	record, err := s.exifRepo.FindByImageID(image.ID)
+	if err == nil {
+		return record, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	record, err = readStoredImageEXIF(image.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	s.SyncImage(image.ID, image.WorkID, record)
+	return record, nil
+}
+
+func (s *ImageEXIFService) BackfillImageEXIF() error {
+	if _, err := GetStorageProvider(); err != nil {
+		return err
+	}
+	images, err := s.exifRepo.FindImagesWithoutEXIF()
+	if err != nil {
+		return err
+	}
+	for i := range images {
+		record := &model.WorkImageEXIF{}
+		if isEXIFSupportedSourceExt(filepath.Ext(images[i].StoragePath)) {
+			record, err = readStoredImageEXIF(images[i].StoragePath)
+			if err != nil {
+				log.Printf("Failed to read image %d for EXIF backfill, recording it without EXIF: %v", images[i].ID, err)
+				record = &model.WorkImageEXIF{}
+			}
+		}
+		s.SyncImage(images[i].ID, images[i].WorkID, record)
+	}
+	return nil
+}
+
+func readStoredImageEXIF(storagePath string) (*model.WorkImageEXIF, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	file, _, err := storage.Get(context.Background(), storagePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return extractImageEXIF(data), nil
+}
+
+func extractImageEXIF(data []byte) *model.WorkImageEXIF {
+	record := &model.WorkImageEXIF{}
+	payload := extractEXIFPayload(data)
+	if payload == nil {
+		return record
+	}
+
+	meta, err := exif.Decode(bytes.NewReader(payload))
+	if err != nil && (meta == nil || exif.IsCriticalError(err)) {
+		return record
+	}
+
+	walker := &imageEXIFWalker{fields: make([]ImageEXIFField, 0)}
+	if err := meta.Walk(walker); err != nil || len(walker.fields) == 0 {
+		return record
+	}
+	sort.Slice(walker.fields, func(i, j int) bool {
+		return walker.fields[i].Key < walker.fields[j].Key
+	})
+	if fields, err := json.Marshal(walker.fields); err == nil {
+		record.Fields = string(fields)
+	}
+
+	record.HasEXIF = true
+	record.CameraMake = exifStringValue(meta, exif.Make)
+	record.CameraModel = exifStringValue(meta, exif.Model)
+	record.LensModel = exifStringValue(meta, exif.LensModel)
+	record.FocalLength = exifRatValue(meta, exif.FocalLength)
+	record.FNumber = exifRatValue(meta, exif.FNumber)
+	record.ExposureTime = exifRatValue(meta, exif.ExposureTime)
+	if tag, err := meta.Get(exif.Orientation); err == nil {
+		if orientation, err := tag.Int(0); err == nil && orientation >= 1 && orientation <= 8 {
+			record.Orientation = orientation
+		}
+	}
+	if tag, err := meta.Get(exif.ISOSpeedRatings); err == nil {
+		if iso, err := tag.Int(0); err == nil && iso > 0 {
+			record.ISO = &iso
+		}
+	}
+	if takenAt, err := meta.DateTime(); err == nil && !takenAt.IsZero() {
+		record.TakenAt = &takenAt
+	}
+	if lat, long, err := meta.LatLong(); err == nil && isValidGPSCoordinate(lat, long) {
+		record.GPSLatitude = &lat
+		record.GPSLongitude = &long
+	}
+	return record
+}
+
+func exifStringValue(meta *exif.Exif, name exif.FieldName) string {
+	tag, err := meta.Get(name)
+	if err != nil {
+		return ""
+	}
+	value, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(value, "\x00"))
+}
+
+func exifRatValue(meta *exif.Exif, name exif.FieldName) *float64 {
+	tag, err := meta.Get(name)
+	if err != nil {
+		return nil
+	}
+	num, den, err := tag.Rat2(0)
+	if err != nil || den == 0 || num <= 0 {
+		return nil
+	}
+	value := float64(num) / float64(den)
+	return &value
+}
+
+func isValidGPSCoordinate(lat, long float64) bool {
+	if math.IsNaN(lat) || math.IsNaN(long) || (lat == 0 && long == 0) {
+		return false
+	}
+	return lat >= -90 && lat <= 90 && long >= -180 && long <= 180
+}
+
+func imageEXIFFields(record *model.WorkImageEXIF) []ImageEXIFField {
+	fields := make([]ImageEXIFField, 0)
+	if record == nil || record.Fields == "" {
+		return fields
+	}
+	if err := json.Unmarshal([]byte(record.Fields), &fields); err != nil {
+		return make([]ImageEXIFField, 0)
+	}
+	return fields
+}
+
+func imageEXIFSummary(record *model.WorkImageEXIF) *ImageEXIFSummary {
+	if record == nil || !record.HasEXIF {
+		return nil
+	}
+	summary := &ImageEXIFSummary{
+		CameraMake:   record.CameraMake,
+		CameraModel:  record.CameraModel,
+		LensModel:    record.LensModel,
+		FocalLength:  record.FocalLength,
+		FNumber:      record.FNumber,
+		ExposureTime: formatExposureTime(record.ExposureTime),
+		ISO:          record.ISO,
+		GPSLatitude:  record.GPSLatitude,
+		GPSLongitude: record.GPSLongitude,
+	}
+	if record.TakenAt != nil {
+		summary.TakenAt = record.TakenAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return summary
+}
+
+func redactImageEXIFInfo(info *ImageEXIFInfo) {
+	fields := make([]ImageEXIFField, 0, len(info.Fields))
+	for _, field := range info.Fields {
+		if strings.HasPrefix(field.Key, "GPS") || field.Key == string(exif.MakerNote) || field.Key == string(exif.ImageUniqueID) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	info.Fields = fields
+	if info.Summary != nil {
+		info.Summary.GPSLatitude = nil
+		info.Summary.GPSLongitude = nil
+	}
+}
+
+func formatExposureTime(seconds *float64) string {
+	if seconds == nil || *seconds <= 0 {
+		return ""
+	}
+	if *seconds < 1 {
+		return "1/" + strconv.FormatFloat(math.Round(1 / *seconds), 'f', -1, 64)
+	}
+	return strconv.FormatFloat(*seconds, 'f', -1, 64)
+}
+
+type imageEXIFWalker struct {
+	fields []ImageEXIFField
+}
+
+func (w *imageEXIFWalker) Walk(name exif.FieldName, tag *tiff.Tag) error {
+	if tag == nil {
+		return nil
+	}
+	w.fields = append(w.fields, ImageEXIFField{
+		Key:   string(name),
+		Value: strings.TrimSpace(tag.String()),
+	})
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+)
+
+const animatedDecodeMaxPixels = 256 * pixelsPerMegapixel
+
+var psdSignature = []byte("8BPS")
+
+func decodeImageSafely(data []byte) (img image.Image, format string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			img, format, err = nil, "", fmt.Errorf("%w: %v", ErrImageMalformed, recovered)
+		}
+	}()
+	return image.Decode(bytes.NewReader(data))
+}
+
+func decodeImageConfigSafely(data []byte) (config image.Config, format string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			config, format, err = image.Config{}, "", fmt.Errorf("%w: %v", ErrImageMalformed, recovered)
+		}
+	}()
+	return image.DecodeConfig(bytes.NewReader(data))
+}
+
+func readImageDimensions(data []byte) (int, int, bool) {
+	if config, _, err := decodeImageConfigSafely(data); err == nil {
+		return config.Width, config.Height, true
+	}
+	if width, height, ok := readPSDDimensions(data); ok {
+		return width, height, true
+	}
+	return readHEIFDimensions(data)
+}
+
+func readPSDDimensions(data []byte) (int, int, bool) {
+	if len(data) < 26 || !bytes.HasPrefix(data, psdSignature) {
+		return 0, 0, false
+	}
+	height := binary.BigEndian.Uint32(data[14:18])
+	width := binary.BigEndian.Uint32(data[18:22])
+	return int(width), int(height), true
+}
+
+func readHEIFDimensions(data []byte) (int, int, bool) {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return 0, 0, false
+	}
+	meta := findISOBMFFBoxBody(readISOBMFFBoxes(data), "meta")
+	if len(meta) < 4 {
+		return 0, 0, false
+	}
+	iprp := findISOBMFFBoxBody(readISOBMFFBoxes(meta[4:]), "iprp")
+	ipco := findISOBMFFBoxBody(readISOBMFFBoxes(iprp), "ipco")
+
+	width, height := 0, 0
+	for _, box := range readISOBMFFBoxes(ipco) {
+		if box.boxType != "ispe" || len(box.body) < 12 {
+			continue
+		}
+		w := int(binary.BigEndian.Uint32(box.body[4:8]))
+		h := int(binary.BigEndian.Uint32(box.body[8:12]))
+		if int64(w)*int64(h) > int64(width)*int64(height) {
+			width, height = w, h
+		}
+	}
+	return width, height, width > 0 && height > 0
+}
+
+func (l *UploadLimits) CheckImageData(filename string, data []byte) error {
+	width, height, ok := readImageDimensions(data)
+	if !ok {
+		return nil
+	}
+	return l.CheckPixels(filename, width, height)
+}
+
+func animationWithinDecodeBudget(data []byte, frameCount int) bool {
+	width, height, ok := readImageDimensions(data)
+	if !ok {
+		return true
+	}
+	return int64(width)*int64(height)*int64(frameCount) <= animatedDecodeMaxPixels
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const fuzzDecodeMaxPixels = 4 * pixelsPerMegapixel
+
+func encodeTestPNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testPSDHeader(width, height uint32) []byte {
+	header := make([]byte, 26)
+	copy(header, psdSignature)
+	binary.BigEndian.PutUint16(header[4:6], 1)
+	binary.BigEndian.PutUint16(header[12:14], 3)
+	binary.BigEndian.PutUint32(header[14:18], height)
+	binary.BigEndian.PutUint32(header[18:22], width)
+	binary.BigEndian.PutUint16(header[22:24], 8)
+	binary.BigEndian.PutUint16(header[24:26], 3)
+	return header
+}
+
+func addImageGuardSeeds(f *testing.F) {
+	pngData := encodeTestPNG(f, 3, 2)
+	heic := readTestdata(f, "heic_exif.heic")
+	seeds := [][]byte{
+		nil,
+		pngData,
+		pngData[:8],
+		pngData[:16],
+		pngData[:len(pngData)-12],
+		testPSDHeader(4000, 3000),
+		testPSDHeader(4000, 3000)[:20],
+		heic,
+		heic[:12],
+		heic[:64],
+		heic[:len(heic)/2],
+		readTestdata(f, "avif_exif.avif"),
+		readTestdata(f, "webp_exif.webp"),
+		[]byte("GIF89a\x01\x00\x01\x00"),
+		{0xFF, 0xD8, 0xFF, 0xC0, 0x00, 0x11, 0x08, 0xFF, 0xFF, 0xFF, 0xFF},
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+}
+
+func FuzzDecodeImageSafely(f *testing.F) {
+	addImageGuardSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		config, _, err := decodeImageConfigSafely(data)
+		if err != nil || int64(config.Width)*int64(config.Height) > fuzzDecodeMaxPixels {
+			return
+		}
+		img, _, err := decodeImageSafely(data)
+		if err == nil && img == nil {
+			t.Fatalf("decodeImageSafely returned nil image without error")
+		}
+	})
+}
+
+func FuzzReadImageDimensions(f *testing.F) {
+	addImageGuardSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		width, height, ok := readImageDimensions(data)
+		if !ok && (width != 0 || height != 0) {
+			t.Fatalf("readImageDimensions = %d, %d, false; want zero size when not ok", width, height)
+		}
+		limits := &UploadLimits{MaxPixels: DefaultUploadMaxMegapixels * pixelsPerMegapixel}
+		_ = limits.CheckImageData("fuzz", data)
+		_ = animationWithinDecodeBudget(data, 100)
+	})
+}
+
+func FuzzReadHEIFDimensions(f *testing.F) {
+	addImageGuardSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		width, height, ok := readHEIFDimensions(data)
+		if ok && (width <= 0 || height <= 0) {
+			t.Fatalf("readHEIFDimensions = %d, %d, true; want positive size", width, height)
+		}
+	})
+}
+
+func TestReadImageDimensions(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		width, height int
+		ok            bool
+	}{
+		{name: "png", data: encodeTestPNG(t, 3, 2), width: 3, height: 2, ok: true},
+		{name: "psd", data: testPSDHeader(4000, 3000), width: 4000, height: 3000, ok: true},
+		{name: "truncated psd", data: testPSDHeader(4000, 3000)[:20]},
+		{name: "heic", data: readTestdata(t, "heic_exif.heic"), width: 640, height: 480, ok: true},
+		{name: "truncated heic", data: readTestdata(t, "heic_exif.heic")[:64]},
+		{name: "garbage", data: []byte("not an image")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, ok := readImageDimensions(tt.data)
+			if width != tt.width || height != tt.height || ok != tt.ok {
+				t.Fatalf("readImageDimensions() = %d, %d, %v; want %d, %d, %v", width, height, ok, tt.width, tt.height, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckImageDataRejectsOversizedHeaders(t *testing.T) {
+	limits := &UploadLimits{MaxPixels: 10 * pixelsPerMegapixel}
+	err := limits.CheckImageData("huge.psd", testPSDHeader(30000, 30000))
+	limitErr, ok := err.(*UploadLimitError)
+	if !ok || limitErr.Code != ErrCodeUploadPixelsExceeded {
+		t.Fatalf("CheckImageData() error = %v, want pixel limit error", err)
+	}
+	if err := limits.CheckImageData("small.png", encodeTestPNG(t, 3, 2)); err != nil {
+		t.Fatalf("CheckImageData() error = %v, want nil", err)
+	}
+}
+
+func TestWriteImageMagickPolicy(t *testing.T) {
+	dir, err := ensureImageMagickPolicy()
+	if err != nil {
+		t.Fatalf("ensureImageMagickPolicy() error = %v", err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		t.Fatalf("lstat policy dir: %v", err)
+	}
+	if !info.IsDir() || info.Mode().Perm() != 0700 {
+		t.Fatalf("policy dir mode = %v, want owner-only directory", info.Mode())
+	}
+	if dir == filepath.Join(os.TempDir(), "illust-nest-imagemagick") {
+		t.Fatalf("policy dir %s is predictable", dir)
+	}
+	policy, err := os.Stat(filepath.Join(dir, "policy.xml"))
+	if err != nil || policy.Mode().Perm() != 0600 {
+		t.Fatalf("policy file = %v, %v; want 0600 file", policy, err)
+	}
+
+	target := filepath.Join(t.TempDir(), "policy.xml")
+	if err := os.Symlink(filepath.Join(t.TempDir(), "elsewhere"), target); err != nil {
+		t.Skipf("symlink unsupported: %v", err)
+	}
+	if err := writeImageMagickPolicy(target); err == nil {
+		t.Fatalf("writeImageMagickPolicy followed an existing symlink")
+	}
+}
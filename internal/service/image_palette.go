@@ -0,0 +1,348 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"illust-nest/internal/model"
+	"illust-nest/internal/repository"
+	"image"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	paletteSize             = 5
+	paletteSampleSize       = 64
+	paletteIterations       = 12
+	DefaultColorTolerance   = 20.0
+	colorMatchMinWeight     = 0.05
+	paletteAlphaThreshold   = 128
+	paletteEmptyPlaceholder = "[]"
+)
+
+type labColor struct {
+	L float64
+	A float64
+	B float64
+}
+
+type ImagePaletteService struct {
+	paletteRepo *repository.ImagePaletteRepository
+}
+
+func NewImagePaletteService(paletteRepo *repository.ImagePaletteRepository) *ImagePaletteService {
+	return &ImagePaletteService{paletteRepo: paletteRepo}
+}
+
+func (s *ImagePaletteService) SyncImage(imageID, workID uint, palette []ImagePaletteColor) {
+	if s == nil || palette == nil {
+		return
+	}
+	if err := s.save(imageID, workID, palette); err != nil {
+		log.Printf("Failed to index palette for image %d: %v", imageID, err)
+	}
+}
+
+func (s *ImagePaletteService) BackfillImagePalettes() error {
+	if _, err := GetStorageProvider(); err != nil {
+		return err
+	}
+	images, err := s.paletteRepo.FindImagesWithoutPalette()
+	if err != nil {
+		return err
+	}
+	for i := range images {
+		palette, err := readStoredImagePalette(images[i].ThumbnailPath)
+		if err != nil {
+			log.Printf("Failed to read thumbnail %d for palette backfill, recording an empty palette: %v", images[i].ID, err)
+			palette = []ImagePaletteColor{}
+		}
+		if err := s.save(images[i].ID, images[i].WorkID, palette); err != nil {
+			log.Printf("Failed to index palette for image %d: %v", images[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ImagePaletteService) save(imageID, workID uint, palette []ImagePaletteColor) error {
+	colors := make([]model.WorkImageColor, 0, len(palette))
+	for i, item := range palette {
+		lab, ok := hexToLab(item.Hex)
+		if !ok {
+			continue
+		}
+		colors = append(colors, model.WorkImageColor{
+			ImageID: imageID,
+			Rank:    i,
+			WorkID:  workID,
+			Hex:     item.Hex,
+			Weight:  item.Weight,
+			LabL:    lab.L,
+			LabA:    lab.A,
+			LabB:    lab.B,
+		})
+	}
+	return s.paletteRepo.Save(imageID, encodeImagePalette(palette), colors)
+}
+
+func encodeImagePalette(palette []ImagePaletteColor) string {
+	if palette == nil {
+		return ""
+	}
+	if len(palette) == 0 {
+		return paletteEmptyPlaceholder
+	}
+	data, err := json.Marshal(palette)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func readStoredImagePalette(thumbnailPath string) ([]ImagePaletteColor, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	file, _, err := storage.Get(context.Background(), thumbnailPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return extractImagePalette(img), nil
+}
+
+func parseImagePalette(raw string) []ImagePaletteColor {
+	if raw == "" || raw == paletteEmptyPlaceholder {
+		return nil
+	}
+	var palette []ImagePaletteColor
+	if err := json.Unmarshal([]byte(raw), &palette); err != nil {
+		return nil
+	}
+	return palette
+}
+
+func extractImagePalette(img image.Image) []ImagePaletteColor {
+	sample := imaging.Fit(img, paletteSampleSize, paletteSampleSize, imaging.Box)
+	pixels := make([]labColor, 0, len(sample.Pix)/4)
+	for i := 0; i+3 < len(sample.Pix); i += 4 {
+		if sample.Pix[i+3] < paletteAlphaThreshold {
+			continue
+		}
+		pixels = append(pixels, rgbToLab(sample.Pix[i], sample.Pix[i+1], sample.Pix[i+2]))
+	}
+	if len(pixels) == 0 {
+		return []ImagePaletteColor{}
+	}
+
+	centroids := medianCutSeeds(pixels, paletteSize)
+	assignments := make([]int, len(pixels))
+	counts := make([]int, len(centroids))
+	for iteration := 0; iteration < paletteIterations; iteration++ {
+		changed := iteration == 0
+		for i, pixel := range pixels {
+			nearest := nearestCentroid(pixel, centroids)
+			if nearest != assignments[i] {
+				assignments[i] = nearest
+				changed = true
+			}
+		}
+
+		sums := make([]labColor, len(centroids))
+		counts = make([]int, len(centroids))
+		for i, pixel := range pixels {
+			cluster := assignments[i]
+			sums[cluster].L += pixel.L
+			sums[cluster].A += pixel.A
+			sums[cluster].B += pixel.B
+			counts[cluster]++
+		}
+		for i := range centroids {
+			if counts[i] == 0 {
+				continue
+			}
+			n := float64(counts[i])
+			centroids[i] = labColor{L: sums[i].L / n, A: sums[i].A / n, B: sums[i].B / n}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	palette := make([]ImagePaletteColor, 0, len(centroids))
+	for i, centroid := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+		palette = append(palette, ImagePaletteColor{
+			Hex:    labToHex(centroid),
+			Weight: math.Round(float64(counts[i])/float64(len(pixels))*10000) / 10000,
+		})
+	}
+	sort.SliceStable(palette, func(i, j int) bool {
+		return palette[i].Weight > palette[j].Weight
+	})
+	return palette
+}
+
+func medianCutSeeds(pixels []labColor, count int) []labColor {
+	buckets := [][]labColor{append([]labColor(nil), pixels...)}
+	for len(buckets) < count {
+		target, axis, widest := -1, 0, 0.0
+		for i, bucket := range buckets {
+			if len(bucket) < 2 {
+				continue
+			}
+			for channel := 0; channel < 3; channel++ {
+				low, high := math.Inf(1), math.Inf(-1)
+				for _, pixel := range bucket {
+					value := labChannel(pixel, channel)
+					low = math.Min(low, value)
+					high = math.Max(high, value)
+				}
+				if high-low > widest {
+					target, axis, widest = i, channel, high-low
+				}
+			}
+		}
+		if target < 0 {
+			break
+		}
+
+		bucket := buckets[target]
+		sort.Slice(bucket, func(i, j int) bool {
+			return labChannel(bucket[i], axis) < labChannel(bucket[j], axis)
+		})
+		middle := len(bucket) / 2
+		buckets[target] = bucket[:middle]
+		buckets = append(buckets, bucket[middle:])
+	}
+
+	seeds := make([]labColor, 0, len(buckets))
+	for _, bucket := range buckets {
+		var sum labColor
+		for _, pixel := range bucket {
+			sum.L += pixel.L
+			sum.A += pixel.A
+			sum.B += pixel.B
+		}
+		n := float64(len(bucket))
+		seeds = append(seeds, labColor{L: sum.L / n, A: sum.A / n, B: sum.B / n})
+	}
+	return seeds
+}
+
+func labChannel(color labColor, channel int) float64 {
+	switch channel {
+	case 0:
+		return color.L
+	case 1:
+		return color.A
+	default:
+		return color.B
+	}
+}
+
+func nearestCentroid(pixel labColor, centroids []labColor) int {
+	nearest, best := 0, math.Inf(1)
+	for i, centroid := range centroids {
+		if distance := labDistanceSquared(pixel, centroid); distance < best {
+			nearest, best = i, distance
+		}
+	}
+	return nearest
+}
+
+func labDistanceSquared(a, b labColor) float64 {
+	dl, da, db := a.L-b.L, a.A-b.A, a.B-b.B
+	return dl*dl + da*da + db*db
+}
+
+func rgbToLab(r, g, b uint8) labColor {
+	lr, lg, lb := srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+	x := (0.4124564*lr + 0.3575761*lg + 0.1804375*lb) / 0.95047
+	y := 0.2126729*lr + 0.7151522*lg + 0.0721750*lb
+	z := (0.0193339*lr + 0.1191920*lg + 0.9503041*lb) / 1.08883
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return labColor{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+func labToHex(color labColor) string {
+	fy := (color.L + 16) / 116
+	fx := fy + color.A/500
+	fz := fy - color.B/200
+	x, y, z := labFInverse(fx)*0.95047, labFInverse(fy), labFInverse(fz)*1.08883
+	r := 3.2404542*x - 1.5371385*y - 0.4985314*z
+	g := -0.9692660*x + 1.8760108*y + 0.0415560*z
+	b := 0.0556434*x - 0.2040259*y + 1.0572252*z
+	return fmt.Sprintf("#%02x%02x%02x", linearToSRGB(r), linearToSRGB(g), linearToSRGB(b))
+}
+
+func hexToLab(value string) (labColor, bool) {
+	cleaned := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(cleaned) == 3 {
+		cleaned = string([]byte{cleaned[0], cleaned[0], cleaned[1], cleaned[1], cleaned[2], cleaned[2]})
+	}
+	if len(cleaned) != 6 {
+		return labColor{}, false
+	}
+	rgb, err := strconv.ParseUint(cleaned, 16, 32)
+	if err != nil {
+		return labColor{}, false
+	}
+	return rgbToLab(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb)), true
+}
+
+func srgbToLinear(value uint8) float64 {
+	c := float64(value) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(value float64) uint8 {
+	c := value * 12.92
+	if value > 0.0031308 {
+		c = 1.055*math.Pow(value, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(math.Max(0, math.Min(1, c)) * 255))
+}
+
+func labF(t float64) float64 {
+	if t > 216.0/24389.0 {
+		return math.Cbrt(t)
+	}
+	return (24389.0/27.0*t + 16) / 116
+}
+
+func labFInverse(t float64) float64 {
+	if t*t*t > 216.0/24389.0 {
+		return t * t * t
+	}
+	return (116*t - 16) / (24389.0 / 27.0)
+}
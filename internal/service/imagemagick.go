@@ -1,10 +1,15 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"os/exec"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -12,6 +17,39 @@ const (
 	ImageMagickVersionV7 = "v7"
 )
 
+const (
+	imageMagickMemoryLimit = "256MiB"
+	imageMagickMapLimit    = "512MiB"
+	imageMagickDiskLimit   = "1GiB"
+)
+
+const imageMagickPolicy = `<?xml version="1.0" encoding="UTF-8"?>
+<policymap>
+  <policy domain="resource" name="memory" value="256MiB"/>
+  <policy domain="resource" name="map" value="512MiB"/>
+  <policy domain="resource" name="disk" value="1GiB"/>
+  <policy domain="resource" name="width" value="64KP"/>
+  <policy domain="resource" name="height" value="64KP"/>
+  <policy domain="delegate" rights="none" pattern="URL"/>
+  <policy domain="delegate" rights="none" pattern="HTTPS"/>
+  <policy domain="delegate" rights="none" pattern="HTTP"/>
+  <policy domain="coder" rights="none" pattern="MVG"/>
+  <policy domain="coder" rights="none" pattern="MSL"/>
+  <policy domain="coder" rights="none" pattern="EPHEMERAL"/>
+  <policy domain="coder" rights="none" pattern="URL"/>
+  <policy domain="coder" rights="none" pattern="HTTPS"/>
+  <policy domain="coder" rights="none" pattern="HTTP"/>
+  <policy domain="coder" rights="none" pattern="FTP"/>
+  <policy domain="path" rights="none" pattern="@*"/>
+</policymap>
+`
+
+var (
+	imageMagickPolicyOnce sync.Once
+	imageMagickPolicyDir  string
+	imageMagickPolicyErr  error
+)
+
 func normalizeImageMagickVersion(version string) string {
 	cleaned := strings.ToLower(strings.TrimSpace(version))
 	if cleaned == "" {
@@ -34,22 +72,15 @@ func resolveImageMagickCommand(version string) (string, error) {
 	}
 }
 
-func testImageMagickCommand(version string) (string, error) {
+func testImageMagickCommand(ctx context.Context, version string) (string, error) {
 	cmdName, err := resolveImageMagickCommand(version)
 	if err != nil {
 		return "", err
 	}
-	if _, err := exec.LookPath(cmdName); err != nil {
-		return "", fmt.Errorf("command not found: %s", cmdName)
-	}
 
-	out, err := exec.Command(cmdName, "-version").CombinedOutput()
+	out, err := runConverterProbe(ctx, cmdName, []string{"-version"}, imageMagickEnv())
 	if err != nil {
-		msg := strings.TrimSpace(string(out))
-		if msg == "" {
-			msg = err.Error()
-		}
-		return "", fmt.Errorf("failed to run %s -version: %s", cmdName, msg)
+		return "", err
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
@@ -59,22 +90,126 @@ func testImageMagickCommand(version string) (string, error) {
 	return strings.TrimSpace(lines[0]), nil
 }
 
-func runImageMagick(version string, args ...string) error {
+func listImageMagickFormats(ctx context.Context, version string) ([]ImageMagickFormat, error) {
 	cmdName, err := resolveImageMagickCommand(version)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runConverterProbe(ctx, cmdName, []string{"-list", "format"}, imageMagickEnv())
+	if err != nil {
+		return nil, err
+	}
+	return parseImageMagickFormats(string(out)), nil
+}
+
+func parseImageMagickFormats(output string) []ImageMagickFormat {
+	formats := []ImageMagickFormat{}
+	started := false
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "---") {
+			started = true
+			continue
+		}
+		if !started || trimmed == "" || !strings.HasPrefix(line, " ") {
+			continue
+		}
+		fields := strings.Fields(trimmed)
+		if len(fields) < 2 {
+			continue
+		}
+		modeIndex := 1
+		if !isImageMagickMode(fields[modeIndex]) && len(fields) >= 3 {
+			modeIndex = 2
+		}
+		if !isImageMagickMode(fields[modeIndex]) {
+			continue
+		}
+		formats = append(formats, ImageMagickFormat{
+			Name:        strings.TrimSuffix(fields[0], "*"),
+			Mode:        fields[modeIndex],
+			Description: strings.Join(fields[modeIndex+1:], " "),
+		})
+	}
+	return formats
+}
+
+func isImageMagickMode(value string) bool {
+	if len(value) != 3 {
+		return false
+	}
+	for _, ch := range value {
+		if !strings.ContainsRune("rw+-", ch) {
+			return false
+		}
+	}
+	return true
+}
+
+func runImageMagick(ctx context.Context, cfg *imageMagickSettings, args ...string) error {
+	cmdName, err := resolveImageMagickCommand(cfg.Version)
 	if err != nil {
 		return err
 	}
-	if _, err := exec.LookPath(cmdName); err != nil {
-		return fmt.Errorf("command not found: %s", cmdName)
+
+	fullArgs := append(imageMagickResourceArgs(cfg.MaxPixels), args...)
+	_, err = runConverter(ctx, cmdName, fullArgs, imageMagickEnv())
+	return err
+}
+
+func imageMagickResourceArgs(maxPixels int64) []string {
+	if maxPixels <= 0 {
+		maxPixels = DefaultUploadMaxMegapixels * pixelsPerMegapixel
 	}
+	return []string{
+		"-limit", "memory", imageMagickMemoryLimit,
+		"-limit", "map", imageMagickMapLimit,
+		"-limit", "disk", imageMagickDiskLimit,
+		"-limit", "area", strconv.FormatInt(maxPixels, 10),
+		"-limit", "time", strconv.Itoa(int(converterTimeout() / time.Second)),
+	}
+}
 
-	out, err := exec.Command(cmdName, args...).CombinedOutput()
+func imageMagickEnv() []string {
+	env := os.Environ()
+	dir, err := ensureImageMagickPolicy()
 	if err != nil {
-		msg := strings.TrimSpace(string(out))
-		if msg == "" {
-			msg = err.Error()
+		log.Printf("Failed to write ImageMagick policy: %v", err)
+		return env
+	}
+	configurePath := dir
+	if existing := os.Getenv("MAGICK_CONFIGURE_PATH"); existing != "" {
+		configurePath += string(os.PathListSeparator) + existing
+	}
+	return append(env, "MAGICK_CONFIGURE_PATH="+configurePath)
+}
+
+func ensureImageMagickPolicy() (string, error) {
+	imageMagickPolicyOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "illust-nest-imagemagick-*")
+		if err != nil {
+			imageMagickPolicyErr = err
+			return
+		}
+		if err := writeImageMagickPolicy(filepath.Join(dir, "policy.xml")); err != nil {
+			os.RemoveAll(dir)
+			imageMagickPolicyErr = err
+			return
 		}
-		return fmt.Errorf("failed to run %s: %s", cmdName, msg)
+		imageMagickPolicyDir = dir
+	})
+	return imageMagickPolicyDir, imageMagickPolicyErr
+}
+
+func writeImageMagickPolicy(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(imageMagickPolicy); err != nil {
+		file.Close()
+		return err
 	}
-	return nil
+	return file.Close()
 }
@@ -0,0 +1,571 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"illust-nest/internal/model"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	videoPlaceholderWidth  = 400
+	videoPlaceholderHeight = 225
+	videoAnimatedSeconds   = "4"
+	ugoiraDefaultDelayMs   = 100
+	ugoiraMaxFrames        = 1000
+	ugoiraMaxFrameBytes    = 64 * 1024 * 1024
+)
+
+type ugoiraFrame struct {
+	Ext   string
+	Data  []byte
+	Delay int
+	Image image.Image
+}
+
+type ugoiraFrameMeta struct {
+	File  string `json:"file"`
+	Delay int    `json:"delay"`
+}
+
+func isVideoUpload(file *multipart.FileHeader) bool {
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".mp4", ".m4v", ".mov", ".webm":
+		return true
+	}
+	return false
+}
+
+func isUgoiraUpload(file *multipart.FileHeader) bool {
+	if strings.ToLower(filepath.Ext(file.Filename)) == ".zip" {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(file.Header.Get("Content-Type"))) {
+	case "application/zip", "application/x-zip-compressed":
+		return true
+	}
+	return false
+}
+
+func videoContentType(ext string) string {
+	switch ext {
+	case ".mp4", ".m4v":
+		return "video/mp4"
+	case ".mov":
+		return "video/quicktime"
+	case ".webm":
+		return "video/webm"
+	}
+	return "application/octet-stream"
+}
+
+func (s *ImageService) FFmpegEnabled() bool {
+	if s.settingRepo == nil {
+		return false
+	}
+	setting, err := s.settingRepo.Get("ffmpeg_enabled")
+	if err != nil {
+		return false
+	}
+	return setting.Value == "true"
+}
+
+func (s *ImageService) processVideo(ctx context.Context, file *multipart.FileHeader, limits *UploadLimits) (*UploadedImage, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	originalBytes, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := videoContainerExt(originalBytes)
+	if ext == "" {
+		return nil, fmt.Errorf("%w: %s", ErrVideoMalformed, file.Filename)
+	}
+	info, err := probeVideo(originalBytes)
+	if err != nil && s.FFmpegEnabled() {
+		log.Printf("Failed to read video metadata from %s, falling back to ffprobe: %v", file.Filename, err)
+		info, err = probeVideoWithFFprobe(ctx, originalBytes, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrVideoMalformed, file.Filename, err)
+	}
+	if err := limits.CheckPixels(file.Filename, info.Width, info.Height); err != nil {
+		return nil, err
+	}
+
+	uuid := generateUUID()
+	originalLogicalPath := s.getStoragePath("originals", uuid, ext)
+	thumbnailLogicalPath := s.getStoragePath("thumbnails", uuid, ".jpg")
+	if err := storage.Put(
+		context.Background(),
+		originalLogicalPath,
+		bytes.NewReader(originalBytes),
+		int64(len(originalBytes)),
+		videoContentType(ext),
+	); err != nil {
+		return nil, err
+	}
+
+	var poster image.Image
+	var animatedThumbnail []byte
+	if s.FFmpegEnabled() {
+		poster, animatedThumbnail, err = s.renderVideoPoster(ctx, originalBytes, ext)
+		if err != nil {
+			log.Printf("Failed to extract poster frame from %s: %v", file.Filename, err)
+		}
+	}
+
+	palette := []ImagePaletteColor{}
+	if poster != nil {
+		if info.Width == 0 || info.Height == 0 {
+			info.Width, info.Height = poster.Bounds().Dx(), poster.Bounds().Dy()
+		}
+		palette = extractImagePalette(poster)
+	} else {
+		poster = videoPlaceholderPoster(info.Width, info.Height)
+	}
+
+	if err := s.putThumbnail(thumbnailLogicalPath, poster); err != nil {
+		return nil, err
+	}
+
+	animatedThumbnailPath := ""
+	if len(animatedThumbnail) > 0 {
+		animatedThumbnailPath = s.putAnimatedThumbnail(animatedThumbnail, uuid)
+	}
+
+	return &UploadedImage{
+		StoragePath:           originalLogicalPath,
+		ThumbnailPath:         thumbnailLogicalPath,
+		FileSize:              file.Size,
+		EXIF:                  &model.WorkImageEXIF{},
+		Palette:               palette,
+		Width:                 info.Width,
+		Height:                info.Height,
+		OriginalFilename:      file.Filename,
+		MediaType:             model.MediaTypeVideo,
+		DurationMs:            info.DurationMs,
+		AnimatedThumbnailPath: animatedThumbnailPath,
+	}, nil
+}
+
+func (s *ImageService) renderVideoPoster(ctx context.Context, originalBytes []byte, ext string) (image.Image, []byte, error) {
+	tempDir, err := os.MkdirTemp("", "illust-nest-ffmpeg-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	tempInputPath := filepath.Join(tempDir, "input"+ext)
+	tempPosterPath := filepath.Join(tempDir, "poster.png")
+	if err := os.WriteFile(tempInputPath, originalBytes, 0644); err != nil {
+		return nil, nil, err
+	}
+
+	if err := runFFmpeg(ctx, "-i", tempInputPath, "-vf", "thumbnail", "-frames:v", "1", "-an", tempPosterPath); err != nil {
+		return nil, nil, fmt.Errorf("FFmpeg poster extraction failed: %w", err)
+	}
+	poster, err := imaging.Open(tempPosterPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open poster frame: %w", err)
+	}
+
+	if !s.AnimatedThumbnailsEnabled() {
+		return poster, nil, nil
+	}
+	tempAnimatedPath := filepath.Join(tempDir, "animated.gif")
+	filter := fmt.Sprintf("fps=10,scale='min(%d,iw)':-1:flags=lanczos,split[a][b];[a]palettegen[p];[b][p]paletteuse", thumbnailMaxWidth)
+	if err := runFFmpeg(ctx, "-t", videoAnimatedSeconds, "-i", tempInputPath, "-vf", filter, "-loop", "0", tempAnimatedPath); err != nil {
+		log.Printf("Failed to generate animated video thumbnail: %v", err)
+		return poster, nil, nil
+	}
+	animated, err := os.ReadFile(tempAnimatedPath)
+	if err != nil {
+		log.Printf("Failed to read animated video thumbnail: %v", err)
+		return poster, nil, nil
+	}
+	return poster, animated, nil
+}
+
+func videoPlaceholderPoster(width, height int) image.Image {
+	posterWidth, posterHeight := videoPlaceholderWidth, videoPlaceholderHeight
+	if width > 0 && height > 0 {
+		posterHeight = posterWidth * height / width
+		if posterHeight < 1 {
+			posterHeight = 1
+		}
+	}
+
+	poster := image.NewNRGBA(image.Rect(0, 0, posterWidth, posterHeight))
+	draw.Draw(poster, poster.Bounds(), image.NewUniform(color.NRGBA{R: 38, G: 38, B: 42, A: 255}), image.Point{}, draw.Src)
+
+	size := posterHeight / 3
+	if size > posterWidth/3 {
+		size = posterWidth / 3
+	}
+	centerX, centerY := posterWidth/2, posterHeight/2
+	left := centerX - size/3
+	foreground := color.NRGBA{R: 220, G: 220, B: 224, A: 255}
+	for x := 0; x < size; x++ {
+		half := (size - x) / 2
+		for y := centerY - half; y <= centerY+half; y++ {
+			poster.SetNRGBA(left+x, y, foreground)
+		}
+	}
+	return poster
+}
+
+func (s *ImageService) processUgoira(ctx context.Context, file *multipart.FileHeader, limits *UploadLimits) (*UploadedImage, error) {
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	originalBytes, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := readUgoiraArchive(originalBytes, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	uuid := generateUUID()
+	originalLogicalPath := s.getStoragePath("originals", uuid, ".zip")
+	thumbnailLogicalPath := s.getStoragePath("thumbnails", uuid, ".jpg")
+	if err := storage.Put(
+		context.Background(),
+		originalLogicalPath,
+		bytes.NewReader(originalBytes),
+		int64(len(originalBytes)),
+		"application/zip",
+	); err != nil {
+		return nil, err
+	}
+
+	first := frames[0].Image
+	if err := s.putThumbnail(thumbnailLogicalPath, first); err != nil {
+		return nil, err
+	}
+
+	animationBytes, animationExt, contentType, err := s.encodeUgoiraAnimation(ctx, frames)
+	if err != nil {
+		return nil, fmt.Errorf("ugoira conversion failed: %w", err)
+	}
+	transcodedLogicalPath := s.getStoragePath("transcoded", uuid+"-transcoded", animationExt)
+	if err := storage.Put(
+		context.Background(),
+		transcodedLogicalPath,
+		bytes.NewReader(animationBytes),
+		int64(len(animationBytes)),
+		contentType,
+	); err != nil {
+		return nil, err
+	}
+
+	durationMs := 0
+	for _, frame := range frames {
+		durationMs += frame.Delay
+	}
+
+	animatedThumbnailPath := ""
+	if s.AnimatedThumbnailsEnabled() {
+		thumbnail, err := encodeFramesAsGIF(frames, thumbnailMaxWidth)
+		if err != nil {
+			log.Printf("Failed to generate animated thumbnail: %v", err)
+		} else {
+			animatedThumbnailPath = s.putAnimatedThumbnail(thumbnail, uuid)
+		}
+	}
+
+	return &UploadedImage{
+		StoragePath:           originalLogicalPath,
+		ThumbnailPath:         thumbnailLogicalPath,
+		TranscodedPath:        transcodedLogicalPath,
+		FileSize:              file.Size,
+		EXIF:                  &model.WorkImageEXIF{},
+		Palette:               extractImagePalette(first),
+		Width:                 first.Bounds().Dx(),
+		Height:                first.Bounds().Dy(),
+		OriginalFilename:      file.Filename,
+		MediaType:             model.MediaTypeUgoira,
+		FrameCount:            len(frames),
+		DurationMs:            durationMs,
+		AnimatedThumbnailPath: animatedThumbnailPath,
+	}, nil
+}
+
+func readUgoiraArchive(data []byte, limits *UploadLimits) ([]ugoiraFrame, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrInvalidUgoiraArchive
+	}
+
+	entries := make(map[string]*zip.File)
+	var names []string
+	var metadata []ugoiraFrameMeta
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Base(entry.Name)
+		switch strings.ToLower(path.Ext(name)) {
+		case ".jpg", ".jpeg", ".png", ".gif":
+			if _, exists := entries[name]; !exists {
+				entries[name] = entry
+				names = append(names, name)
+			}
+		case ".json":
+			if metadata != nil {
+				continue
+			}
+			raw, err := readZipEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+			metadata = parseUgoiraFrameMeta(raw)
+		}
+	}
+	if len(names) == 0 {
+		return nil, ErrInvalidUgoiraArchive
+	}
+
+	order := make([]ugoiraFrameMeta, 0, len(names))
+	for _, item := range metadata {
+		if _, ok := entries[path.Base(item.File)]; ok {
+			order = append(order, ugoiraFrameMeta{File: path.Base(item.File), Delay: item.Delay})
+		}
+	}
+	if len(order) == 0 {
+		sort.Strings(names)
+		for _, name := range names {
+			order = append(order, ugoiraFrameMeta{File: name})
+		}
+	}
+	if len(order) > ugoiraMaxFrames {
+		return nil, fmt.Errorf("%w: more than %d frames", ErrInvalidUgoiraArchive, ugoiraMaxFrames)
+	}
+
+	frames := make([]ugoiraFrame, 0, len(order))
+	var decodedPixels int64
+	for _, item := range order {
+		raw, err := readZipEntry(entries[item.File])
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.CheckImageData(item.File, raw); err != nil {
+			return nil, err
+		}
+		if width, height, ok := readImageDimensions(raw); ok {
+			decodedPixels += int64(width) * int64(height)
+			if decodedPixels > animatedDecodeMaxPixels {
+				return nil, fmt.Errorf("%w: frames exceed the %d megapixel decode budget", ErrInvalidUgoiraArchive, animatedDecodeMaxPixels/pixelsPerMegapixel)
+			}
+		}
+		img, _, err := decodeImageSafely(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidUgoiraArchive, item.File, err)
+		}
+		delay := item.Delay
+		if delay <= 0 {
+			delay = ugoiraDefaultDelayMs
+		}
+		frames = append(frames, ugoiraFrame{
+			Ext:   strings.ToLower(path.Ext(item.File)),
+			Data:  raw,
+			Delay: delay,
+			Image: img,
+		})
+	}
+	return frames, nil
+}
+
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	if entry.UncompressedSize64 > ugoiraMaxFrameBytes {
+		return nil, fmt.Errorf("%w: %s is too large", ErrInvalidUgoiraArchive, entry.Name)
+	}
+	file, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, ugoiraMaxFrameBytes))
+}
+
+func parseUgoiraFrameMeta(raw []byte) []ugoiraFrameMeta {
+	var direct []ugoiraFrameMeta
+	if err := json.Unmarshal(raw, &direct); err == nil && len(direct) > 0 {
+		return direct
+	}
+
+	var wrapped struct {
+		Frames []ugoiraFrameMeta `json:"frames"`
+		Body   struct {
+			Frames []ugoiraFrameMeta `json:"frames"`
+		} `json:"body"`
+		UgokuIllustData struct {
+			Frames []ugoiraFrameMeta `json:"frames"`
+		} `json:"ugokuIllustData"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil
+	}
+	switch {
+	case len(wrapped.Frames) > 0:
+		return wrapped.Frames
+	case len(wrapped.Body.Frames) > 0:
+		return wrapped.Body.Frames
+	case len(wrapped.UgokuIllustData.Frames) > 0:
+		return wrapped.UgokuIllustData.Frames
+	}
+	return nil
+}
+
+func (s *ImageService) encodeUgoiraAnimation(ctx context.Context, frames []ugoiraFrame) ([]byte, string, string, error) {
+	if s.FFmpegEnabled() {
+		data, err := encodeUgoiraWithFFmpeg(ctx, frames)
+		if err == nil {
+			return data, ".webp", "image/webp", nil
+		}
+		log.Printf("Failed to convert ugoira with FFmpeg: %v", err)
+	}
+
+	if cfg, err := s.getImageMagickSettings(); err == nil && cfg.Enabled {
+		data, err := encodeUgoiraWithImageMagick(ctx, cfg, frames)
+		if err == nil {
+			return data, ".webp", "image/webp", nil
+		}
+		log.Printf("Failed to convert ugoira with ImageMagick: %v", err)
+	}
+
+	data, err := encodeFramesAsGIF(frames, 0)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return data, ".gif", "image/gif", nil
+}
+
+func writeUgoiraFrames(dir string, frames []ugoiraFrame) ([]string, error) {
+	paths := make([]string, 0, len(frames))
+	for i, frame := range frames {
+		framePath := filepath.Join(dir, fmt.Sprintf("frame_%04d%s", i, frame.Ext))
+		if err := os.WriteFile(framePath, frame.Data, 0644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, framePath)
+	}
+	return paths, nil
+}
+
+func encodeUgoiraWithFFmpeg(ctx context.Context, frames []ugoiraFrame) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "illust-nest-ugoira-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	paths, err := writeUgoiraFrames(tempDir, frames)
+	if err != nil {
+		return nil, err
+	}
+
+	var list strings.Builder
+	list.WriteString("ffconcat version 1.0\n")
+	for i, framePath := range paths {
+		fmt.Fprintf(&list, "file '%s'\nduration %.3f\n", filepath.Base(framePath), float64(frames[i].Delay)/1000)
+	}
+	fmt.Fprintf(&list, "file '%s'\n", filepath.Base(paths[len(paths)-1]))
+	listPath := filepath.Join(tempDir, "frames.ffconcat")
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return nil, err
+	}
+
+	outputPath := filepath.Join(tempDir, "ugoira.webp")
+	if err := runFFmpeg(ctx, "-f", "concat", "-safe", "0", "-i", listPath, "-vsync", "vfr", "-c:v", "libwebp", "-quality", "90", "-loop", "0", outputPath); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(outputPath)
+}
+
+func encodeUgoiraWithImageMagick(ctx context.Context, cfg *imageMagickSettings, frames []ugoiraFrame) ([]byte, error) {
+	tempDir, err := os.MkdirTemp("", "illust-nest-ugoira-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	paths, err := writeUgoiraFrames(tempDir, frames)
+	if err != nil {
+		return nil, err
+	}
+
+	outputPath := filepath.Join(tempDir, "ugoira.webp")
+	args := []string{"-loop", "0"}
+	for i, framePath := range paths {
+		args = append(args, "-delay", fmt.Sprintf("%dx1000", frames[i].Delay), framePath)
+	}
+	args = append(args, "-quality", "90", outputPath)
+	if err := runImageMagick(ctx, cfg, args...); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(outputPath)
+}
+
+func encodeFramesAsGIF(frames []ugoiraFrame, maxWidth int) ([]byte, error) {
+	output := &gif.GIF{LoopCount: 0}
+	for _, frame := range frames {
+		img := frame.Image
+		if maxWidth > 0 && img.Bounds().Dx() > maxWidth {
+			img = imaging.Resize(img, maxWidth, 0, imaging.Linear)
+		}
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, img.Bounds().Min)
+
+		delay := frame.Delay / 10
+		if delay < gifMinFrameDelay {
+			delay = gifMinFrameDelay
+		}
+		output.Image = append(output.Image, paletted)
+		output.Delay = append(output.Delay, delay)
+	}
+
+	var buffer bytes.Buffer
+	if err := gif.EncodeAll(&buffer, output); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+var pinyinArgs = pinyin.NewArgs()
+
+func hanziToPinyin(value string) (string, bool) {
+	var builder strings.Builder
+	hasHan := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			readings := pinyin.SinglePinyin(r, pinyinArgs)
+			if len(readings) == 0 {
+				return "", false
+			}
+			hasHan = true
+			builder.WriteString(readings[0])
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			builder.WriteRune(r)
+		case unicode.IsSpace(r), r == '・', r == '·', r == '_', r == '-':
+			builder.WriteByte(' ')
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			return "", false
+		}
+	}
+
+	if !hasHan {
+		return "", false
+	}
+	return strings.Join(strings.Fields(builder.String()), " "), true
+}
+
+func generateTransliteration(value string) (string, bool) {
+	if romaji, ok := kanaToRomaji(value); ok {
+		return romaji, true
+	}
+	return hanziToPinyin(value)
+}
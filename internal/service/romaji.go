@@ -0,0 +1,127 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+var kanaRomaji = map[string]string{
+	"あ": "a", "い": "i", "う": "u", "え": "e", "お": "o",
+	"か": "ka", "き": "ki", "く": "ku", "け": "ke", "こ": "ko",
+	"さ": "sa", "し": "shi", "す": "su", "せ": "se", "そ": "so",
+	"た": "ta", "ち": "chi", "つ": "tsu", "て": "te", "と": "to",
+	"な": "na", "に": "ni", "ぬ": "nu", "ね": "ne", "の": "no",
+	"は": "ha", "ひ": "hi", "ふ": "fu", "へ": "he", "ほ": "ho",
+	"ま": "ma", "み": "mi", "む": "mu", "め": "me", "も": "mo",
+	"や": "ya", "ゆ": "yu", "よ": "yo",
+	"ら": "ra", "り": "ri", "る": "ru", "れ": "re", "ろ": "ro",
+	"わ": "wa", "ゐ": "wi", "ゑ": "we", "を": "wo", "ん": "n",
+	"が": "ga", "ぎ": "gi", "ぐ": "gu", "げ": "ge", "ご": "go",
+	"ざ": "za", "じ": "ji", "ず": "zu", "ぜ": "ze", "ぞ": "zo",
+	"だ": "da", "ぢ": "ji", "づ": "zu", "で": "de", "ど": "do",
+	"ば": "ba", "び": "bi", "ぶ": "bu", "べ": "be", "ぼ": "bo",
+	"ぱ": "pa", "ぴ": "pi", "ぷ": "pu", "ぺ": "pe", "ぽ": "po",
+	"ゔ": "vu",
+	"ぁ": "a", "ぃ": "i", "ぅ": "u", "ぇ": "e", "ぉ": "o",
+	"ゃ": "ya", "ゅ": "yu", "ょ": "yo", "ゎ": "wa",
+	"きゃ": "kya", "きゅ": "kyu", "きょ": "kyo",
+	"しゃ": "sha", "しゅ": "shu", "しょ": "sho", "しぇ": "she",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho", "ちぇ": "che",
+	"にゃ": "nya", "にゅ": "nyu", "にょ": "nyo",
+	"ひゃ": "hya", "ひゅ": "hyu", "ひょ": "hyo",
+	"みゃ": "mya", "みゅ": "myu", "みょ": "myo",
+	"りゃ": "rya", "りゅ": "ryu", "りょ": "ryo",
+	"ぎゃ": "gya", "ぎゅ": "gyu", "ぎょ": "gyo",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo", "じぇ": "je",
+	"ぢゃ": "ja", "ぢゅ": "ju", "ぢょ": "jo",
+	"びゃ": "bya", "びゅ": "byu", "びょ": "byo",
+	"ぴゃ": "pya", "ぴゅ": "pyu", "ぴょ": "pyo",
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo",
+	"てぃ": "ti", "でぃ": "di", "とぅ": "tu", "どぅ": "du",
+	"うぃ": "wi", "うぇ": "we", "うぉ": "wo",
+	"ゔぁ": "va", "ゔぃ": "vi", "ゔぇ": "ve", "ゔぉ": "vo",
+	"つぁ": "tsa", "つぃ": "tsi", "つぇ": "tse", "つぉ": "tso",
+}
+
+func kanaToRomaji(value string) (string, bool) {
+	runes := []rune(strings.ToLower(strings.TrimSpace(value)))
+	var builder strings.Builder
+	hasKana := false
+	doubleNext := false
+
+	for i := 0; i < len(runes); i++ {
+		r := toHiragana(runes[i])
+
+		if r == 'っ' {
+			hasKana = true
+			doubleNext = true
+			continue
+		}
+		if r == 'ー' {
+			hasKana = true
+			current := builder.String()
+			if current != "" {
+				last := current[len(current)-1]
+				if strings.IndexByte("aeiou", last) >= 0 {
+					builder.WriteByte(last)
+				}
+			}
+			continue
+		}
+
+		if isKana(r) {
+			hasKana = true
+			syllable := ""
+			if i+1 < len(runes) {
+				if romaji, ok := kanaRomaji[string([]rune{r, toHiragana(runes[i+1])})]; ok {
+					syllable = romaji
+					i++
+				}
+			}
+			if syllable == "" {
+				romaji, ok := kanaRomaji[string(r)]
+				if !ok {
+					return "", false
+				}
+				syllable = romaji
+			}
+			if doubleNext {
+				if strings.HasPrefix(syllable, "ch") {
+					builder.WriteByte('t')
+				} else if strings.IndexByte("aeiou", syllable[0]) < 0 {
+					builder.WriteByte(syllable[0])
+				}
+				doubleNext = false
+			}
+			builder.WriteString(syllable)
+			continue
+		}
+
+		doubleNext = false
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			builder.WriteRune(r)
+		case unicode.IsSpace(r), r == '・', r == '_', r == '-':
+			builder.WriteByte(' ')
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			return "", false
+		}
+	}
+
+	if !hasKana {
+		return "", false
+	}
+	return strings.Join(strings.Fields(builder.String()), " "), true
+}
+
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヴ' {
+		return r - ('ァ' - 'ぁ')
+	}
+	return r
+}
+
+func isKana(r rune) bool {
+	return r >= 'ぁ' && r <= 'ゔ'
+}
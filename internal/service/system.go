@@ -1,12 +1,17 @@
 package service
 
 import (
+	"context"
 	"illust-nest/internal/middleware"
 	"illust-nest/internal/repository"
+	"log"
 	"sort"
+	"strconv"
 	"strings"
 )
 
+const statisticsTagListLimit = 20
+
 type SystemService struct {
 	settingRepo    *repository.SettingRepository
 	userRepo       *repository.UserRepository
@@ -91,9 +96,15 @@ func (s *SystemService) Init(req *InitRequest) (*LoginResponse, error) {
 
 func (s *SystemService) GetSettings() (*SystemSettings, error) {
 	settings := &SystemSettings{
-		SiteTitle:          "Illust Nest",
-		ImageMagickEnabled: false,
-		ImageMagickVersion: ImageMagickVersionV7,
+		SiteTitle:            "Illust Nest",
+		ImageMagickEnabled:   false,
+		ImageMagickVersion:   ImageMagickVersionV7,
+		TrashRetentionDays:   DefaultTrashRetentionDays,
+		PublicStripEXIF:      true,
+		UploadMaxRequestMB:   DefaultUploadMaxRequestMB,
+		UploadMaxFileMB:      DefaultUploadMaxFileMB,
+		UploadFormatLimitsMB: map[string]int{},
+		UploadMaxMegapixels:  DefaultUploadMaxMegapixels,
 	}
 
 	if enabled, err := s.settingRepo.Get("public_gallery_enabled"); err == nil {
@@ -109,6 +120,30 @@ func (s *SystemService) GetSettings() (*SystemSettings, error) {
 	if version, err := s.settingRepo.Get("imagemagick_version"); err == nil {
 		settings.ImageMagickVersion = normalizeImageMagickVersion(version.Value)
 	}
+	if days, err := s.settingRepo.Get("trash_retention_days"); err == nil {
+		settings.TrashRetentionDays = normalizeTrashRetentionDays(days.Value)
+	}
+	if strip, err := s.settingRepo.Get("public_strip_sensitive_exif"); err == nil {
+		settings.PublicStripEXIF = strip.Value == "true"
+	}
+	if animated, err := s.settingRepo.Get("animated_thumbnails_enabled"); err == nil {
+		settings.AnimatedThumbnails = animated.Value == "true"
+	}
+	if enabled, err := s.settingRepo.Get("ffmpeg_enabled"); err == nil {
+		settings.FFmpegEnabled = enabled.Value == "true"
+	}
+	if limit, err := s.settingRepo.Get("upload_max_request_mb"); err == nil {
+		settings.UploadMaxRequestMB = normalizeUploadLimitMB(limit.Value, DefaultUploadMaxRequestMB)
+	}
+	if limit, err := s.settingRepo.Get("upload_max_file_mb"); err == nil {
+		settings.UploadMaxFileMB = normalizeUploadLimitMB(limit.Value, DefaultUploadMaxFileMB)
+	}
+	if limits, err := s.settingRepo.Get("upload_format_limits_mb"); err == nil {
+		settings.UploadFormatLimitsMB = parseUploadFormatLimits(limits.Value)
+	}
+	if limit, err := s.settingRepo.Get("upload_max_megapixels"); err == nil {
+		settings.UploadMaxMegapixels = normalizeUploadMegapixels(limit.Value)
+	}
 
 	return settings, nil
 }
@@ -127,10 +162,39 @@ func (s *SystemService) UpdateSettings(settings *SystemSettings) error {
 	if err := s.settingRepo.Set("imagemagick_version", settings.ImageMagickVersion); err != nil {
 		return err
 	}
+	settings.TrashRetentionDays = normalizeTrashRetentionDays(strconv.Itoa(settings.TrashRetentionDays))
+	if err := s.settingRepo.Set("trash_retention_days", strconv.Itoa(settings.TrashRetentionDays)); err != nil {
+		return err
+	}
+	if err := s.settingRepo.Set("public_strip_sensitive_exif", boolToString(settings.PublicStripEXIF)); err != nil {
+		return err
+	}
+	if err := s.settingRepo.Set("animated_thumbnails_enabled", boolToString(settings.AnimatedThumbnails)); err != nil {
+		return err
+	}
+	if err := s.settingRepo.Set("ffmpeg_enabled", boolToString(settings.FFmpegEnabled)); err != nil {
+		return err
+	}
+	settings.UploadMaxRequestMB = normalizeUploadLimitMB(strconv.Itoa(settings.UploadMaxRequestMB), DefaultUploadMaxRequestMB)
+	if err := s.settingRepo.Set("upload_max_request_mb", strconv.Itoa(settings.UploadMaxRequestMB)); err != nil {
+		return err
+	}
+	settings.UploadMaxFileMB = normalizeUploadLimitMB(strconv.Itoa(settings.UploadMaxFileMB), DefaultUploadMaxFileMB)
+	if err := s.settingRepo.Set("upload_max_file_mb", strconv.Itoa(settings.UploadMaxFileMB)); err != nil {
+		return err
+	}
+	settings.UploadFormatLimitsMB = normalizeUploadFormatLimits(settings.UploadFormatLimitsMB)
+	if err := s.settingRepo.Set("upload_format_limits_mb", encodeUploadFormatLimits(settings.UploadFormatLimitsMB)); err != nil {
+		return err
+	}
+	settings.UploadMaxMegapixels = normalizeUploadMegapixels(strconv.Itoa(settings.UploadMaxMegapixels))
+	if err := s.settingRepo.Set("upload_max_megapixels", strconv.Itoa(settings.UploadMaxMegapixels)); err != nil {
+		return err
+	}
 	return nil
 }
 
-func (s *SystemService) TestImageMagickCommand(versionOverride string) (*ImageMagickTestResult, error) {
+func (s *SystemService) TestImageMagickCommand(ctx context.Context, versionOverride string) (*ImageMagickTestResult, error) {
 	version := normalizeImageMagickVersion(versionOverride)
 	if strings.TrimSpace(versionOverride) == "" {
 		settings, err := s.GetSettings()
@@ -144,21 +208,49 @@ func (s *SystemService) TestImageMagickCommand(versionOverride string) (*ImageMa
 		return nil, err
 	}
 
-	message, err := testImageMagickCommand(version)
+	message, err := testImageMagickCommand(ctx, version)
 	if err != nil {
 		return &ImageMagickTestResult{
 			Available: false,
 			Command:   command,
 			Message:   err.Error(),
+			Pool:      getConverterPool().Status(),
+			Formats:   []ImageMagickFormat{},
 		}, nil
 	}
+
+	formats, err := listImageMagickFormats(ctx, version)
+	if err != nil {
+		log.Printf("Failed to list ImageMagick formats: %v", err)
+		formats = []ImageMagickFormat{}
+	}
 	return &ImageMagickTestResult{
 		Available: true,
 		Command:   command,
 		Message:   message,
+		Pool:      getConverterPool().Status(),
+		Formats:   formats,
 	}, nil
 }
 
+func (s *SystemService) TestFFmpegCommand(ctx context.Context) *FFmpegTestResult {
+	message, err := testFFmpegCommand(ctx)
+	if err != nil {
+		return &FFmpegTestResult{
+			Available: false,
+			Command:   ffmpegCommand,
+			Message:   err.Error(),
+			Pool:      getConverterPool().Status(),
+		}
+	}
+	return &FFmpegTestResult{
+		Available: true,
+		Command:   ffmpegCommand,
+		Message:   message,
+		Pool:      getConverterPool().Status(),
+	}
+}
+
 func (s *SystemService) GetStatistics() (*SystemStatistics, error) {
 	workCount, err := s.workRepo.Count()
 	if err != nil {
@@ -240,15 +332,56 @@ func (s *SystemService) GetStatistics() (*SystemStatistics, error) {
 		}
 	}
 
+	pairRows, err := s.tagRepo.FindTopTagPairs(statisticsTagListLimit)
+	if err != nil {
+		return nil, err
+	}
+	topTagPairs := make([]TagPairStat, 0, len(pairRows))
+	for _, row := range pairRows {
+		topTagPairs = append(topTagPairs, TagPairStat{
+			TagA:  TagStatItem{ID: row.TagAID, Name: row.TagAName, Category: row.TagACategory, WorkCount: row.TagAWorkCount},
+			TagB:  TagStatItem{ID: row.TagBID, Name: row.TagBName, Category: row.TagBCategory, WorkCount: row.TagBWorkCount},
+			Count: row.Count,
+		})
+	}
+
+	usedOnceRows, usedOnceCount, err := s.tagRepo.FindTagsUsedOnce(statisticsTagListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	unusedRows, unusedCount, err := s.tagRepo.FindUnusedTags(statisticsTagListLimit)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SystemStatistics{
 		WorkCount:            workCount,
 		ImageCount:           imageCount,
 		TagCount:             tagCount,
 		CollectionCount:      collectionCount,
 		DuplicateImageGroups: duplicateGroups,
+		TopTagPairs:          topTagPairs,
+		TagsUsedOnce:         tagUsageRowsToStats(usedOnceRows),
+		TagsUsedOnceCount:    usedOnceCount,
+		UnusedTags:           tagUsageRowsToStats(unusedRows),
+		UnusedTagCount:       unusedCount,
 	}, nil
 }
 
+func tagUsageRowsToStats(rows []repository.TagUsageRow) []TagStatItem {
+	items := make([]TagStatItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, TagStatItem{
+			ID:        row.ID,
+			Name:      row.Name,
+			Category:  row.Category,
+			WorkCount: row.WorkCount,
+		})
+	}
+	return items
+}
+
 func boolToString(b bool) string {
 	if b {
 		return "true"
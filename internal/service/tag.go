@@ -4,35 +4,49 @@ import (
 	"errors"
 	"illust-nest/internal/model"
 	"illust-nest/internal/repository"
+	"math"
+	"reflect"
+	"strings"
+)
+
+const (
+	RelatedTagMethodCooccurrence = "cooccurrence"
+	RelatedTagMethodPMI          = "pmi"
 )
 
 type TagService struct {
-	tagRepo *repository.TagRepository
+	tagRepo      *repository.TagRepository
+	workRepo     *repository.WorkRepository
+	auditService *AuditService
 }
 
-func NewTagService(tagRepo *repository.TagRepository) *TagService {
-	return &TagService{tagRepo: tagRepo}
+func NewTagService(tagRepo *repository.TagRepository, workRepo *repository.WorkRepository, auditService *AuditService) *TagService {
+	return &TagService{tagRepo: tagRepo, workRepo: workRepo, auditService: auditService}
 }
 
-func (s *TagService) GetTags(keyword string, includeCount bool) ([]TagInfo, error) {
-	tags, err := s.tagRepo.FindAll(keyword, includeCount)
+func (s *TagService) GetTags(keyword, category string, includeCount bool) ([]TagInfo, error) {
+	tags, err := s.tagRepo.FindAll(keyword, category, includeCount)
 	if err != nil {
 		return nil, err
 	}
 
-	var tagInfos []TagInfo
-	for _, tag := range tags {
-		tagInfo := TagInfo{
-			ID:        tag.ID,
-			Name:      tag.Name,
-			IsSystem:  tag.IsSystem,
-			WorkCount: tag.WorkCount,
-			CreatedAt: tag.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	return s.tagsToInfos(tags)
+}
+
+func (s *TagService) GetTagInfo(id uint) (*TagInfo, error) {
+	tag, err := s.tagRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTagNotFound) {
+			return nil, errors.New("tag not found")
 		}
-		tagInfos = append(tagInfos, tagInfo)
+		return nil, err
 	}
 
-	return tagInfos, nil
+	infos, err := s.tagsToInfos([]model.Tag{*tag})
+	if err != nil {
+		return nil, err
+	}
+	return &infos[0], nil
 }
 
 func (s *TagService) GetTagByID(id uint) (*model.Tag, error) {
@@ -40,6 +54,11 @@ func (s *TagService) GetTagByID(id uint) (*model.Tag, error) {
 }
 
 func (s *TagService) CreateTag(req *CreateTagRequest) (*model.Tag, error) {
+	category := req.Category
+	if category == "" {
+		category, req.Name = splitTagCategoryPrefix(req.Name)
+	}
+
 	existing, err := s.tagRepo.FindByName(req.Name)
 	if err == nil && existing != nil {
 		return nil, &ValidationError{Message: "Tag already exists", Code: 1003}
@@ -47,10 +66,18 @@ func (s *TagService) CreateTag(req *CreateTagRequest) (*model.Tag, error) {
 	if err != nil && !errors.Is(err, repository.ErrTagNotFound) {
 		return nil, err
 	}
+	if err := s.ensureNotAlias(req.Name, 0); err != nil {
+		return nil, err
+	}
 
 	tag := &model.Tag{
-		Name:     req.Name,
-		IsSystem: false,
+		Name:            req.Name,
+		IsSystem:        false,
+		Category:        category,
+		Transliteration: normalizeTransliteration(req.Transliteration),
+	}
+	if tag.Transliteration == "" {
+		tag.Transliteration, _ = generateTransliteration(req.Name)
 	}
 
 	if err := s.tagRepo.Create(tag); err != nil {
@@ -80,8 +107,21 @@ func (s *TagService) UpdateTag(id uint, req *UpdateTagRequest) (*model.Tag, erro
 	if err != nil && !errors.Is(err, repository.ErrTagNotFound) {
 		return nil, err
 	}
+	if err := s.ensureNotAlias(req.Name, id); err != nil {
+		return nil, err
+	}
 
+	if req.Transliteration != "" {
+		tag.Transliteration = normalizeTransliteration(req.Transliteration)
+	} else if req.Name != tag.Name {
+		if transliteration, ok := generateTransliteration(req.Name); ok {
+			tag.Transliteration = transliteration
+		}
+	}
 	tag.Name = req.Name
+	if req.Category != "" {
+		tag.Category = req.Category
+	}
 
 	if err := s.tagRepo.Update(tag); err != nil {
 		return nil, err
@@ -107,14 +147,20 @@ func (s *TagService) BatchCreateTags(req *BatchCreateTagsRequest) (*BatchCreateT
 	var createdTags []*model.Tag
 	var skipped []string
 
-	for _, name := range req.Names {
+	for _, rawName := range req.Names {
+		category, name := splitTagCategoryPrefix(rawName)
 		existing, err := s.tagRepo.FindByName(name)
 		if err == nil && existing != nil {
 			skipped = append(skipped, name)
 			continue
 		}
+		if _, err := s.tagRepo.FindAliasByName(name); err == nil {
+			skipped = append(skipped, name)
+			continue
+		}
 
-		tag, err := s.tagRepo.FirstOrCreate(&model.Tag{Name: name, IsSystem: false})
+		transliteration, _ := generateTransliteration(name)
+		tag, err := s.tagRepo.FirstOrCreate(&model.Tag{Name: name, IsSystem: false, Category: category, Transliteration: transliteration})
 		if err != nil {
 			continue
 		}
@@ -138,3 +184,381 @@ func (s *TagService) ValidateTagIDs(tagIDs []uint) ([]model.Tag, error) {
 func (s *TagService) GetOrCreateByName(name string) (*model.Tag, error) {
 	return s.tagRepo.GetOrCreateByName(name)
 }
+
+func (s *TagService) UpdateParent(id uint, req *UpdateTagParentRequest) (*TagInfo, error) {
+	if _, err := s.tagRepo.FindByID(id); err != nil {
+		return nil, errors.New("tag not found")
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == id {
+			return nil, &ValidationError{Message: "Tag cannot be its own parent", Code: 1001}
+		}
+		if _, err := s.tagRepo.FindByID(*req.ParentID); err != nil {
+			return nil, errors.New("parent tag not found")
+		}
+		descendants, err := s.tagRepo.FindDescendantTagIDs([]uint{id})
+		if err != nil {
+			return nil, err
+		}
+		if containsID(descendants, *req.ParentID) {
+			return nil, &ValidationError{Message: "Parent tag cannot be a descendant of the tag", Code: 1001}
+		}
+	}
+
+	if err := s.tagRepo.UpdateParent(id, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	return s.GetTagInfo(id)
+}
+
+func (s *TagService) AddAlias(id uint, req *CreateTagAliasRequest) (*TagInfo, error) {
+	if _, err := s.tagRepo.FindByID(id); err != nil {
+		return nil, errors.New("tag not found")
+	}
+
+	if existing, err := s.tagRepo.FindByName(req.Name); err == nil && existing != nil {
+		return nil, &ValidationError{Message: "Tag name already exists", Code: 1003}
+	}
+	if err := s.ensureNotAlias(req.Name, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.CreateAlias(&model.TagAlias{Name: req.Name, TagID: id}); err != nil {
+		return nil, err
+	}
+
+	return s.GetTagInfo(id)
+}
+
+func (s *TagService) DeleteAlias(id, aliasID uint) error {
+	deleted, err := s.tagRepo.DeleteAlias(id, aliasID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("alias not found")
+	}
+	return nil
+}
+
+func (s *TagService) AddImplication(id uint, req *CreateTagImplicationRequest) (*TagInfo, error) {
+	if _, err := s.tagRepo.FindByID(id); err != nil {
+		return nil, errors.New("tag not found")
+	}
+	if _, err := s.tagRepo.FindByID(req.ImpliedTagID); err != nil {
+		return nil, errors.New("implied tag not found")
+	}
+	if req.ImpliedTagID == id {
+		return nil, &ValidationError{Message: "Tag cannot imply itself", Code: 1001}
+	}
+
+	existing, err := s.tagRepo.FindImplicationsByTagIDs([]uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if containsID(existing[id], req.ImpliedTagID) {
+		return nil, &ValidationError{Message: "Implication already exists", Code: 1003}
+	}
+
+	implied, err := s.tagRepo.FindImpliedTagIDs([]uint{req.ImpliedTagID})
+	if err != nil {
+		return nil, err
+	}
+	if containsID(implied, id) {
+		return nil, &ValidationError{Message: "Implication would create a cycle", Code: 1001}
+	}
+
+	if err := s.tagRepo.CreateImplication(id, req.ImpliedTagID); err != nil {
+		return nil, err
+	}
+
+	return s.GetTagInfo(id)
+}
+
+func (s *TagService) DeleteImplication(id, impliedTagID uint) error {
+	deleted, err := s.tagRepo.DeleteImplication(id, impliedTagID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("implication not found")
+	}
+	return nil
+}
+
+func (s *TagService) MergeTag(actor *Actor, sourceID uint, req *MergeTagsRequest) (*TagInfo, error) {
+	source, err := s.tagRepo.FindByID(sourceID)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+	if _, err := s.tagRepo.FindByID(req.TargetID); err != nil {
+		return nil, errors.New("target tag not found")
+	}
+	if source.ID == req.TargetID {
+		return nil, &ValidationError{Message: "Cannot merge a tag into itself", Code: 1001}
+	}
+	if source.IsSystem {
+		return nil, errors.New("cannot merge system tag")
+	}
+
+	aliasName := ""
+	if req.CreateAlias {
+		aliasName = source.Name
+	}
+
+	workIDs, err := s.tagRepo.FindTaggedWorkIDs([]uint{source.ID, req.TargetID})
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.workAuditSnapshots(workIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tagRepo.Merge(source.ID, req.TargetID, aliasName); err != nil {
+		if errors.Is(err, repository.ErrTagParentCycle) {
+			return nil, &ValidationError{Message: "Merge would create a tag parent cycle", Code: 1001}
+		}
+		return nil, err
+	}
+
+	after, err := s.workAuditSnapshots(workIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range workIDs {
+		workBefore, ok := before[id]
+		if !ok || reflect.DeepEqual(workBefore, after[id]) {
+			continue
+		}
+		s.auditService.Record(actor, "tag.merge", AuditEntityWork, id, id, workBefore, after[id])
+	}
+
+	return s.GetTagInfo(req.TargetID)
+}
+
+func (s *TagService) workAuditSnapshots(ids []uint) (map[uint]*workAuditSnapshot, error) {
+	snapshots := make(map[uint]*workAuditSnapshot, len(ids))
+	if s.workRepo == nil {
+		return snapshots, nil
+	}
+	works, err := s.workRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range works {
+		snapshots[works[i].ID] = newWorkAuditSnapshot(&works[i])
+	}
+	return snapshots, nil
+}
+
+func (s *TagService) GetRelatedTags(tagIDs []uint, method string, minCount, limit int) (*RelatedTagsResult, error) {
+	if method != RelatedTagMethodPMI {
+		method = RelatedTagMethodCooccurrence
+	}
+	if minCount < 1 {
+		minCount = 1
+	}
+
+	result := &RelatedTagsResult{
+		Method: method,
+		Items:  []RelatedTagInfo{},
+	}
+	if len(tagIDs) == 0 {
+		return result, nil
+	}
+
+	totalWorks, err := s.tagRepo.CountTaggedWorks(nil)
+	if err != nil {
+		return nil, err
+	}
+	contextWorks, err := s.tagRepo.CountTaggedWorks(tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	result.TotalWorkCount = totalWorks
+	result.ContextWorkCount = contextWorks
+	if contextWorks == 0 {
+		return result, nil
+	}
+
+	rows, err := s.tagRepo.FindRelatedTags(tagIDs, method == RelatedTagMethodPMI, minCount, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		item := RelatedTagInfo{
+			ID:        row.ID,
+			Name:      row.Name,
+			Category:  row.Category,
+			CoCount:   row.CoCount,
+			WorkCount: row.WorkCount,
+		}
+		if method == RelatedTagMethodPMI && row.WorkCount > 0 {
+			item.Score = math.Log2(float64(row.CoCount) * float64(totalWorks) / (float64(contextWorks) * float64(row.WorkCount)))
+		} else {
+			item.Score = float64(row.CoCount) / float64(contextWorks)
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
+
+func (s *TagService) Autocomplete(query string, limit int) ([]TagSuggestion, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []TagSuggestion{}, nil
+	}
+
+	terms := []string{query}
+	if romaji, ok := kanaToRomaji(query); ok && romaji != strings.ToLower(query) {
+		terms = append(terms, romaji)
+	}
+
+	rows, err := s.tagRepo.Suggest(terms, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]TagSuggestion, 0, len(rows))
+	for _, row := range rows {
+		suggestions = append(suggestions, TagSuggestion{
+			ID:        row.ID,
+			Name:      row.Name,
+			Category:  row.Category,
+			WorkCount: row.WorkCount,
+			Matched:   row.Matched,
+			MatchType: row.MatchType,
+		})
+	}
+	return suggestions, nil
+}
+
+func (s *TagService) BackfillTransliterations() error {
+	tags, err := s.tagRepo.FindWithoutTransliteration()
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		transliteration, ok := generateTransliteration(tag.Name)
+		if !ok {
+			continue
+		}
+		if err := s.tagRepo.UpdateTransliteration(tag.ID, transliteration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeTransliteration(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
+
+func (s *TagService) ensureNotAlias(name string, tagID uint) error {
+	alias, err := s.tagRepo.FindAliasByName(name)
+	if err != nil {
+		if errors.Is(err, repository.ErrTagNotFound) {
+			return nil
+		}
+		return err
+	}
+	if alias.TagID != tagID {
+		return &ValidationError{Message: "Tag name is already used as an alias", Code: 1003}
+	}
+	return nil
+}
+
+func (s *TagService) tagsToInfos(tags []model.Tag) ([]TagInfo, error) {
+	ids := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+
+	aliases, err := s.tagRepo.FindAliasesByTagIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	implications, err := s.tagRepo.FindImplicationsByTagIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagInfos []TagInfo
+	for _, tag := range tags {
+		tagInfo := TagInfo{
+			ID:              tag.ID,
+			Name:            tag.Name,
+			IsSystem:        tag.IsSystem,
+			Category:        tag.Category,
+			Transliteration: tag.Transliteration,
+			ParentID:        tag.ParentID,
+			ImpliedTagIDs:   implications[tag.ID],
+			WorkCount:       tag.WorkCount,
+			CreatedAt:       tag.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+		for _, alias := range aliases[tag.ID] {
+			tagInfo.Aliases = append(tagInfo.Aliases, TagAliasInfo{ID: alias.ID, Name: alias.Name})
+		}
+		tagInfos = append(tagInfos, tagInfo)
+	}
+
+	return tagInfos, nil
+}
+
+func (s *TagService) GetCategories() ([]model.TagCategory, error) {
+	return s.tagRepo.FindCategories()
+}
+
+func (s *TagService) UpdateCategory(name string, req *UpdateTagCategoryRequest) (*model.TagCategory, error) {
+	category, err := s.tagRepo.FindCategory(name)
+	if err != nil {
+		return nil, errors.New("tag category not found")
+	}
+
+	if req.Color != "" {
+		category.Color = req.Color
+	}
+	if req.SortPriority != nil {
+		category.SortPriority = *req.SortPriority
+	}
+
+	if err := s.tagRepo.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func isTagCategory(name string) bool {
+	switch name {
+	case model.TagCategoryArtist, model.TagCategoryCharacter, model.TagCategorySeries, model.TagCategoryMeta, model.TagCategoryGeneral:
+		return true
+	}
+	return false
+}
+
+func splitTagCategoryPrefix(name string) (string, string) {
+	prefix, rest, found := strings.Cut(name, ":")
+	if !found {
+		return model.TagCategoryGeneral, name
+	}
+	category := strings.ToLower(strings.TrimSpace(prefix))
+	rest = strings.TrimSpace(rest)
+	if !isTagCategory(category) || rest == "" {
+		return model.TagCategoryGeneral, name
+	}
+	return category, rest
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"illust-nest/internal/model"
+	"illust-nest/internal/repository"
+	"log"
+	"strconv"
+	"time"
+)
+
+const DefaultTrashRetentionDays = 30
+
+type TrashService struct {
+	workRepo     *repository.WorkRepository
+	settingRepo  *repository.SettingRepository
+	imageService *ImageService
+}
+
+func NewTrashService(workRepo *repository.WorkRepository, settingRepo *repository.SettingRepository, imageService *ImageService) *TrashService {
+	return &TrashService{
+		workRepo:     workRepo,
+		settingRepo:  settingRepo,
+		imageService: imageService,
+	}
+}
+
+func (s *TrashService) ListWorks(page, pageSize int) (*WorkPagedResult, error) {
+	works, total, err := s.workRepo.FindTrashed(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	workInfos := make([]*WorkInfo, 0, len(works))
+	for i := range works {
+		info := workToInfo(&works[i], false)
+		if works[i].DeletedAt.Valid {
+			info.DeletedAt = works[i].DeletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		}
+		workInfos = append(workInfos, info)
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	return &WorkPagedResult{
+		Items:      workInfos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *TrashService) RestoreWorks(ids []uint) (int64, error) {
+	return s.workRepo.Restore(ids)
+}
+
+func (s *TrashService) ListImages() ([]TrashedImageInfo, error) {
+	images, err := s.workRepo.FindTrashedImages()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TrashedImageInfo, 0, len(images))
+	for _, img := range images {
+		item := TrashedImageInfo{
+			ImageInfo: ImageInfo{
+				ID:             img.ID,
+				ThumbnailPath:  img.ThumbnailPath,
+				OriginalPath:   img.StoragePath,
+				TranscodedPath: img.TranscodedPath,
+				ImageHash:      img.ImageHash,
+				FileSize:       img.FileSize,
+				Width:          img.Width,
+				Height:         img.Height,
+				SortOrder:      img.SortOrder,
+			},
+			WorkID: img.WorkID,
+		}
+		if img.DeletedAt.Valid {
+			item.DeletedAt = img.DeletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func (s *TrashService) RestoreImages(ids []uint) (int64, error) {
+	return s.workRepo.RestoreImages(ids)
+}
+
+func (s *TrashService) Empty(workIDs []uint, imageIDs []uint) (*EmptyTrashResponse, error) {
+	if len(workIDs) == 0 && len(imageIDs) == 0 {
+		return s.purge(nil)
+	}
+
+	result := &EmptyTrashResponse{}
+	if len(workIDs) > 0 {
+		trashed, err := s.workRepo.FindTrashedWorkIDs(nil)
+		if err != nil {
+			return nil, err
+		}
+		requested := make(map[uint]struct{}, len(workIDs))
+		for _, id := range workIDs {
+			requested[id] = struct{}{}
+		}
+		ids := make([]uint, 0, len(workIDs))
+		for _, id := range trashed {
+			if _, ok := requested[id]; ok {
+				ids = append(ids, id)
+			}
+		}
+		purged, err := s.purgeWorks(ids)
+		if err != nil {
+			return nil, err
+		}
+		result.PurgedWorks = purged
+	}
+	if len(imageIDs) > 0 {
+		images, err := s.workRepo.FindTrashedImagesByIDs(imageIDs)
+		if err != nil {
+			return nil, err
+		}
+		purged, err := s.purgeImages(images)
+		if err != nil {
+			return nil, err
+		}
+		result.PurgedImages = purged
+	}
+	return result, nil
+}
+
+func (s *TrashService) PurgeExpired() (*EmptyTrashResponse, error) {
+	cutoff := time.Now().AddDate(0, 0, -s.retentionDays())
+	return s.purge(&cutoff)
+}
+
+func (s *TrashService) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if result, err := s.PurgeExpired(); err != nil {
+			log.Printf("Trash sweeper failed: %v", err)
+		} else if result.PurgedWorks > 0 || result.PurgedImages > 0 {
+			log.Printf("Trash sweeper purged %d works and %d images", result.PurgedWorks, result.PurgedImages)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *TrashService) purge(deletedBefore *time.Time) (*EmptyTrashResponse, error) {
+	workIDs, err := s.workRepo.FindTrashedWorkIDs(deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	purgedWorks, err := s.purgeWorks(workIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := s.workRepo.FindTrashedImagesBefore(deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	purgedImages, err := s.purgeImages(images)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmptyTrashResponse{
+		PurgedWorks:  purgedWorks,
+		PurgedImages: purgedImages,
+	}, nil
+}
+
+func (s *TrashService) purgeImages(images []model.WorkImage) (int64, error) {
+	if len(images) == 0 {
+		return 0, nil
+	}
+	if err := s.deleteStorage(images); err != nil {
+		return 0, err
+	}
+	imageIDs := make([]uint, 0, len(images))
+	for _, img := range images {
+		imageIDs = append(imageIDs, img.ID)
+	}
+	if err := s.workRepo.PurgeImages(imageIDs); err != nil {
+		return 0, err
+	}
+	return int64(len(imageIDs)), nil
+}
+
+func (s *TrashService) purgeWorks(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	images, err := s.workRepo.FindAllImagesByWorkIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.deleteStorage(images); err != nil {
+		return 0, err
+	}
+	return s.workRepo.PurgeWorks(ids)
+}
+
+func (s *TrashService) deleteStorage(images []model.WorkImage) error {
+	for _, img := range images {
+		if err := s.imageService.DeleteImage(img.StoragePath, img.ThumbnailPath, img.TranscodedPath, img.AnimatedThumbnailPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TrashService) retentionDays() int {
+	if s.settingRepo == nil {
+		return DefaultTrashRetentionDays
+	}
+	setting, err := s.settingRepo.Get("trash_retention_days")
+	if err != nil {
+		return DefaultTrashRetentionDays
+	}
+	return normalizeTrashRetentionDays(setting.Value)
+}
+
+func normalizeTrashRetentionDays(value string) int {
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		return DefaultTrashRetentionDays
+	}
+	return days
+}
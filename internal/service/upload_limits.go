@@ -0,0 +1,178 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultUploadMaxRequestMB  = 1024
+	DefaultUploadMaxFileMB     = 50
+	DefaultUploadMaxMegapixels = 100
+	maxUploadLimitMB           = 64 * 1024
+	maxUploadMegapixels        = 10000
+	bytesPerMB                 = 1024 * 1024
+	pixelsPerMegapixel         = 1000 * 1000
+)
+
+type UploadLimits struct {
+	MaxRequestBytes int64
+	MaxFileBytes    int64
+	FormatMaxBytes  map[string]int64
+	MaxPixels       int64
+}
+
+func (s *ImageService) GetUploadLimits() *UploadLimits {
+	limits := &UploadLimits{
+		MaxRequestBytes: DefaultUploadMaxRequestMB * bytesPerMB,
+		MaxFileBytes:    DefaultUploadMaxFileMB * bytesPerMB,
+		FormatMaxBytes:  map[string]int64{},
+		MaxPixels:       DefaultUploadMaxMegapixels * pixelsPerMegapixel,
+	}
+	if s.settingRepo == nil {
+		return limits
+	}
+
+	if value, err := s.settingRepo.Get("upload_max_request_mb"); err == nil {
+		limits.MaxRequestBytes = int64(normalizeUploadLimitMB(value.Value, DefaultUploadMaxRequestMB)) * bytesPerMB
+	}
+	if value, err := s.settingRepo.Get("upload_max_file_mb"); err == nil {
+		limits.MaxFileBytes = int64(normalizeUploadLimitMB(value.Value, DefaultUploadMaxFileMB)) * bytesPerMB
+	}
+	if value, err := s.settingRepo.Get("upload_format_limits_mb"); err == nil {
+		for format, mb := range parseUploadFormatLimits(value.Value) {
+			limits.FormatMaxBytes[format] = int64(mb) * bytesPerMB
+		}
+	}
+	if value, err := s.settingRepo.Get("upload_max_megapixels"); err == nil {
+		limits.MaxPixels = int64(normalizeUploadMegapixels(value.Value)) * pixelsPerMegapixel
+	}
+	return limits
+}
+
+func (l *UploadLimits) RequestTooLarge(size int64) *UploadLimitError {
+	if size <= l.MaxRequestBytes {
+		return nil
+	}
+	err := l.RequestLimitError()
+	err.Actual = size
+	return err
+}
+
+func (l *UploadLimits) RequestLimitError() *UploadLimitError {
+	return &UploadLimitError{
+		Code:    ErrCodeUploadRequestTooLarge,
+		Message: fmt.Sprintf("upload request exceeds the %s limit", formatMegabytes(l.MaxRequestBytes)),
+		Limit:   l.MaxRequestBytes,
+	}
+}
+
+func (l *UploadLimits) FileLimit(filename string) (string, int64) {
+	format := uploadFormatKey(filename)
+	if limit, ok := l.FormatMaxBytes[format]; ok {
+		return format, limit
+	}
+	return format, l.MaxFileBytes
+}
+
+func (l *UploadLimits) CheckFile(file *multipart.FileHeader) error {
+	if _, limit := l.FileLimit(file.Filename); file.Size <= limit {
+		return nil
+	}
+	return l.FileLimitError(file.Filename, file.Size)
+}
+
+func (l *UploadLimits) FileLimitError(filename string, actual int64) *UploadLimitError {
+	format, limit := l.FileLimit(filename)
+	return &UploadLimitError{
+		Code:     ErrCodeUploadFileTooLarge,
+		Message:  fmt.Sprintf("%s exceeds the %s limit for %s files", filename, formatMegabytes(limit), strings.ToUpper(format)),
+		Filename: filename,
+		Format:   format,
+		Limit:    limit,
+		Actual:   actual,
+	}
+}
+
+func (l *UploadLimits) CheckPixels(filename string, width, height int) error {
+	if l == nil || l.MaxPixels <= 0 {
+		return nil
+	}
+	pixels := int64(width) * int64(height)
+	if pixels <= l.MaxPixels {
+		return nil
+	}
+	return &UploadLimitError{
+		Code:     ErrCodeUploadPixelsExceeded,
+		Message:  fmt.Sprintf("%s is %dx%d pixels, which exceeds the %d megapixel limit", filename, width, height, l.MaxPixels/pixelsPerMegapixel),
+		Filename: filename,
+		Format:   uploadFormatKey(filename),
+		Limit:    l.MaxPixels,
+		Actual:   pixels,
+	}
+}
+
+func uploadFormatKey(filename string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+}
+
+func formatMegabytes(bytes int64) string {
+	return strconv.FormatInt(bytes/bytesPerMB, 10) + "MB"
+}
+
+func normalizeUploadLimitMB(value string, fallback int) int {
+	mb, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || mb <= 0 {
+		return fallback
+	}
+	if mb > maxUploadLimitMB {
+		return maxUploadLimitMB
+	}
+	return mb
+}
+
+func normalizeUploadMegapixels(value string) int {
+	megapixels, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || megapixels <= 0 {
+		return DefaultUploadMaxMegapixels
+	}
+	if megapixels > maxUploadMegapixels {
+		return maxUploadMegapixels
+	}
+	return megapixels
+}
+
+func parseUploadFormatLimits(raw string) map[string]int {
+	var parsed map[string]int
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return map[string]int{}
+	}
+	return normalizeUploadFormatLimits(parsed)
+}
+
+func normalizeUploadFormatLimits(limits map[string]int) map[string]int {
+	normalized := make(map[string]int, len(limits))
+	for format, mb := range limits {
+		key := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(format)), ".")
+		if key == "" || mb <= 0 {
+			continue
+		}
+		if mb > maxUploadLimitMB {
+			mb = maxUploadLimitMB
+		}
+		normalized[key] = mb
+	}
+	return normalized
+}
+
+func encodeUploadFormatLimits(limits map[string]int) string {
+	data, err := json.Marshal(normalizeUploadFormatLimits(limits))
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
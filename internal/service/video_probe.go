@@ -0,0 +1,242 @@
+package service
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+const (
+	ebmlIDHeader        = 0x1A45DFA3
+	ebmlIDSegment       = 0x18538067
+	ebmlIDInfo          = 0x1549A966
+	ebmlIDTimecodeScale = 0x2AD7B1
+	ebmlIDDuration      = 0x4489
+	ebmlIDTracks        = 0x1654AE6B
+	ebmlIDTrackEntry    = 0xAE
+	ebmlIDTrackVideo    = 0xE0
+	ebmlIDPixelWidth    = 0xB0
+	ebmlIDPixelHeight   = 0xBA
+	ebmlIDCluster       = 0x1F43B675
+	ebmlUnknownSize     = -1
+	defaultTimecodeNs   = 1000000
+)
+
+var errUnsupportedVideoContainer = errors.New("unsupported video container")
+
+type videoInfo struct {
+	Width      int
+	Height     int
+	DurationMs int
+}
+
+func videoContainerExt(data []byte) string {
+	switch {
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		brand := string(data[8:12])
+		if _, heif := heifBrands[brand]; heif {
+			return ""
+		}
+		if brand == "qt  " {
+			return ".mov"
+		}
+		return ".mp4"
+	case len(data) >= 8 && (string(data[4:8]) == "moov" || string(data[4:8]) == "wide" || string(data[4:8]) == "mdat"):
+		return ".mov"
+	case len(data) >= 4 && binary.BigEndian.Uint32(data[0:4]) == ebmlIDHeader:
+		return ".webm"
+	}
+	return ""
+}
+
+func probeVideo(data []byte) (videoInfo, error) {
+	switch videoContainerExt(data) {
+	case ".mp4", ".mov":
+		return probeMP4(data)
+	case ".webm":
+		return probeMatroska(data)
+	}
+	return videoInfo{}, errUnsupportedVideoContainer
+}
+
+func probeMP4(data []byte) (videoInfo, error) {
+	moov := findISOBMFFBoxBody(readISOBMFFBoxes(data), "moov")
+	if moov == nil {
+		return videoInfo{}, errors.New("mp4: moov box not found")
+	}
+
+	var info videoInfo
+	moovBoxes := readISOBMFFBoxes(moov)
+	if mvhd := findISOBMFFBoxBody(moovBoxes, "mvhd"); len(mvhd) >= 20 {
+		var timescale, duration uint64
+		if mvhd[0] == 1 && len(mvhd) >= 32 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+			duration = binary.BigEndian.Uint64(mvhd[24:32])
+		} else {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+			duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+		}
+		if timescale > 0 && duration != math.MaxUint32 && duration != math.MaxUint64 {
+			info.DurationMs = int(duration * 1000 / timescale)
+		}
+	}
+
+	for _, trak := range moovBoxes {
+		if trak.boxType != "trak" {
+			continue
+		}
+		trakBoxes := readISOBMFFBoxes(trak.body)
+		mdia := findISOBMFFBoxBody(trakBoxes, "mdia")
+		hdlr := findISOBMFFBoxBody(readISOBMFFBoxes(mdia), "hdlr")
+		if len(hdlr) < 12 || string(hdlr[8:12]) != "vide" {
+			continue
+		}
+		tkhd := findISOBMFFBoxBody(trakBoxes, "tkhd")
+		if len(tkhd) < 84 {
+			continue
+		}
+		matrix := tkhd[len(tkhd)-44 : len(tkhd)-8]
+		width := int(binary.BigEndian.Uint32(tkhd[len(tkhd)-8:len(tkhd)-4]) >> 16)
+		height := int(binary.BigEndian.Uint32(tkhd[len(tkhd)-4:]) >> 16)
+		if width == 0 || height == 0 {
+			continue
+		}
+		if a, b := int32(binary.BigEndian.Uint32(matrix[0:4])), int32(binary.BigEndian.Uint32(matrix[4:8])); a == 0 && b != 0 {
+			width, height = height, width
+		}
+		info.Width, info.Height = width, height
+		break
+	}
+
+	if info.Width == 0 || info.Height == 0 {
+		return info, errors.New("mp4: video track not found")
+	}
+	return info, nil
+}
+
+type ebmlElement struct {
+	ID      uint32
+	Payload []byte
+}
+
+func readEBMLVint(data []byte, keepMarker bool) (int64, int, bool) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, false
+	}
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || length > len(data) {
+		return 0, 0, false
+	}
+	value := uint64(data[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	allOnes := value == uint64(0xFF>>length)
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+		allOnes = allOnes && data[i] == 0xFF
+	}
+	if !keepMarker && allOnes {
+		return ebmlUnknownSize, length, true
+	}
+	return int64(value), length, true
+}
+
+func readEBMLElements(data []byte) []ebmlElement {
+	var elements []ebmlElement
+	pos := 0
+	for pos < len(data) {
+		id, idLength, ok := readEBMLVint(data[pos:], true)
+		if !ok {
+			return elements
+		}
+		size, sizeLength, ok := readEBMLVint(data[pos+idLength:], false)
+		if !ok {
+			return elements
+		}
+		start := pos + idLength + sizeLength
+		end := len(data)
+		if size != ebmlUnknownSize && size <= int64(len(data)-start) {
+			end = start + int(size)
+		}
+		elements = append(elements, ebmlElement{ID: uint32(id), Payload: data[start:end]})
+		if uint32(id) == ebmlIDCluster || end == len(data) {
+			return elements
+		}
+		pos = end
+	}
+	return elements
+}
+
+func findEBMLElement(elements []ebmlElement, id uint32) []byte {
+	for _, element := range elements {
+		if element.ID == id {
+			return element.Payload
+		}
+	}
+	return nil
+}
+
+func ebmlUint(payload []byte) uint64 {
+	var value uint64
+	for _, b := range payload {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func ebmlFloat(payload []byte) float64 {
+	switch len(payload) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(payload))
+	}
+	return 0
+}
+
+func probeMatroska(data []byte) (videoInfo, error) {
+	segment := findEBMLElement(readEBMLElements(data), ebmlIDSegment)
+	if segment == nil {
+		return videoInfo{}, errors.New("webm: segment not found")
+	}
+
+	var info videoInfo
+	segmentElements := readEBMLElements(segment)
+	if infoElement := findEBMLElement(segmentElements, ebmlIDInfo); infoElement != nil {
+		infoElements := readEBMLElements(infoElement)
+		timecodeScale := uint64(defaultTimecodeNs)
+		if scale := findEBMLElement(infoElements, ebmlIDTimecodeScale); len(scale) > 0 {
+			timecodeScale = ebmlUint(scale)
+		}
+		if duration := findEBMLElement(infoElements, ebmlIDDuration); len(duration) > 0 {
+			info.DurationMs = int(ebmlFloat(duration) * float64(timecodeScale) / 1e6)
+		}
+	}
+
+	if tracks := findEBMLElement(segmentElements, ebmlIDTracks); tracks != nil {
+		for _, entry := range readEBMLElements(tracks) {
+			if entry.ID != ebmlIDTrackEntry {
+				continue
+			}
+			video := findEBMLElement(readEBMLElements(entry.Payload), ebmlIDTrackVideo)
+			if video == nil {
+				continue
+			}
+			videoElements := readEBMLElements(video)
+			info.Width = int(ebmlUint(findEBMLElement(videoElements, ebmlIDPixelWidth)))
+			info.Height = int(ebmlUint(findEBMLElement(videoElements, ebmlIDPixelHeight)))
+			if info.Width > 0 && info.Height > 0 {
+				break
+			}
+		}
+	}
+
+	if info.Width == 0 || info.Height == 0 {
+		return info, errors.New("webm: video track not found")
+	}
+	return info, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func buildTestMP4(brand string, width, height uint32) []byte {
+	ftyp := testISOBMFFBox("ftyp", []byte(brand+"\x00\x00\x02\x00"+brand))
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:20], 2500)
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[40:44], 0x00010000)
+	binary.BigEndian.PutUint32(tkhd[56:60], 0x00010000)
+	binary.BigEndian.PutUint32(tkhd[76:80], width<<16)
+	binary.BigEndian.PutUint32(tkhd[80:84], height<<16)
+	hdlr := testISOBMFFBox("hdlr", []byte("\x00\x00\x00\x00\x00\x00\x00\x00vide\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"))
+	trak := testISOBMFFBox("trak", append(testISOBMFFBox("tkhd", tkhd), testISOBMFFBox("mdia", hdlr)...))
+	moov := testISOBMFFBox("moov", append(testISOBMFFBox("mvhd", mvhd), trak...))
+	return append(ftyp, moov...)
+}
+
+func TestVideoContainerExt(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "mp4", data: buildTestMP4("isom", 640, 360), want: ".mp4"},
+		{name: "m4v", data: buildTestMP4("M4V ", 640, 360), want: ".mp4"},
+		{name: "quicktime", data: buildTestMP4("qt  ", 640, 360), want: ".mov"},
+		{name: "quicktime without ftyp", data: testISOBMFFBox("moov", nil), want: ".mov"},
+		{name: "webm", data: []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81}, want: ".webm"},
+		{name: "heic", data: readTestdata(t, "heic_exif.heic"), want: ""},
+		{name: "html", data: []byte("<html><script>alert(1)</script></html>"), want: ""},
+		{name: "png", data: encodeTestPNG(t, 2, 2), want: ""},
+		{name: "empty", data: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := videoContainerExt(tt.data); got != tt.want {
+				t.Fatalf("videoContainerExt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeVideo(t *testing.T) {
+	info, err := probeVideo(buildTestMP4("isom", 640, 360))
+	if err != nil || info.Width != 640 || info.Height != 360 || info.DurationMs != 2500 {
+		t.Fatalf("probeVideo() = %+v, %v; want 640x360, 2500ms", info, err)
+	}
+
+	for name, data := range map[string][]byte{
+		"html":         []byte("<html><script>alert(1)</script></html>"),
+		"ftyp only":    testISOBMFFBox("ftyp", []byte("isom\x00\x00\x02\x00isom")),
+		"heic":         readTestdata(t, "heic_exif.heic"),
+		"no dimension": buildTestMP4("isom", 0, 0),
+	} {
+		if _, err := probeVideo(data); err == nil {
+			t.Fatalf("probeVideo(%s) succeeded, want error", name)
+		}
+	}
+}
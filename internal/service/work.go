@@ -6,32 +6,84 @@ import (
 	"errors"
 	"illust-nest/internal/model"
 	"illust-nest/internal/repository"
-	"os"
+	"io"
+	"log"
+	"math/rand"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/rwcarlsen/goexif/exif"
-	"github.com/rwcarlsen/goexif/tiff"
 	"gorm.io/gorm"
 )
 
+const (
+	AspectRatioLandscape = "landscape"
+	AspectRatioPortrait  = "portrait"
+	AspectRatioSquare    = "square"
+	AspectRatioPanorama  = "panorama"
+	AspectRatioTall      = "tall"
+)
+
+const (
+	defaultGeoPointLimit = 500
+	maxGeoPointLimit     = 2000
+)
+
 type WorkService struct {
-	workRepo     *repository.WorkRepository
-	tagRepo      *repository.TagRepository
-	imageService *ImageService
+	workRepo          *repository.WorkRepository
+	tagRepo           *repository.TagRepository
+	imageService      *ImageService
+	auditService      *AuditService
+	autoTagService    *AutoTagService
+	aiMetadataService *AIMetadataService
+	imageEXIFService  *ImageEXIFService
+	paletteService    *ImagePaletteService
 }
 
-func NewWorkService(workRepo *repository.WorkRepository, tagRepo *repository.TagRepository, imageService *ImageService) *WorkService {
+func NewWorkService(workRepo *repository.WorkRepository, tagRepo *repository.TagRepository, imageService *ImageService, auditService *AuditService, autoTagService *AutoTagService, aiMetadataService *AIMetadataService, imageEXIFService *ImageEXIFService, paletteService *ImagePaletteService) *WorkService {
 	return &WorkService{
-		workRepo:     workRepo,
-		tagRepo:      tagRepo,
-		imageService: imageService,
+		workRepo:          workRepo,
+		tagRepo:           tagRepo,
+		imageService:      imageService,
+		auditService:      auditService,
+		autoTagService:    autoTagService,
+		aiMetadataService: aiMetadataService,
+		imageEXIFService:  imageEXIFService,
+		paletteService:    paletteService,
 	}
 }
 
 func (s *WorkService) GetWorks(params *WorkListParams) (*WorkPagedResult, error) {
+	repoParams := workListRepoParams(params)
+
+	works, total, err := s.workRepo.FindAll(repoParams, params.Page, params.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var workInfos []*WorkInfo
+	for _, work := range works {
+		workInfo := s.workToInfo(&work, false)
+		workInfos = append(workInfos, workInfo)
+	}
+
+	totalPages := int(total) / params.PageSize
+	if int(total)%params.PageSize > 0 {
+		totalPages++
+	}
+
+	return &WorkPagedResult{
+		Items:      workInfos,
+		Total:      total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func workListRepoParams(params *WorkListParams) map[string]interface{} {
 	repoParams := make(map[string]interface{})
 	if params.Keyword != "" {
 		repoParams["keyword"] = params.Keyword
@@ -54,32 +106,303 @@ func (s *WorkService) GetWorks(params *WorkListParams) (*WorkPagedResult, error)
 	if params.SortOrder != "" {
 		repoParams["sort_order"] = params.SortOrder
 	}
+	applyWorkAttributeParams(repoParams, params)
+	return repoParams
+}
 
-	works, total, err := s.workRepo.FindAll(repoParams, params.Page, params.PageSize)
+func applyWorkAttributeParams(repoParams map[string]interface{}, params *WorkListParams) {
+	if params.CreatedFrom != nil {
+		repoParams["created_from"] = *params.CreatedFrom
+	}
+	if params.CreatedTo != nil {
+		repoParams["created_to"] = *params.CreatedTo
+	}
+	if params.WidthMin > 0 {
+		repoParams["width_min"] = params.WidthMin
+	}
+	if params.WidthMax > 0 {
+		repoParams["width_max"] = params.WidthMax
+	}
+	if params.HeightMin > 0 {
+		repoParams["height_min"] = params.HeightMin
+	}
+	if params.HeightMax > 0 {
+		repoParams["height_max"] = params.HeightMax
+	}
+	if aspectRatio := normalizeAspectRatio(params.AspectRatio); aspectRatio != "" {
+		repoParams["aspect_ratio"] = aspectRatio
+	}
+	if exts := normalizeFormatExtensions(params.Formats); len(exts) > 0 {
+		repoParams["formats"] = exts
+	}
+	if params.ImageCountMin > 0 {
+		repoParams["image_count_min"] = params.ImageCountMin
+	}
+	if params.ImageCountMax > 0 {
+		repoParams["image_count_max"] = params.ImageCountMax
+	}
+	if params.HasAIMetadata != nil {
+		repoParams["has_ai_metadata"] = *params.HasAIMetadata
+	}
+	if params.AICheckpointID > 0 {
+		repoParams["ai_checkpoint_id"] = params.AICheckpointID
+	}
+	if params.AISamplerID > 0 {
+		repoParams["ai_sampler_id"] = params.AISamplerID
+	}
+	if params.AILoraID > 0 {
+		repoParams["ai_lora_id"] = params.AILoraID
+	}
+	if params.AILoraWeightMin != nil {
+		repoParams["ai_lora_weight_min"] = *params.AILoraWeightMin
+	}
+	if params.AILoraWeightMax != nil {
+		repoParams["ai_lora_weight_max"] = *params.AILoraWeightMax
+	}
+	if seed := strings.TrimSpace(params.AISeed); seed != "" {
+		repoParams["ai_seed"] = seed
+	}
+	if params.Untagged {
+		repoParams["untagged"] = true
+	}
+	if params.NotInCollection {
+		repoParams["not_in_collection"] = true
+	}
+	if camera := strings.TrimSpace(params.EXIFCamera); camera != "" {
+		repoParams["exif_camera"] = camera
+	}
+	if lens := strings.TrimSpace(params.EXIFLens); lens != "" {
+		repoParams["exif_lens"] = lens
+	}
+	if params.ISOMin > 0 {
+		repoParams["iso_min"] = params.ISOMin
+	}
+	if params.ISOMax > 0 {
+		repoParams["iso_max"] = params.ISOMax
+	}
+	if params.FocalLengthMin != nil {
+		repoParams["focal_length_min"] = *params.FocalLengthMin
+	}
+	if params.FocalLengthMax != nil {
+		repoParams["focal_length_max"] = *params.FocalLengthMax
+	}
+	if params.TakenFrom != nil {
+		repoParams["taken_from"] = *params.TakenFrom
+	}
+	if params.TakenTo != nil {
+		repoParams["taken_to"] = *params.TakenTo
+	}
+	if params.HasGPS != nil {
+		repoParams["has_gps"] = *params.HasGPS
+	}
+	if lab, ok := hexToLab(params.Color); ok {
+		tolerance := DefaultColorTolerance
+		if params.ColorTolerance != nil && *params.ColorTolerance > 0 {
+			tolerance = *params.ColorTolerance
+		}
+		repoParams["color"] = repository.ColorMatch{L: lab.L, A: lab.A, B: lab.B, Tolerance: tolerance, MinWeight: colorMatchMinWeight}
+	}
+	if params.GPSBounds != nil && params.GPSBounds.Valid() {
+		repoParams["gps_bounds"] = params.GPSBounds.repositoryBounds()
+	}
+	if params.Animated != nil {
+		repoParams["animated"] = *params.Animated
+	}
+	switch params.MediaType {
+	case model.MediaTypeImage, model.MediaTypeVideo, model.MediaTypeUgoira:
+		repoParams["media_type"] = params.MediaType
+	}
+}
+
+func (b *GeoBounds) Valid() bool {
+	return b.MinLat >= -90 && b.MaxLat <= 90 && b.MinLat <= b.MaxLat &&
+		b.MinLng >= -180 && b.MinLng <= 180 && b.MaxLng >= -180 && b.MaxLng <= 180
+}
+
+func (b *GeoBounds) repositoryBounds() repository.GeoBounds {
+	return repository.GeoBounds{MinLat: b.MinLat, MaxLat: b.MaxLat, MinLng: b.MinLng, MaxLng: b.MaxLng}
+}
+
+func normalizeAspectRatio(aspectRatio string) string {
+	cleaned := strings.ToLower(strings.TrimSpace(aspectRatio))
+	switch cleaned {
+	case AspectRatioLandscape, AspectRatioPortrait, AspectRatioSquare, AspectRatioPanorama, AspectRatioTall:
+		return cleaned
+	default:
+		return ""
+	}
+}
+
+func normalizeFormatExtensions(formats []string) []string {
+	exts := make([]string, 0, len(formats))
+	seen := make(map[string]struct{})
+	for _, format := range formats {
+		cleaned := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(format)), ".")
+		if cleaned == "" {
+			continue
+		}
+		candidates := []string{cleaned}
+		switch cleaned {
+		case "jpg", "jpeg":
+			candidates = []string{"jpg", "jpeg"}
+		case "tif", "tiff":
+			candidates = []string{"tif", "tiff"}
+		case "heic", "heif":
+			candidates = []string{"heic", "heif"}
+		}
+		for _, candidate := range candidates {
+			ext := "." + candidate
+			if _, exists := seen[ext]; exists {
+				continue
+			}
+			seen[ext] = struct{}{}
+			exts = append(exts, ext)
+		}
+	}
+	return exts
+}
+
+func (s *WorkService) GetRandomWorks(params *WorkListParams, count int, seed int64) (*RandomWorksResult, error) {
+	repoParams := workListRepoParams(params)
+	delete(repoParams, "sort_by")
+	delete(repoParams, "sort_order")
+
+	rows, err := s.workRepo.FindRatingsByFilters(repoParams)
 	if err != nil {
 		return nil, err
 	}
 
-	var workInfos []*WorkInfo
-	for _, work := range works {
-		workInfo := s.workToInfo(&work, false)
-		workInfos = append(workInfos, workInfo)
+	ids := pickWeightedWorkIDs(rows, count, rand.New(rand.NewSource(seed)))
+	works, err := s.workRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
 	}
 
-	totalPages := int(total) / params.PageSize
-	if int(total)%params.PageSize > 0 {
-		totalPages++
+	byID := make(map[uint]*model.Work, len(works))
+	for i := range works {
+		byID[works[i].ID] = &works[i]
+	}
+	items := make([]*WorkInfo, 0, len(ids))
+	for _, id := range ids {
+		if work, ok := byID[id]; ok {
+			items = append(items, s.workToInfo(work, false))
+		}
 	}
 
-	return &WorkPagedResult{
-		Items:      workInfos,
-		Total:      total,
-		Page:       params.Page,
-		PageSize:   params.PageSize,
-		TotalPages: totalPages,
+	return &RandomWorksResult{
+		Seed:  strconv.FormatInt(seed, 10),
+		Items: items,
+	}, nil
+}
+
+func pickWeightedWorkIDs(rows []repository.WorkRatingRow, count int, rng *rand.Rand) []uint {
+	if count > len(rows) {
+		count = len(rows)
+	}
+	candidates := make([]repository.WorkRatingRow, len(rows))
+	copy(candidates, rows)
+
+	totalWeight := 0
+	for _, row := range candidates {
+		totalWeight += randomWorkWeight(row.Rating)
+	}
+
+	ids := make([]uint, 0, count)
+	for len(ids) < count && totalWeight > 0 {
+		target := rng.Intn(totalWeight)
+		for i, row := range candidates {
+			weight := randomWorkWeight(row.Rating)
+			if target < weight {
+				ids = append(ids, row.ID)
+				totalWeight -= weight
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+			target -= weight
+		}
+	}
+	return ids
+}
+
+func randomWorkWeight(rating int) int {
+	if rating < 0 {
+		rating = 0
+	}
+	return rating + 1
+}
+
+func (s *WorkService) GetOnThisDayWorks(params *WorkListParams, date time.Time, limit int) (*OnThisDayResult, error) {
+	repoParams := workListRepoParams(params)
+	works, err := s.workRepo.FindCreatedOnMonthDay(date.Format("01-02"), date.Year(), repoParams, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*WorkInfo, 0, len(works))
+	for i := range works {
+		items = append(items, s.workToInfo(&works[i], false))
+	}
+
+	return &OnThisDayResult{
+		Date:  date.Format("2006-01-02"),
+		Items: items,
 	}, nil
 }
 
+func (s *WorkService) GetTimeline(params *WorkListParams) (*WorkTimelineResult, error) {
+	rows, undated, err := s.workRepo.CountByTakenMonth(workListRepoParams(params))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]WorkTimelineBucket, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, WorkTimelineBucket{Year: row.Year, Month: row.Month, Count: row.Count})
+	}
+	return &WorkTimelineResult{Items: items, Undated: undated}, nil
+}
+
+func (s *WorkService) GetGeoPoints(params *WorkListParams, bounds *GeoBounds, limit int) (*WorkGeoResult, error) {
+	if bounds == nil {
+		bounds = &GeoBounds{MinLat: -90, MaxLat: 90, MinLng: -180, MaxLng: 180}
+	}
+	if !bounds.Valid() {
+		return nil, ErrInvalidGeoBounds
+	}
+	if limit <= 0 {
+		limit = defaultGeoPointLimit
+	}
+	if limit > maxGeoPointLimit {
+		limit = maxGeoPointLimit
+	}
+
+	rows, err := s.workRepo.FindGeoPoints(bounds.repositoryBounds(), workListRepoParams(params), limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WorkGeoResult{Items: make([]WorkGeoPoint, 0, len(rows))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		result.Truncated = true
+	}
+	for _, row := range rows {
+		point := WorkGeoPoint{
+			WorkID:        row.WorkID,
+			ImageID:       row.ImageID,
+			Title:         row.Title,
+			ThumbnailPath: row.ThumbnailPath,
+			Latitude:      row.Latitude,
+			Longitude:     row.Longitude,
+		}
+		if row.TakenAt != nil {
+			point.TakenAt = row.TakenAt.Format("2006-01-02T15:04:05Z07:00")
+		}
+		result.Items = append(result.Items, point)
+	}
+	return result, nil
+}
+
 func (s *WorkService) GetWorkByID(id uint) (*WorkInfo, error) {
 	work, err := s.workRepo.FindByID(id, true)
 	if err != nil {
@@ -89,9 +412,17 @@ func (s *WorkService) GetWorkByID(id uint) (*WorkInfo, error) {
 }
 
 func (s *WorkService) GetPublicWorks(params *WorkListParams) (*WorkPagedResult, error) {
+	s.RestrictToPublic(params)
+	return s.GetWorks(params)
+}
+
+func (s *WorkService) RestrictToPublic(params *WorkListParams) {
 	public := true
 	params.IsPublic = &public
-	return s.GetWorks(params)
+	if s.imageService.PublicEXIFStripEnabled() {
+		params.HasGPS = nil
+		params.GPSBounds = nil
+	}
 }
 
 func (s *WorkService) GetPublicWorkByID(id uint) (*WorkInfo, error) {
@@ -106,7 +437,14 @@ func (s *WorkService) IsPublicImagePath(path string, isThumbnail bool) (bool, er
 	return s.workRepo.IsPublicImagePath(path, isThumbnail)
 }
 
-func (s *WorkService) CreateWork(req *CreateWorkRequest, uploadedImages []*UploadedImage) (*WorkInfo, error) {
+func (s *WorkService) OpenPublicImage(path string, isThumbnail bool) (io.ReadCloser, ObjectInfo, error) {
+	if isThumbnail || !s.imageService.PublicEXIFStripEnabled() {
+		return s.imageService.OpenImage(path)
+	}
+	return s.imageService.OpenSanitizedImage(path)
+}
+
+func (s *WorkService) CreateWork(actor *Actor, req *CreateWorkRequest, uploadedImages []*UploadedImage) (*WorkInfo, error) {
 	if len(uploadedImages) == 0 {
 		return nil, ErrAtLeastOneImageRequired
 	}
@@ -118,8 +456,19 @@ func (s *WorkService) CreateWork(req *CreateWorkRequest, uploadedImages []*Uploa
 		IsPublic:    req.IsPublic,
 	}
 
-	if req.TagIDs != nil {
-		tags, err := s.tagRepo.FindByIDs(req.TagIDs)
+	metadataList := make([]*AIImageMetadata, 0, len(uploadedImages))
+	for _, uploaded := range uploadedImages {
+		metadataList = append(metadataList, uploaded.AIMetadata)
+	}
+	tagIDs := req.TagIDs
+	autoTagIDs, err := s.autoTagService.ResolveTagIDs(metadataList)
+	if err != nil {
+		log.Printf("Failed to resolve auto tags for new work: %v", err)
+	}
+	tagIDs = uniqueIDs(append(append([]uint{}, tagIDs...), autoTagIDs...))
+
+	if len(tagIDs) > 0 {
+		tags, err := s.tagRepo.FindByIDs(tagIDs)
 		if err != nil {
 			return nil, err
 		}
@@ -133,14 +482,20 @@ func (s *WorkService) CreateWork(req *CreateWorkRequest, uploadedImages []*Uploa
 			return nil, err
 		}
 		images = append(images, model.WorkImage{
-			StoragePath:    uploaded.StoragePath,
-			TranscodedPath: uploaded.TranscodedPath,
-			ThumbnailPath:  uploaded.ThumbnailPath,
-			ImageHash:      normalizeImageHash(uploaded.ImageHash),
-			AIMetadata:     metadataJSON,
-			FileSize:       uploaded.FileSize,
-			Width:          uploaded.Width,
-			Height:         uploaded.Height,
+			StoragePath:           uploaded.StoragePath,
+			TranscodedPath:        uploaded.TranscodedPath,
+			ThumbnailPath:         uploaded.ThumbnailPath,
+			ImageHash:             normalizeImageHash(uploaded.ImageHash),
+			AIMetadata:            metadataJSON,
+			Palette:               encodeImagePalette(uploaded.Palette),
+			FileSize:              uploaded.FileSize,
+			Width:                 uploaded.Width,
+			Height:                uploaded.Height,
+			FrameCount:            uploaded.FrameCount,
+			DurationMs:            uploaded.DurationMs,
+			LoopCount:             uploaded.LoopCount,
+			AnimatedThumbnailPath: uploaded.AnimatedThumbnailPath,
+			MediaType:             uploaded.MediaType,
 		})
 	}
 	work.Images = images
@@ -148,15 +503,26 @@ func (s *WorkService) CreateWork(req *CreateWorkRequest, uploadedImages []*Uploa
 	if err := s.workRepo.Create(work); err != nil {
 		return nil, err
 	}
+	for i := range work.Images {
+		if work.Images[i].AIMetadata != "" {
+			s.aiMetadataService.SyncImage(work.Images[i].ID, work.ID, parseAIMetadata(work.Images[i].AIMetadata))
+		}
+		s.imageEXIFService.SyncImage(work.Images[i].ID, work.ID, uploadedImages[i].EXIF)
+		s.paletteService.SyncImage(work.Images[i].ID, work.ID, uploadedImages[i].Palette)
+	}
+
+	s.auditService.Record(actor, "work.create", AuditEntityWork, work.ID, work.ID, nil, newWorkAuditSnapshot(work))
+	s.auditService.Record(actor, "work.images.add", AuditEntityWorkImages, work.ID, work.ID, nil, s.snapshotWorkImages(work.ID))
 
 	return s.workToInfo(work, true), nil
 }
 
-func (s *WorkService) UpdateWork(id uint, req *UpdateWorkRequest) (*WorkInfo, error) {
-	work, err := s.workRepo.FindByID(id, false)
+func (s *WorkService) UpdateWork(actor *Actor, id uint, req *UpdateWorkRequest) (*WorkInfo, error) {
+	work, err := s.workRepo.FindByID(id, true)
 	if err != nil {
 		return nil, ErrWorkNotFound
 	}
+	before := newWorkAuditSnapshot(work)
 
 	if req.Title != "" {
 		work.Title = req.Title
@@ -185,42 +551,117 @@ func (s *WorkService) UpdateWork(id uint, req *UpdateWorkRequest) (*WorkInfo, er
 		return nil, err
 	}
 
+	s.auditService.Record(actor, "work.update", AuditEntityWork, id, id, before, newWorkAuditSnapshot(updatedWork))
+
 	return s.workToInfo(updatedWork, true), nil
 }
 
-func (s *WorkService) DeleteWork(id uint) error {
+func (s *WorkService) GetWorkHistory(id uint, page, pageSize int) (*AuditLogPagedResult, error) {
+	return s.auditService.GetWorkHistory(id, page, pageSize)
+}
+
+func (s *WorkService) RevertWork(actor *Actor, id, logID uint) (*WorkInfo, error) {
 	work, err := s.workRepo.FindByID(id, true)
 	if err != nil {
-		return ErrWorkNotFound
+		return nil, ErrWorkNotFound
 	}
 
-	for _, img := range work.Images {
-		if err := s.imageService.DeleteImage(img.StoragePath, img.ThumbnailPath, img.TranscodedPath); err != nil {
-			return err
-		}
+	entry, err := s.auditService.FindByID(logID)
+	if err != nil || entry.WorkID != id {
+		return nil, ErrAuditLogNotFound
+	}
+	if entry.EntityType != AuditEntityWork || entry.After == "" {
+		return nil, ErrAuditLogNotRevertible
 	}
 
-	return s.workRepo.Delete(id)
-}
+	var target workAuditSnapshot
+	if err := json.Unmarshal([]byte(entry.After), &target); err != nil {
+		return nil, ErrAuditLogNotRevertible
+	}
 
-func (s *WorkService) BatchDeleteWorks(ids []uint) (int64, error) {
-	for _, id := range ids {
-		work, err := s.workRepo.FindByID(id, true)
+	before := newWorkAuditSnapshot(work)
+	work.Title = target.Title
+	work.Description = target.Description
+	work.Rating = target.Rating
+
+	tagIDs := []uint{}
+	if len(target.TagIDs) > 0 {
+		tags, err := s.tagRepo.FindByIDs(target.TagIDs)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		for _, img := range work.Images {
-			s.imageService.DeleteImage(img.StoragePath, img.ThumbnailPath, img.TranscodedPath)
+		for _, tag := range tags {
+			tagIDs = append(tagIDs, tag.ID)
 		}
 	}
-	return s.workRepo.BatchDelete(ids)
+
+	if err := s.workRepo.Update(work, tagIDs); err != nil {
+		return nil, err
+	}
+
+	revertedWork, err := s.workRepo.FindByID(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(actor, "work.revert", AuditEntityWork, id, id, before, newWorkAuditSnapshot(revertedWork))
+
+	return s.workToInfo(revertedWork, true), nil
+}
+
+func (s *WorkService) DeleteWork(actor *Actor, id uint) error {
+	work, err := s.workRepo.FindByID(id, true)
+	if err != nil {
+		return ErrWorkNotFound
+	}
+
+	if err := s.workRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.auditService.Record(actor, "work.delete", AuditEntityWork, id, id, newWorkAuditSnapshot(work), nil)
+	return nil
 }
 
-func (s *WorkService) BatchUpdatePublicStatus(ids []uint, isPublic bool) (int64, error) {
-	return s.workRepo.BatchUpdatePublicStatus(ids, isPublic)
+func (s *WorkService) BatchDeleteWorks(actor *Actor, ids []uint) (int64, error) {
+	works, err := s.workRepo.FindByIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.workRepo.BatchDelete(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range works {
+		s.auditService.Record(actor, "work.delete", AuditEntityWork, works[i].ID, works[i].ID, newWorkAuditSnapshot(&works[i]), nil)
+	}
+	return deleted, nil
+}
+
+func (s *WorkService) BatchUpdatePublicStatus(actor *Actor, ids []uint, isPublic bool) (int64, error) {
+	works, err := s.workRepo.FindByIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	updated, err := s.workRepo.BatchUpdatePublicStatus(ids, isPublic)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range works {
+		before := newWorkAuditSnapshot(&works[i])
+		after := *before
+		after.IsPublic = isPublic
+		s.auditService.Record(actor, "work.update", AuditEntityWork, works[i].ID, works[i].ID, before, &after)
+	}
+	return updated, nil
 }
 
-func (s *WorkService) AddImages(workID uint, uploadedImages []*UploadedImage) ([]*ImageInfo, error) {
+func (s *WorkService) AddImages(actor *Actor, workID uint, uploadedImages []*UploadedImage) ([]*ImageInfo, error) {
+	before := s.snapshotWorkImages(workID)
 	existingCount, err := s.workRepo.FindImageCount(workID)
 	if err != nil {
 		return nil, err
@@ -237,14 +678,20 @@ func (s *WorkService) AddImages(workID uint, uploadedImages []*UploadedImage) ([
 			return nil, err
 		}
 		images = append(images, model.WorkImage{
-			StoragePath:    uploaded.StoragePath,
-			TranscodedPath: uploaded.TranscodedPath,
-			ThumbnailPath:  uploaded.ThumbnailPath,
-			ImageHash:      normalizeImageHash(uploaded.ImageHash),
-			AIMetadata:     metadataJSON,
-			FileSize:       uploaded.FileSize,
-			Width:          uploaded.Width,
-			Height:         uploaded.Height,
+			StoragePath:           uploaded.StoragePath,
+			TranscodedPath:        uploaded.TranscodedPath,
+			ThumbnailPath:         uploaded.ThumbnailPath,
+			ImageHash:             normalizeImageHash(uploaded.ImageHash),
+			AIMetadata:            metadataJSON,
+			Palette:               encodeImagePalette(uploaded.Palette),
+			FileSize:              uploaded.FileSize,
+			Width:                 uploaded.Width,
+			Height:                uploaded.Height,
+			FrameCount:            uploaded.FrameCount,
+			DurationMs:            uploaded.DurationMs,
+			LoopCount:             uploaded.LoopCount,
+			AnimatedThumbnailPath: uploaded.AnimatedThumbnailPath,
+			MediaType:             uploaded.MediaType,
 		})
 	}
 
@@ -252,26 +699,46 @@ func (s *WorkService) AddImages(workID uint, uploadedImages []*UploadedImage) ([
 		return nil, err
 	}
 
+	s.auditService.Record(actor, "work.images.add", AuditEntityWorkImages, workID, workID, before, s.snapshotWorkImages(workID))
+
+	metadataList := make([]*AIImageMetadata, 0, len(images))
+	for i := range images {
+		metadata := parseAIMetadata(images[i].AIMetadata)
+		if metadata != nil {
+			s.aiMetadataService.SyncImage(images[i].ID, workID, metadata)
+		}
+		s.imageEXIFService.SyncImage(images[i].ID, workID, uploadedImages[i].EXIF)
+		s.paletteService.SyncImage(images[i].ID, workID, uploadedImages[i].Palette)
+		metadataList = append(metadataList, metadata)
+	}
+	s.applyAutoTags(actor, workID, metadataList)
+
 	var imageInfos []*ImageInfo
 	for i := range images {
 		imageInfos = append(imageInfos, &ImageInfo{
-			ID:             images[i].ID,
-			ThumbnailPath:  images[i].ThumbnailPath,
-			OriginalPath:   images[i].StoragePath,
-			TranscodedPath: images[i].TranscodedPath,
-			ImageHash:      images[i].ImageHash,
-			AIMetadata:     parseAIMetadata(images[i].AIMetadata),
-			FileSize:       images[i].FileSize,
-			Width:          images[i].Width,
-			Height:         images[i].Height,
-			SortOrder:      images[i].SortOrder,
+			ID:                    images[i].ID,
+			ThumbnailPath:         images[i].ThumbnailPath,
+			OriginalPath:          images[i].StoragePath,
+			TranscodedPath:        images[i].TranscodedPath,
+			ImageHash:             images[i].ImageHash,
+			AIMetadata:            parseAIMetadata(images[i].AIMetadata),
+			Palette:               parseImagePalette(images[i].Palette),
+			FileSize:              images[i].FileSize,
+			Width:                 images[i].Width,
+			Height:                images[i].Height,
+			FrameCount:            images[i].FrameCount,
+			DurationMs:            images[i].DurationMs,
+			LoopCount:             images[i].LoopCount,
+			AnimatedThumbnailPath: images[i].AnimatedThumbnailPath,
+			MediaType:             images[i].MediaType,
+			SortOrder:             images[i].SortOrder,
 		})
 	}
 
 	return imageInfos, nil
 }
 
-func (s *WorkService) DeleteImage(workID, imageID uint) error {
+func (s *WorkService) DeleteImage(actor *Actor, workID, imageID uint) error {
 	imageCount, err := s.workRepo.FindImageCount(workID)
 	if err != nil {
 		return err
@@ -281,36 +748,272 @@ func (s *WorkService) DeleteImage(workID, imageID uint) error {
 		return ErrCannotDeleteLastImage
 	}
 
-	image, err := s.workRepo.FindFirstOrDefaultImage(workID)
-	if err != nil {
-		return err
+	if _, err := s.workRepo.FindImageByID(workID, imageID); err != nil {
+		return ErrImageNotFound
 	}
 
-	if err := s.imageService.DeleteImage(image.StoragePath, image.ThumbnailPath, image.TranscodedPath); err != nil {
+	before := s.snapshotWorkImages(workID)
+	if err := s.workRepo.DeleteImage(imageID); err != nil {
 		return err
 	}
 
-	return s.workRepo.DeleteImage(imageID)
+	s.auditService.Record(actor, "work.images.delete", AuditEntityWorkImages, workID, workID, before, s.snapshotWorkImages(workID))
+	return nil
 }
 
-func (s *WorkService) UpdateImageOrder(workID uint, imageIDs []uint) error {
-	return s.workRepo.UpdateImageOrder(workID, imageIDs)
+func (s *WorkService) UpdateImageOrder(actor *Actor, workID uint, imageIDs []uint) error {
+	before := s.snapshotWorkImages(workID)
+	if err := s.workRepo.UpdateImageOrder(workID, imageIDs); err != nil {
+		return err
+	}
+
+	s.auditService.Record(actor, "work.images.reorder", AuditEntityWorkImages, workID, workID, before, s.snapshotWorkImages(workID))
+	return nil
 }
 
-func (s *WorkService) UpdateImageAIMetadata(workID, imageID uint, metadata *AIImageMetadata) error {
+func (s *WorkService) UpdateImageAIMetadata(actor *Actor, workID, imageID uint, metadata *AIImageMetadata) error {
 	metadataJSON, err := normalizeAndMarshalAIMetadata(metadata)
 	if err != nil {
 		return err
 	}
+
+	var before *imageAIMetadataAuditSnapshot
+	if image, findErr := s.workRepo.FindImageByID(workID, imageID); findErr == nil {
+		before = &imageAIMetadataAuditSnapshot{AIMetadata: parseAIMetadata(image.AIMetadata)}
+	}
+
 	if err := s.workRepo.UpdateImageAIMetadata(workID, imageID, metadataJSON); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrImageNotFound
 		}
 		return err
 	}
+
+	after := &imageAIMetadataAuditSnapshot{AIMetadata: parseAIMetadata(metadataJSON)}
+	s.auditService.Record(actor, "work.image.ai_metadata", AuditEntityWorkImage, imageID, workID, before, after)
+	s.aiMetadataService.SyncImage(imageID, workID, after.AIMetadata)
+	s.applyAutoTags(actor, workID, []*AIImageMetadata{after.AIMetadata})
 	return nil
 }
 
+func (s *WorkService) ExportImageAIMetadata(workID, imageID uint, format string) (*AIMetadataExport, error) {
+	image, err := s.workRepo.FindImageByID(workID, imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	metadata := parseAIMetadata(image.AIMetadata)
+	if metadata == nil {
+		return nil, ErrAIMetadataNotFound
+	}
+
+	content, contentType, ext, err := formatAIMetadata(metadata, strings.ToLower(strings.TrimSpace(format)))
+	if err != nil {
+		return nil, err
+	}
+
+	filename := "work-" + strconv.FormatUint(uint64(workID), 10)
+	if work, findErr := s.workRepo.FindByID(workID, false); findErr == nil && strings.TrimSpace(work.Title) != "" {
+		filename = strings.TrimSpace(work.Title)
+	}
+	filename += "-P" + strconv.Itoa(image.SortOrder+1) + ext
+
+	return &AIMetadataExport{Filename: filename, ContentType: contentType, Content: content}, nil
+}
+
+func (s *WorkService) ImportImageAIMetadata(actor *Actor, workID, imageID uint, req *ImportAIMetadataRequest) (*AIImageMetadata, error) {
+	if len(req.Content) > aiMetadataMaxImportBytes {
+		return nil, ErrAIMetadataImportInvalid
+	}
+	metadata, err := parseAIMetadataImport(strings.ToLower(strings.TrimSpace(req.Format)), req.Content)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.UpdateImageAIMetadata(actor, workID, imageID, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+var (
+	aiMetadataRescanMu     sync.Mutex
+	aiMetadataRescanStatus RescanAIMetadataStatus
+)
+
+func (s *WorkService) StartAIMetadataRescan(actor *Actor, req *RescanAIMetadataRequest) (*RescanAIMetadataStatus, error) {
+	aiMetadataRescanMu.Lock()
+	defer aiMetadataRescanMu.Unlock()
+
+	if aiMetadataRescanStatus.Running {
+		return nil, ErrAIMetadataRescanRunning
+	}
+
+	images, err := s.workRepo.FindImagesForAIMetadataScan(uniqueIDs(req.WorkIDs), !req.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := GetStorageProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	startedAt := time.Now()
+	aiMetadataRescanStatus = RescanAIMetadataStatus{
+		Running:   true,
+		Total:     len(images),
+		StartedAt: &startedAt,
+	}
+	status := aiMetadataRescanStatus
+
+	go s.runAIMetadataRescan(actor, storage, images)
+
+	return &status, nil
+}
+
+func (s *WorkService) GetAIMetadataRescanStatus() *RescanAIMetadataStatus {
+	aiMetadataRescanMu.Lock()
+	defer aiMetadataRescanMu.Unlock()
+
+	status := aiMetadataRescanStatus
+	return &status
+}
+
+func updateAIMetadataRescanStatus(update func(status *RescanAIMetadataStatus)) {
+	aiMetadataRescanMu.Lock()
+	update(&aiMetadataRescanStatus)
+	aiMetadataRescanMu.Unlock()
+}
+
+func (s *WorkService) runAIMetadataRescan(actor *Actor, storage StorageProvider, images []model.WorkImage) {
+	defer updateAIMetadataRescanStatus(func(status *RescanAIMetadataStatus) {
+		finishedAt := time.Now()
+		status.Running = false
+		status.FinishedAt = &finishedAt
+	})
+
+	updatedByWork := make(map[uint][]*AIImageMetadata)
+	workIDs := make([]uint, 0)
+	for i := range images {
+		image := &images[i]
+		outcome := s.rescanImageAIMetadata(actor, storage, image)
+		updateAIMetadataRescanStatus(func(status *RescanAIMetadataStatus) {
+			status.Scanned++
+			switch outcome {
+			case aiMetadataRescanUpdated:
+				status.Updated++
+			case aiMetadataRescanSkipped:
+				status.Skipped++
+			default:
+				status.Failed++
+			}
+		})
+		if outcome != aiMetadataRescanUpdated {
+			continue
+		}
+
+		if _, ok := updatedByWork[image.WorkID]; !ok {
+			workIDs = append(workIDs, image.WorkID)
+		}
+		updatedByWork[image.WorkID] = append(updatedByWork[image.WorkID], parseAIMetadata(image.AIMetadata))
+	}
+
+	for _, workID := range workIDs {
+		s.applyAutoTags(actor, workID, updatedByWork[workID])
+	}
+}
+
+type aiMetadataRescanOutcome int
+
+const (
+	aiMetadataRescanFailed aiMetadataRescanOutcome = iota
+	aiMetadataRescanSkipped
+	aiMetadataRescanUpdated
+)
+
+func (s *WorkService) rescanImageAIMetadata(actor *Actor, storage StorageProvider, image *model.WorkImage) aiMetadataRescanOutcome {
+	file, _, err := storage.Get(context.Background(), image.StoragePath)
+	if err != nil {
+		log.Printf("Failed to read image %d for AI metadata scan: %v", image.ID, err)
+		return aiMetadataRescanFailed
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		log.Printf("Failed to read image %d for AI metadata scan: %v", image.ID, err)
+		return aiMetadataRescanFailed
+	}
+
+	metadataJSON, err := normalizeAndMarshalAIMetadata(extractAIMetadata(data))
+	if err != nil || metadataJSON == "" || metadataJSON == image.AIMetadata {
+		return aiMetadataRescanSkipped
+	}
+
+	if err := s.workRepo.UpdateImageAIMetadata(image.WorkID, image.ID, metadataJSON); err != nil {
+		log.Printf("Failed to store AI metadata for image %d: %v", image.ID, err)
+		return aiMetadataRescanFailed
+	}
+
+	before := &imageAIMetadataAuditSnapshot{AIMetadata: parseAIMetadata(image.AIMetadata)}
+	after := &imageAIMetadataAuditSnapshot{AIMetadata: parseAIMetadata(metadataJSON)}
+	s.auditService.Record(actor, "work.image.ai_metadata", AuditEntityWorkImage, image.ID, image.WorkID, before, after)
+	s.aiMetadataService.SyncImage(image.ID, image.WorkID, after.AIMetadata)
+	image.AIMetadata = metadataJSON
+	return aiMetadataRescanUpdated
+}
+
+func (s *WorkService) applyAutoTags(actor *Actor, workID uint, metadataList []*AIImageMetadata) {
+	tagIDs, err := s.autoTagService.ResolveTagIDs(metadataList)
+	if err != nil {
+		log.Printf("Failed to resolve auto tags for work %d: %v", workID, err)
+		return
+	}
+	if len(tagIDs) == 0 {
+		return
+	}
+
+	work, err := s.workRepo.FindByID(workID, true)
+	if err != nil {
+		return
+	}
+	before := newWorkAuditSnapshot(work)
+
+	missing := make([]uint, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		if !containsID(before.TagIDs, tagID) {
+			missing = append(missing, tagID)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	if err := s.workRepo.ApplyBatchOperations([]uint{workID}, []repository.WorkBatchOperation{
+		{Type: repository.WorkBatchAddTags, TagIDs: missing},
+	}); err != nil {
+		log.Printf("Failed to apply auto tags for work %d: %v", workID, err)
+		return
+	}
+
+	if updated, err := s.workRepo.FindByID(workID, true); err == nil {
+		s.auditService.Record(actor, "work.auto_tag", AuditEntityWork, workID, workID, before, newWorkAuditSnapshot(updated))
+	}
+}
+
+func (s *WorkService) snapshotWorkImages(workID uint) *workImagesAuditSnapshot {
+	work, err := s.workRepo.FindByID(workID, true)
+	if err != nil {
+		return nil
+	}
+	imageIDs := make([]uint, 0, len(work.Images))
+	for _, img := range work.Images {
+		imageIDs = append(imageIDs, img.ID)
+	}
+	return &workImagesAuditSnapshot{ImageIDs: imageIDs}
+}
+
 func (s *WorkService) workToInfo(work *model.Work, fullDetails bool) *WorkInfo {
 	info := &WorkInfo{
 		ID:          work.ID,
@@ -324,16 +1027,22 @@ func (s *WorkService) workToInfo(work *model.Work, fullDetails bool) *WorkInfo {
 
 	if len(work.Images) > 0 {
 		info.CoverImage = &ImageInfo{
-			ID:             work.Images[0].ID,
-			ThumbnailPath:  work.Images[0].ThumbnailPath,
-			OriginalPath:   work.Images[0].StoragePath,
-			TranscodedPath: work.Images[0].TranscodedPath,
-			ImageHash:      work.Images[0].ImageHash,
-			AIMetadata:     parseAIMetadata(work.Images[0].AIMetadata),
-			FileSize:       work.Images[0].FileSize,
-			Width:          work.Images[0].Width,
-			Height:         work.Images[0].Height,
-			SortOrder:      work.Images[0].SortOrder,
+			ID:                    work.Images[0].ID,
+			ThumbnailPath:         work.Images[0].ThumbnailPath,
+			OriginalPath:          work.Images[0].StoragePath,
+			TranscodedPath:        work.Images[0].TranscodedPath,
+			ImageHash:             work.Images[0].ImageHash,
+			AIMetadata:            parseAIMetadata(work.Images[0].AIMetadata),
+			Palette:               parseImagePalette(work.Images[0].Palette),
+			FileSize:              work.Images[0].FileSize,
+			Width:                 work.Images[0].Width,
+			Height:                work.Images[0].Height,
+			FrameCount:            work.Images[0].FrameCount,
+			DurationMs:            work.Images[0].DurationMs,
+			LoopCount:             work.Images[0].LoopCount,
+			AnimatedThumbnailPath: work.Images[0].AnimatedThumbnailPath,
+			MediaType:             work.Images[0].MediaType,
+			SortOrder:             work.Images[0].SortOrder,
 		}
 		info.ImageCount = len(work.Images)
 	}
@@ -342,16 +1051,22 @@ func (s *WorkService) workToInfo(work *model.Work, fullDetails bool) *WorkInfo {
 		var images []ImageInfo
 		for _, img := range work.Images {
 			images = append(images, ImageInfo{
-				ID:             img.ID,
-				ThumbnailPath:  img.ThumbnailPath,
-				OriginalPath:   img.StoragePath,
-				TranscodedPath: img.TranscodedPath,
-				ImageHash:      img.ImageHash,
-				AIMetadata:     parseAIMetadata(img.AIMetadata),
-				FileSize:       img.FileSize,
-				Width:          img.Width,
-				Height:         img.Height,
-				SortOrder:      img.SortOrder,
+				ID:                    img.ID,
+				ThumbnailPath:         img.ThumbnailPath,
+				OriginalPath:          img.StoragePath,
+				TranscodedPath:        img.TranscodedPath,
+				ImageHash:             img.ImageHash,
+				AIMetadata:            parseAIMetadata(img.AIMetadata),
+				Palette:               parseImagePalette(img.Palette),
+				FileSize:              img.FileSize,
+				Width:                 img.Width,
+				Height:                img.Height,
+				FrameCount:            img.FrameCount,
+				DurationMs:            img.DurationMs,
+				LoopCount:             img.LoopCount,
+				AnimatedThumbnailPath: img.AnimatedThumbnailPath,
+				MediaType:             img.MediaType,
+				SortOrder:             img.SortOrder,
 			})
 		}
 		info.Images = images
@@ -363,11 +1078,54 @@ func (s *WorkService) workToInfo(work *model.Work, fullDetails bool) *WorkInfo {
 			tags = append(tags, &tag)
 		}
 		info.Tags = tags
+		if fullDetails {
+			info.TagGroups = s.groupTagsByCategory(tags)
+		}
 	}
 
 	return info
 }
 
+func (s *WorkService) groupTagsByCategory(tags []*model.Tag) []TagGroup {
+	categories, err := s.tagRepo.FindCategories()
+	if err != nil {
+		categories = nil
+	}
+
+	groups := make([]TagGroup, 0, len(categories))
+	indexes := make(map[string]int, len(categories))
+	for _, category := range categories {
+		indexes[category.Name] = len(groups)
+		groups = append(groups, TagGroup{
+			Category:     category.Name,
+			Color:        category.Color,
+			SortPriority: category.SortPriority,
+		})
+	}
+
+	for _, tag := range tags {
+		name := tag.Category
+		if name == "" {
+			name = model.TagCategoryGeneral
+		}
+		idx, ok := indexes[name]
+		if !ok {
+			idx = len(groups)
+			indexes[name] = idx
+			groups = append(groups, TagGroup{Category: name})
+		}
+		groups[idx].Tags = append(groups[idx].Tags, tag)
+	}
+
+	result := make([]TagGroup, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Tags) > 0 {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
 func (s *WorkService) ParseTagIDs(tagIDsStr string) ([]uint, error) {
 	if tagIDsStr == "" {
 		return nil, nil
@@ -412,8 +1170,10 @@ func (s *WorkService) ListExportImages() ([]ExportImageRecord, error) {
 				Title:       work.Title,
 				Description: work.Description,
 				Tags:        tags,
+				TagNames:    tagNames,
 				Rating:      work.Rating,
 				CreatedAt:   createdAt,
+				AIMetadata:  parseAIMetadata(image.AIMetadata),
 			})
 		}
 	}
@@ -561,91 +1321,37 @@ func (s *WorkService) GetImageEXIF(workID, imageID uint) (*ImageEXIFInfo, error)
 		return nil, ErrEXIFUnsupportedSourceType
 	}
 
-	storage, err := GetStorageProvider()
-	if err != nil {
-		return nil, err
-	}
-
-	file, _, err := storage.Get(context.Background(), image.StoragePath)
+	record, err := s.imageEXIFService.Load(image)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil, ErrImageNotFound
-		}
 		return nil, err
 	}
-	defer file.Close()
 
-	info := &ImageEXIFInfo{
+	fields := imageEXIFFields(record)
+	return &ImageEXIFInfo{
 		WorkID:   workID,
 		ImageID:  imageID,
-		HasEXIF:  false,
-		Fields:   []ImageEXIFField{},
+		HasEXIF:  len(fields) > 0,
+		Fields:   fields,
+		Summary:  imageEXIFSummary(record),
 		Format:   strings.TrimPrefix(ext, "."),
 		Filename: filepath.Base(image.StoragePath),
-	}
-
-	meta, err := exif.Decode(file)
-	if err != nil {
-		if isNoEXIFDecodeError(err) {
-			return info, nil
-		}
-		return nil, err
-	}
+	}, nil
+}
 
-	walker := &imageEXIFWalker{
-		fields: make([]ImageEXIFField, 0),
-	}
-	if err := meta.Walk(walker); err != nil {
-		return nil, err
+func (s *WorkService) GetPublicImageEXIF(workID, imageID uint) (*ImageEXIFInfo, error) {
+	info, err := s.GetImageEXIF(workID, imageID)
+	if err != nil || !s.imageService.PublicEXIFStripEnabled() {
+		return info, err
 	}
-
-	sort.Slice(walker.fields, func(i, j int) bool {
-		return walker.fields[i].Key < walker.fields[j].Key
-	})
-	info.Fields = walker.fields
-	info.HasEXIF = len(walker.fields) > 0
+	redactImageEXIFInfo(info)
 	return info, nil
 }
 
 func isEXIFSupportedSourceExt(ext string) bool {
 	switch strings.ToLower(strings.TrimSpace(ext)) {
-	case ".jpg", ".jpeg", ".tif", ".tiff":
+	case ".jpg", ".jpeg", ".tif", ".tiff", ".png", ".webp", ".heic", ".heif", ".avif":
 		return true
 	default:
 		return false
 	}
 }
-
-func isNoEXIFDecodeError(err error) bool {
-	if err == nil {
-		return false
-	}
-	msg := strings.ToLower(strings.TrimSpace(err.Error()))
-	noExifMarkers := []string{
-		"no exif data",
-		"failed to find exif intro marker",
-		"failed to find beginning of tiff header",
-		"failed to read tiff",
-	}
-	for _, marker := range noExifMarkers {
-		if strings.Contains(msg, marker) {
-			return true
-		}
-	}
-	return false
-}
-
-type imageEXIFWalker struct {
-	fields []ImageEXIFField
-}
-
-func (w *imageEXIFWalker) Walk(name exif.FieldName, tag *tiff.Tag) error {
-	if tag == nil {
-		return nil
-	}
-	w.fields = append(w.fields, ImageEXIFField{
-		Key:   string(name),
-		Value: strings.TrimSpace(tag.String()),
-	})
-	return nil
-}